@@ -8,15 +8,23 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"syscall"
 	"time"
 
 	"github.com/vibin/chat-bot/config"
 	httpHandler "github.com/vibin/chat-bot/internal/adapters/primary/http"
 	whatsappAdapter "github.com/vibin/chat-bot/internal/adapters/primary/whatsapp"
+	"github.com/vibin/chat-bot/internal/adapters/secondary/database"
+	"github.com/vibin/chat-bot/internal/adapters/secondary/documents"
+	"github.com/vibin/chat-bot/internal/adapters/secondary/imagegen"
 	"github.com/vibin/chat-bot/internal/adapters/secondary/llm"
 	"github.com/vibin/chat-bot/internal/adapters/secondary/repository"
+	"github.com/vibin/chat-bot/internal/adapters/secondary/speech"
+	"github.com/vibin/chat-bot/internal/adapters/secondary/tools"
+	"github.com/vibin/chat-bot/internal/adapters/secondary/webhook"
 	"github.com/vibin/chat-bot/internal/adapters/secondary/websearch"
+	"github.com/vibin/chat-bot/internal/core/domain"
 	"github.com/vibin/chat-bot/internal/core/ports"
 	"github.com/vibin/chat-bot/internal/core/services"
 	"github.com/vibin/chat-bot/internal/logger"
@@ -26,8 +34,27 @@ func main() {
 	// Parse command line flags
 	configPath := flag.String("config", "", "Path to config file")
 	debugMode := flag.Bool("debug", false, "Enable debug logging")
+	hashPassword := flag.String("hash-password", "", "Print the bcrypt hash of a password for auth.users and exit")
+	newToken := flag.Bool("new-token", false, "Print a new API token and its hash for auth.api_tokens and exit")
 	flag.Parse()
 
+	// Helpers for filling in the auth configuration
+	if *hashPassword != "" {
+		hash, err := services.HashPassword(*hashPassword)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Failed to hash password:", err)
+			os.Exit(1)
+		}
+		fmt.Println(hash)
+		return
+	}
+	if *newToken {
+		token, hash := services.NewAPIToken()
+		fmt.Println("token:     ", token)
+		fmt.Println("token_hash:", hash)
+		return
+	}
+
 	// Setup logger
 	logLevel := slog.LevelInfo
 	if *debugMode {
@@ -55,55 +82,84 @@ func main() {
 	// Initialize adapters
 	log.Info("Initializing adapters")
 
-	// Create main LLM adapter
-	llmAdapter, err := llm.NewOllamaAdapter(&cfg.LLM, log)
-	if err != nil {
-		log.Error("Failed to initialize main LLM adapter", "error", err)
-		os.Exit(1)
+	// Route the main, image and secondary LLMs over a chain of backends if enabled
+	// Adapters on the same server share its request queue
+	llmQueues := make(map[string]*llm.RequestQueue)
+	var llmAdapter, imageLLMAdapter, secondaryLLMAdapter ports.LLMPort
+	if cfg.LLMRouting.Enabled {
+		router, err := newLLMRouter(cfg, llmQueues, log)
+		if err != nil {
+			log.Error("Failed to initialize LLM routing", "error", err)
+			os.Exit(1)
+		}
+		llmAdapter = router.ForTask(domain.LLMTaskChat)
+		imageLLMAdapter = router.ForTask(domain.LLMTaskImage)
+		secondaryLLMAdapter = router.ForTask(domain.LLMTaskHelper)
+		go router.Start(context.Background())
+	} else {
+		// Create main LLM adapter
+		llmAdapter, err = newLLMAdapter(&cfg.LLM, log)
+		if err != nil {
+			log.Error("Failed to initialize main LLM adapter", "error", err)
+			os.Exit(1)
+		}
+		llmAdapter = queueLLM(cfg, llmQueues, llmAdapter, &cfg.LLM, 0, log)
 	}
 	
-	// Create image analysis LLM adapter if enabled
-	var imageLLMAdapter ports.LLMPort
-	if cfg.ImageLLM.Enabled {
-		log.Info("Initializing image analysis LLM adapter", "provider", cfg.ImageLLM.Provider, "model", cfg.ImageLLM.Ollama.Model)
+	// Create image analysis LLM adapter if enabled, routed image requests need none
+	switch {
+	case cfg.LLMRouting.Enabled:
+	case cfg.ImageLLM.Enabled:
 		// Create a temporary LLMConfig from ImageLLM for adapter initialization
-		imageLLMConfig := &config.LLMConfig{
-			Provider: cfg.ImageLLM.Provider,
-			Ollama:   cfg.ImageLLM.Ollama,
-		}
-		imageAdapter, err := llm.NewOllamaAdapter(imageLLMConfig, log)
+		imageLLMConfig := cfg.ImageLLM.AsLLMConfig()
+		log.Info("Initializing image analysis LLM adapter", "provider", imageLLMConfig.Provider, "model", imageLLMConfig.ModelName())
+		imageAdapter, err := newLLMAdapter(imageLLMConfig, log)
 		if err != nil {
 			log.Error("Failed to initialize image analysis LLM adapter", "error", err)
 			// Fall back to main LLM if image LLM fails
 			imageLLMAdapter = llmAdapter
 		} else {
-			imageLLMAdapter = imageAdapter
+			imageLLMAdapter = queueLLM(cfg, llmQueues, imageAdapter, imageLLMConfig, 0, log)
 		}
-	} else {
+	default:
 		// Use main LLM adapter for image analysis if dedicated one is disabled
 		imageLLMAdapter = llmAdapter
 	}
 
+	// Probe the dependencies for /healthz and /readyz, the service is only ready while the critical ones are up
+	health := services.NewHealthService(&cfg.Health, log)
+	registerLLMProbe(health, "llm", llmAdapter, true)
+	if imageLLMAdapter != llmAdapter {
+		registerLLMProbe(health, "image_llm", imageLLMAdapter, false)
+	}
+
 	// Create repository adapter
-	repoAdapter := repository.NewInMemoryRepository(log)
+	repoAdapter, closeRepo, err := newChatRepository(&cfg.Storage, log)
+	if err != nil {
+		log.Error("Failed to initialize chat repository", "error", err)
+		os.Exit(1)
+	}
+	defer closeRepo()
 	
-	// Create secondary LLM adapter for search query formatting
-	var secondaryLLMAdapter *llm.OllamaAdapter
+	// Create secondary LLM adapter for search query formatting and history summaries
 	var webSearchAdapter ports.WebSearchPort
 	
-	if cfg.WebSearch.Enabled {
-		log.Info("Initializing secondary LLM adapter for web search")
+	if cfg.LLMRouting.Enabled {
+		registerLLMProbe(health, "secondary_llm", secondaryLLMAdapter, false)
+	} else if cfg.WebSearch.Enabled || cfg.SecondaryLLM.AsLLMConfig().ModelName() != "" {
+		log.Info("Initializing secondary LLM adapter")
 		// Create a temporary LLMConfig from SecondaryLLM for adapter initialization
-		secondaryLLMConfig := &config.LLMConfig{
-			Provider: cfg.SecondaryLLM.Provider,
-			Ollama:   cfg.SecondaryLLM.Ollama,
-		}
-		secondaryLLMAdapter, err = llm.NewOllamaAdapter(secondaryLLMConfig, log)
+		secondaryLLMConfig := cfg.SecondaryLLM.AsLLMConfig()
+		secondaryLLMAdapter, err = newLLMAdapter(secondaryLLMConfig, log)
 		if err != nil {
 			log.Error("Failed to initialize secondary LLM adapter", "error", err)
 			os.Exit(1)
 		}
-		
+		secondaryLLMAdapter = queueLLM(cfg, llmQueues, secondaryLLMAdapter, secondaryLLMConfig, 0, log)
+		registerLLMProbe(health, "secondary_llm", secondaryLLMAdapter, false)
+	}
+	
+	if cfg.WebSearch.Enabled {
 		// Create web search adapter based on config
 		log.Info("Initializing web search adapter", "provider", cfg.WebSearch.Provider)
 		
@@ -121,10 +177,18 @@ func main() {
 	}
 
 	// Create chat service with dedicated image LLM adapter
-	chatService := services.NewChatService(llmAdapter, imageLLMAdapter, repoAdapter, webSearchAdapter, cfg, log)
+	chatService := services.NewChatService(llmAdapter, imageLLMAdapter, secondaryLLMAdapter, repoAdapter, webSearchAdapter, cfg, log)
+	
+	// Webhook services are shared by the WhatsApp commands and the LLM tools
+	webhookAdapter := webhook.NewWebhookAdapter(log)
+
+	// Register the tools the LLM can call
+	registerTools(chatService, cfg, webSearchAdapter, webhookAdapter, log)
 
 	// Initialize WhatsApp adapter if enabled
 	var waAdapter ports.WhatsAppPort
+	var scheduler *services.Scheduler
+	var responseRules *services.ResponseRuleService
 	if cfg.WhatsApp.Enabled {
 		log.Info("Initializing WhatsApp adapter")
 		whatsappAdapter, err := whatsappAdapter.NewWhatsAppAdapter(chatService, cfg, log)
@@ -132,16 +196,114 @@ func main() {
 			log.Error("Failed to initialize WhatsApp adapter", "error", err)
 		} else {
 			waAdapter = whatsappAdapter
+			whatsappAdapter.SetWebhookCaller(webhookAdapter)
+			health.Register("whatsapp", false, whatsappAdapter.Ping)
+			if cfg.WhatsApp.ComfyUIService.Enabled {
+				health.Register("comfyui", false, whatsappAdapter.PingComfyUI)
+			}
 			
 			// Initialize memory database
 			log.Info("Initializing memory database")
 			memoryService, err := whatsappAdapter.InitializeMemoryDB()
+			// Extracted memories are kept in memory until a restart when the database is unavailable
+			memoryStore := whatsappAdapter.InMemoryMemoryStore()
 			if err != nil {
 				log.Error("Failed to initialize memory database", "error", err)
 			} else {
+				memoryStore = memoryService
 				log.Info("Memory database initialized successfully")
+				health.Register("memory_db", true, memoryService.Ping)
+				// Retrieve memories by semantic similarity if embeddings are enabled
+				if cfg.Embeddings.Enabled {
+					embedder := llm.NewOllamaEmbeddingAdapter(&cfg.Embeddings, log)
+					memoryService.SetEmbedder(embedder, cfg.Embeddings.TopK, cfg.Embeddings.MinSimilarity)
+					go memoryService.Start(context.Background())
+				}
+				
 				// Connect the memory service to the WhatsApp adapter
 				whatsappAdapter.SetMemoryService(memoryService)
+				
+				if cfg.Tools.Enabled {
+					chatService.RegisterTool(tools.NewSaveMemoryTool(memoryService))
+					chatService.RegisterTool(tools.NewRecallMemoryTool(memoryService))
+				}
+			}
+			
+			// Extract memories with the secondary LLM, falling back to the main LLM
+			extractorLLM := secondaryLLMAdapter
+			if extractorLLM == nil {
+				extractorLLM = llmAdapter
+			}
+			memoryExtractor := services.NewMemoryExtractor(extractorLLM, memoryStore, log)
+			whatsappAdapter.SetMemoryExtractor(memoryExtractor)
+			go memoryExtractor.Start(context.Background())
+			
+			// Transcribe voice notes if speech to text is enabled
+			if cfg.Speech.Enabled {
+				transcriber, err := speech.NewWhisperAdapter(&cfg.Speech, log)
+				if err != nil {
+					log.Error("Failed to initialize speech to text", "error", err)
+				} else {
+					whatsappAdapter.SetSpeechToText(transcriber)
+				}
+			}
+			
+			// Log group messages for digests if enabled
+			if cfg.WhatsApp.MessageLog.Enabled {
+				messageLog, closeMessageLog, err := newMessageLog(&cfg.WhatsApp.MessageLog, log)
+				if err != nil {
+					log.Error("Failed to initialize message log", "error", err)
+				} else {
+					defer closeMessageLog()
+					whatsappAdapter.SetMessageLog(messageLog)
+				}
+			}
+			
+			// Answer questions about shared documents if enabled
+			if cfg.Documents.Enabled {
+				documentService, closeDocuments, err := newDocumentService(cfg, log)
+				if err != nil {
+					log.Error("Failed to initialize document service", "error", err)
+				} else {
+					defer closeDocuments()
+					whatsappAdapter.SetDocumentService(documentService)
+				}
+			}
+			
+			// Post reminders and recurring messages if enabled
+			if cfg.Scheduler.Enabled {
+				// Read reminders with the secondary LLM, falling back to the main LLM
+				schedulerLLM := secondaryLLMAdapter
+				if schedulerLLM == nil {
+					schedulerLLM = llmAdapter
+				}
+				jobScheduler, closeJobs, err := newScheduler(&cfg.Scheduler, schedulerLLM, log)
+				if err != nil {
+					log.Error("Failed to initialize scheduler", "error", err)
+				} else {
+					defer closeJobs()
+					jobScheduler.SetSender(whatsappAdapter)
+					whatsappAdapter.SetScheduler(jobScheduler)
+					scheduler = jobScheduler
+					go scheduler.Start(context.Background())
+				}
+			}
+			
+			// Answer with canned responses and guard rules if enabled
+			if cfg.WhatsApp.ResponseRules.Enabled {
+				// Classify intent rules with the secondary LLM, falling back to the main LLM
+				rulesLLM := secondaryLLMAdapter
+				if rulesLLM == nil {
+					rulesLLM = llmAdapter
+				}
+				rules, err := newResponseRules(&cfg.WhatsApp.ResponseRules, rulesLLM, log)
+				if err != nil {
+					log.Error("Failed to load response rules", "error", err)
+				} else {
+					whatsappAdapter.SetResponseRules(rules)
+					responseRules = rules
+					go responseRules.Watch(context.Background(), cfg.WhatsApp.ResponseRules.ReloadSeconds*time.Second)
+				}
 			}
 			
 			// Start WhatsApp adapter in a goroutine
@@ -159,8 +321,50 @@ func main() {
 		}
 	}
 
+	// Webhook services only need to answer, they are not called
+	for _, service := range cfg.WhatsApp.Services {
+		if service.Enabled && service.URL != "" {
+			health.Register("webhook:"+service.Name, false, func(ctx context.Context) error {
+				return webhookAdapter.Ping(ctx, service)
+			})
+		}
+	}
+
 	// Create HTTP handler
 	handler := httpHandler.NewHandler(chatService, cfg, waAdapter, log)
+	handler.SetHealthService(health)
+	if !cfg.LLMRouting.Enabled {
+		// Routed requests have no single model to manage or switch
+		handler.SetModelService(newModelService(cfg, llmAdapter, imageLLMAdapter, secondaryLLMAdapter, log))
+	}
+	if scheduler != nil {
+		handler.SetScheduler(scheduler)
+	}
+	if responseRules != nil {
+		handler.SetResponseRules(responseRules)
+	}
+	
+	// Require a login for the admin pages and API if enabled
+	if cfg.Auth.Enabled {
+		authService, err := services.NewAuthService(&cfg.Auth, log)
+		if err != nil {
+			log.Error("Failed to initialize admin auth", "error", err)
+			os.Exit(1)
+		}
+		handler.SetAuthService(authService)
+	} else if cfg.WhatsApp.Enabled {
+		log.Warn("Admin auth is disabled, the admin pages and API are open to anyone who can reach the server")
+	}
+	
+	// Let other systems post into WhatsApp groups if enabled
+	if cfg.Hooks.Enabled && waAdapter != nil {
+		// Rewrite hook messages with the secondary LLM, falling back to the main LLM
+		hooksLLM := secondaryLLMAdapter
+		if hooksLLM == nil {
+			hooksLLM = llmAdapter
+		}
+		handler.SetHookService(services.NewHookService(&cfg.Hooks, hooksLLM, waAdapter, log))
+	}
 
 	// Create HTTP server
 	addr := fmt.Sprintf(":%d", cfg.Server.Port)
@@ -168,7 +372,7 @@ func main() {
 		Addr:         addr,
 		Handler:      handler,
 		ReadTimeout:  15 * time.Second,
-		WriteTimeout: 120 * time.Second, // Longer timeout for LLM responses
+		WriteTimeout: 120 * time.Second, // Lifted by the handler for reply generation, which the LLM timeouts bound
 		IdleTimeout:  60 * time.Second,
 	}
 	
@@ -210,3 +414,235 @@ func main() {
 
 	log.Info("Server exited")
 }
+
+// newChatRepository creates the chat repository for the configured storage backend
+// The returned function releases the repository's resources on shutdown
+func newChatRepository(storageConfig *config.StorageConfig, log logger.Logger) (ports.ChatRepositoryPort, func(), error) {
+	switch storageConfig.ChatRepository {
+	case "memory", "":
+		return repository.NewInMemoryRepository(log), func() {}, nil
+	case "sqlite":
+		dbPath := storageConfig.SQLitePath
+		if dbPath == "" {
+			dataDir, err := database.DataDir()
+			if err != nil {
+				return nil, nil, err
+			}
+			dbPath = filepath.Join(dataDir, "chats.db")
+		}
+
+		repo, err := repository.NewSQLiteRepository(dbPath, log)
+		if err != nil {
+			return nil, nil, err
+		}
+		return repo, func() {
+			if err := repo.Close(); err != nil {
+				log.Error("Failed to close chat repository", "error", err)
+			}
+		}, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown chat repository: %s", storageConfig.ChatRepository)
+	}
+}
+
+// newMessageLog opens the SQLite log of group messages
+func newMessageLog(messageLogConfig *config.MessageLogConfig, log logger.Logger) (ports.MessageLogPort, func(), error) {
+	dbPath := messageLogConfig.SQLitePath
+	if dbPath == "" {
+		dataDir, err := database.DataDir()
+		if err != nil {
+			return nil, nil, err
+		}
+		dbPath = filepath.Join(dataDir, "message_log.db")
+	}
+
+	messageLog, err := repository.NewSQLiteMessageLog(dbPath, log)
+	if err != nil {
+		return nil, nil, err
+	}
+	return messageLog, func() {
+		if err := messageLog.Close(); err != nil {
+			log.Error("Failed to close message log", "error", err)
+		}
+	}, nil
+}
+
+// newScheduler creates the job scheduler with its SQLite store
+func newScheduler(schedulerConfig *config.SchedulerConfig, llmAdapter ports.LLMPort, log logger.Logger) (*services.Scheduler, func(), error) {
+	dbPath := schedulerConfig.SQLitePath
+	if dbPath == "" {
+		dataDir, err := database.DataDir()
+		if err != nil {
+			return nil, nil, err
+		}
+		dbPath = filepath.Join(dataDir, "jobs.db")
+	}
+
+	repo, err := repository.NewSQLiteJobRepository(dbPath, log)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	scheduler, err := services.NewScheduler(repo, llmAdapter, schedulerConfig, log)
+	if err != nil {
+		repo.Close()
+		return nil, nil, err
+	}
+	return scheduler, func() {
+		if err := repo.Close(); err != nil {
+			log.Error("Failed to close job repository", "error", err)
+		}
+	}, nil
+}
+
+// newResponseRules loads the response rules file
+func newResponseRules(rulesConfig *config.ResponseRulesConfig, llmAdapter ports.LLMPort, log logger.Logger) (*services.ResponseRuleService, error) {
+	path := rulesConfig.Path
+	if path == "" {
+		dataDir, err := database.DataDir()
+		if err != nil {
+			return nil, err
+		}
+		path = filepath.Join(dataDir, "response_rules.json")
+	}
+
+	return services.NewResponseRuleService(path, llmAdapter, log)
+}
+
+// newDocumentService creates the document service with its SQLite store
+// Chunks are embedded when embeddings are enabled and matched by keyword otherwise
+func newDocumentService(cfg *config.Config, log logger.Logger) (*services.DocumentService, func(), error) {
+	dbPath := cfg.Documents.SQLitePath
+	if dbPath == "" {
+		dataDir, err := database.DataDir()
+		if err != nil {
+			return nil, nil, err
+		}
+		dbPath = filepath.Join(dataDir, "documents.db")
+	}
+
+	repo, err := repository.NewSQLiteDocumentRepository(dbPath, log)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var embedder ports.EmbeddingPort
+	if cfg.Embeddings.Enabled {
+		embedder = llm.NewOllamaEmbeddingAdapter(&cfg.Embeddings, log)
+	}
+
+	documentService := services.NewDocumentService(repo, documents.NewTextExtractor(), embedder, &cfg.Documents, log)
+	return documentService, func() {
+		if err := repo.Close(); err != nil {
+			log.Error("Failed to close document repository", "error", err)
+		}
+	}, nil
+}
+
+// registerTools registers the built-in tools the LLM can call
+func registerTools(chatService *services.ChatService, cfg *config.Config, webSearch ports.WebSearchPort, webhookAdapter ports.WebhookPort, log logger.Logger) {
+	if !cfg.Tools.Enabled {
+		return
+	}
+	
+	if webSearch != nil {
+		chatService.RegisterTool(tools.NewWebSearchTool(webSearch))
+	}
+	
+	if cfg.Tools.ImageGenerationEndpoint != "" {
+		generator := imagegen.NewImageGenerator(cfg.Tools.ImageGenerationEndpoint, log)
+		chatService.RegisterTool(tools.NewImageGenerationTool(generator))
+	}
+	
+	for _, service := range cfg.WhatsApp.Services {
+		if service.Enabled && service.Tool && service.URL != "" {
+			chatService.RegisterTool(tools.NewWebhookTool(service, webhookAdapter))
+		}
+	}
+}
+
+// registerLLMProbe probes an LLM adapter's backend if the adapter can check it
+func registerLLMProbe(health *services.HealthService, name string, adapter ports.LLMPort, critical bool) {
+	if checker, ok := adapter.(ports.HealthCheckerPort); ok {
+		health.Register(name, critical, checker.Ping)
+	}
+}
+
+// newModelService manages the main LLM's server and lets each distinct LLM adapter switch models
+// Model management bypasses the request queues
+func newModelService(cfg *config.Config, llmAdapter, imageLLMAdapter, secondaryLLMAdapter ports.LLMPort, log logger.Logger) *services.ModelService {
+	manager, _ := unwrapLLM(llmAdapter).(ports.ModelManagerPort)
+	models := services.NewModelService(cfg, manager, log)
+	models.SetRole(domain.ModelRoleMain, unwrapLLM(llmAdapter))
+	if imageLLMAdapter != llmAdapter {
+		models.SetRole(domain.ModelRoleImage, unwrapLLM(imageLLMAdapter))
+	}
+	if secondaryLLMAdapter != nil {
+		models.SetRole(domain.ModelRoleSecondary, unwrapLLM(secondaryLLMAdapter))
+	}
+	return models
+}
+
+// queueLLM puts an LLM adapter behind the request queue of its server if enabled
+// The queue is created by the first adapter on the server, a concurrency of 0 uses the queue's default
+func queueLLM(cfg *config.Config, queues map[string]*llm.RequestQueue, adapter ports.LLMPort, llmConfig *config.LLMConfig,
+	concurrency int, log logger.Logger) ports.LLMPort {
+	if !cfg.LLMQueue.Enabled {
+		return adapter
+	}
+
+	endpoint := llmConfig.Endpoint()
+	queue, ok := queues[endpoint]
+	if !ok {
+		if concurrency <= 0 {
+			concurrency = cfg.LLMQueue.Concurrency
+		}
+		log.Info("Queueing LLM requests", "endpoint", endpoint, "concurrency", concurrency)
+		queue = llm.NewRequestQueue(endpoint, concurrency, &cfg.LLMQueue, log)
+		queues[endpoint] = queue
+	}
+	return llm.NewQueuedLLM(adapter, queue)
+}
+
+// unwrapLLM returns the adapter behind a request queue
+func unwrapLLM(adapter ports.LLMPort) ports.LLMPort {
+	if queued, ok := adapter.(*llm.QueuedLLM); ok {
+		return queued.Unwrap()
+	}
+	return adapter
+}
+
+// newLLMRouter creates an adapter for each routing backend and a router over them
+func newLLMRouter(cfg *config.Config, queues map[string]*llm.RequestQueue, log logger.Logger) (*llm.Router, error) {
+	if len(cfg.LLMRouting.Backends) == 0 {
+		return nil, fmt.Errorf("llm_routing is enabled without backends")
+	}
+
+	backends := make([]llm.Backend, 0, len(cfg.LLMRouting.Backends))
+	for i, backendConfig := range cfg.LLMRouting.Backends {
+		if backendConfig.Name == "" {
+			backendConfig.Name = fmt.Sprintf("backend-%d", i+1)
+		}
+		log.Info("Initializing LLM backend", "backend", backendConfig.Name, "provider", backendConfig.Provider, "tasks", backendConfig.Tasks)
+		llmConfig := backendConfig.AsLLMConfig(cfg.LLM.EnableReasoning)
+		adapter, err := newLLMAdapter(llmConfig, log)
+		if err != nil {
+			return nil, fmt.Errorf("backend %s: %w", backendConfig.Name, err)
+		}
+		adapter = queueLLM(cfg, queues, adapter, llmConfig, backendConfig.Concurrency, log)
+		backends = append(backends, llm.Backend{Name: backendConfig.Name, LLM: adapter, Config: backendConfig})
+	}
+	return llm.NewRouter(&cfg.LLMRouting, backends, log), nil
+}
+
+// newLLMAdapter creates the LLM adapter for the configured provider
+func newLLMAdapter(llmConfig *config.LLMConfig, log logger.Logger) (ports.LLMPort, error) {
+	switch llmConfig.Provider {
+	case "ollama", "":
+		return llm.NewOllamaAdapter(llmConfig, log)
+	case "openai":
+		return llm.NewOpenAIAdapter(llmConfig, log)
+	default:
+		return nil, fmt.Errorf("unknown LLM provider: %s", llmConfig.Provider)
+	}
+}
@@ -3,6 +3,8 @@ package config
 import (
 	"encoding/json"
 	"os"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -14,6 +16,41 @@ type Config struct {
 	SecondaryLLM SecondaryLLMConfig `json:"secondary_llm"`
 	ImageLLM     ImageLLMConfig     `json:"image_llm"`
 	WhatsApp     WhatsAppConfig     `json:"whatsapp"`
+	Storage      StorageConfig      `json:"storage"`
+	History      HistoryConfig      `json:"history"`
+	Tools        ToolsConfig        `json:"tools"`
+	Embeddings   EmbeddingsConfig   `json:"embeddings"`
+	Speech       SpeechConfig       `json:"speech"`
+	Documents    DocumentsConfig    `json:"documents"`
+	Scheduler    SchedulerConfig    `json:"scheduler"`
+	Hooks        HooksConfig        `json:"hooks"`
+	Auth         AuthConfig         `json:"auth"`
+	Health       HealthConfig       `json:"health"`
+	LLMRouting   LLMRoutingConfig   `json:"llm_routing"`
+	LLMQueue     LLMQueueConfig     `json:"llm_queue"`
+
+	mutex sync.RWMutex // Guards the settings changed at runtime, see Lock
+}
+
+// Lock locks the configuration for a change made at runtime, such as switching a model
+// SaveConfig holds the read lock while it writes the file
+func (c *Config) Lock() {
+	c.mutex.Lock()
+}
+
+// Unlock undoes Lock
+func (c *Config) Unlock() {
+	c.mutex.Unlock()
+}
+
+// RLock locks the configuration for reading settings that are changed at runtime
+func (c *Config) RLock() {
+	c.mutex.RLock()
+}
+
+// RUnlock undoes RLock
+func (c *Config) RUnlock() {
+	c.mutex.RUnlock()
 }
 
 // ServerConfig holds HTTP server configuration
@@ -21,11 +58,119 @@ type ServerConfig struct {
 	Port int `json:"port"`
 }
 
+// StorageConfig holds configuration for chat persistence
+type StorageConfig struct {
+	ChatRepository string `json:"chat_repository"` // "memory" or "sqlite"
+	SQLitePath     string `json:"sqlite_path"`     // Defaults to chats.db in the data directory
+}
+
+// HistoryConfig holds configuration for fitting chat history into the model context
+type HistoryConfig struct {
+	MaxContextTokens  int `json:"max_context_tokens"`  // Token budget for history sent to the LLM, 0 disables budgeting
+	MinRecentMessages int `json:"min_recent_messages"` // Newest messages always sent verbatim
+	SummaryMaxWords   int `json:"summary_max_words"`   // Length limit for the rolling summary of older messages
+}
+
+// ToolsConfig holds configuration for LLM tool calling
+type ToolsConfig struct {
+	Enabled                 bool   `json:"enabled"`
+	MaxIterations           int    `json:"max_iterations"` // Tool calling rounds before a final answer is forced
+	ImageGenerationEndpoint string `json:"image_generation_endpoint"`
+}
+
+// EmbeddingsConfig holds configuration for embedding-based memory retrieval
+type EmbeddingsConfig struct {
+	Enabled        bool          `json:"enabled"`
+	Endpoint       string        `json:"endpoint"` // Ollama base URL
+	Model          string        `json:"model"`
+	TopK           int           `json:"top_k"`          // Memories added to a prompt
+	MinSimilarity  float64       `json:"min_similarity"` // Cosine similarity below which memories are ignored
+	TimeoutSeconds time.Duration `json:"timeout_seconds"`
+}
+
+// SpeechConfig holds configuration for voice note transcription
+type SpeechConfig struct {
+	Enabled        bool          `json:"enabled"`
+	API            string        `json:"api"`      // "whispercpp" for the whisper.cpp server or "openai" for /v1/audio/transcriptions
+	Endpoint       string        `json:"endpoint"` // Base URL of the transcription server
+	Model          string        `json:"model"`    // Only sent to OpenAI-compatible servers such as faster-whisper-server
+	Language       string        `json:"language"` // Empty lets the server detect the language
+	TimeoutSeconds time.Duration `json:"timeout_seconds"`
+}
+
+// DocumentsConfig holds configuration for document ingestion and question answering
+type DocumentsConfig struct {
+	Enabled       bool   `json:"enabled"`
+	SQLitePath    string `json:"sqlite_path"`      // Defaults to documents.db in the data directory
+	MaxFileSizeMB int    `json:"max_file_size_mb"` // Larger files are ignored
+	ChunkSize     int    `json:"chunk_size"`       // Characters per chunk
+	ChunkOverlap  int    `json:"chunk_overlap"`    // Characters repeated between neighbouring chunks
+	TopK          int    `json:"top_k"`            // Chunks added to a question
+}
+
+// SchedulerConfig holds configuration for scheduled reminders and recurring messages
+type SchedulerConfig struct {
+	Enabled             bool          `json:"enabled"`
+	SQLitePath          string        `json:"sqlite_path"`           // Defaults to jobs.db in the data directory
+	Timezone            string        `json:"timezone"`              // IANA name such as "Europe/London", empty uses the server's local time
+	PollIntervalSeconds time.Duration `json:"poll_interval_seconds"` // How often due jobs are checked
+	MaxJobsPerChat      int           `json:"max_jobs_per_chat"`
+	MinIntervalMinutes  time.Duration `json:"min_interval_minutes"` // Shortest repeat of reminders written in natural language
+}
+
+// AuthConfig holds login settings for the admin pages and API
+type AuthConfig struct {
+	Enabled        bool              `json:"enabled"`
+	Users          []AdminUserConfig `json:"users"`
+	APITokens      []APITokenConfig  `json:"api_tokens"`
+	SessionHours   time.Duration     `json:"session_hours"`   // How long a login lasts, 0 uses 12
+	SecureCookies  bool              `json:"secure_cookies"`  // Only send the session cookie over HTTPS
+	AllowedOrigins []string          `json:"allowed_origins"` // Cross-origin sites allowed to call the API, empty allows none
+}
+
+// HealthConfig holds settings for the dependency probes behind /healthz and /readyz
+type HealthConfig struct {
+	TimeoutSeconds time.Duration `json:"timeout_seconds"` // Per probe, 0 uses 3
+	CacheSeconds   time.Duration `json:"cache_seconds"`   // How long a report is reused, so frequent probes don't load the dependencies
+}
+
+// AdminUserConfig holds a user who can log in to the admin pages
+type AdminUserConfig struct {
+	Username     string `json:"username"`
+	PasswordHash string `json:"password_hash"` // bcrypt hash, create one with -hash-password
+	Role         string `json:"role"`          // "viewer", "memory-editor" or "admin"
+}
+
+// APITokenConfig holds a token scripts can use instead of logging in
+type APITokenConfig struct {
+	Name      string `json:"name"`
+	TokenHash string `json:"token_hash"` // Hex SHA-256 of the token, create one with -new-token
+	Role      string `json:"role"`
+}
+
+// HooksConfig holds configuration for inbound webhooks that post messages into WhatsApp groups
+type HooksConfig struct {
+	Enabled bool                `json:"enabled"`
+	Hooks   []InboundHookConfig `json:"hooks"`
+}
+
+// InboundHookConfig holds configuration for one inbound webhook, called at /api/hooks/{id}
+type InboundHookConfig struct {
+	ID                 string `json:"id"`
+	GroupID            string `json:"group_id"`              // Group JID the messages are posted to
+	Token              string `json:"token"`                 // Required as a bearer token or X-Hook-Token header
+	Template           string `json:"template"`              // Go template over the JSON payload, empty posts its "message" field
+	Rewrite            bool   `json:"rewrite"`               // Have the LLM rewrite the message before posting
+	RewritePrompt      string `json:"rewrite_prompt"`        // Instructions for the rewrite, empty uses a friendly default
+	RateLimitPerMinute int    `json:"rate_limit_per_minute"` // 0 uses 10
+}
+
 // LLMConfig holds configuration for the LLM backend
 type LLMConfig struct {
-	Provider        string        `json:"provider"`
+	Provider        string        `json:"provider"` // "ollama" or "openai"
 	EnableReasoning bool          `json:"enable_reasoning"`
 	Ollama          OllamaConfig  `json:"ollama"`
+	OpenAI          OpenAIConfig  `json:"openai"`
 	DefaultTimeout  time.Duration `json:"default_timeout"`
 	DefaultMaxToken int           `json:"default_max_tokens"`
 }
@@ -39,6 +184,80 @@ type OllamaConfig struct {
 	TimeoutSeconds time.Duration `json:"timeout_seconds"`
 }
 
+// OpenAIConfig holds configuration for OpenAI-compatible servers such as vLLM or llama.cpp
+// It is used when the provider is "openai"
+type OpenAIConfig struct {
+	Endpoint       string        `json:"endpoint"` // Base URL including the version, e.g. http://localhost:8000/v1
+	APIKey         string        `json:"api_key"`
+	Model          string        `json:"model"`
+	MaxTokens      int           `json:"max_tokens"`
+	TimeoutSeconds time.Duration `json:"timeout_seconds"`
+}
+
+// ModelName returns the model name of the configured provider
+func (c *LLMConfig) ModelName() string {
+	if c.Provider == "openai" {
+		return c.OpenAI.Model
+	}
+	return c.Ollama.Model
+}
+
+// Endpoint returns the server URL of the configured provider
+func (c *LLMConfig) Endpoint() string {
+	if c.Provider == "openai" {
+		return c.OpenAI.Endpoint
+	}
+	return c.Ollama.Endpoint
+}
+
+// SetModelName sets the model name of the configured provider
+func (c *LLMConfig) SetModelName(name string) {
+	if c.Provider == "openai" {
+		c.OpenAI.Model = name
+		return
+	}
+	c.Ollama.Model = name
+}
+
+// LLMRoutingConfig holds the ordered LLM backends that replace the main, secondary and image LLMs when enabled
+type LLMRoutingConfig struct {
+	Enabled            bool               `json:"enabled"`
+	Backends           []LLMBackendConfig `json:"backends"`             // Tried in order
+	CooldownSeconds    time.Duration      `json:"cooldown_seconds"`     // How long a failed backend is skipped, 0 uses 30
+	HealthCheckSeconds time.Duration      `json:"health_check_seconds"` // How often backends are probed, 0 disables probing
+}
+
+// LLMBackendConfig holds one backend of the LLM routing chain
+type LLMBackendConfig struct {
+	Name            string        `json:"name"`
+	Provider        string        `json:"provider"` // "ollama" or "openai"
+	Ollama          OllamaConfig  `json:"ollama"`
+	OpenAI          OpenAIConfig  `json:"openai"`
+	TimeoutSeconds  time.Duration `json:"timeout_seconds"`   // Per request, 0 leaves it to the caller
+	Tasks           []string      `json:"tasks"`             // "chat", "search_query", "helper" or "image", empty serves every task
+	MinMessageChars int           `json:"min_message_chars"` // Only serve requests whose last message is at least this long
+	MaxMessageChars int           `json:"max_message_chars"` // Only serve requests whose last message is at most this long, 0 for no limit
+	Concurrency     int           `json:"concurrency"`       // Overrides llm_queue.concurrency for this backend's endpoint, the first backend on an endpoint wins
+}
+
+// LLMQueueConfig holds the limits of the queue in front of each LLM server
+type LLMQueueConfig struct {
+	Enabled        bool          `json:"enabled"`
+	Concurrency    int           `json:"concurrency"`      // Requests each LLM server runs at once, 0 uses 1
+	MaxQueued      int           `json:"max_queued"`       // Requests waiting per LLM server before new ones are refused, 0 uses 20
+	MaxWaitSeconds time.Duration `json:"max_wait_seconds"` // How long a request may wait for its turn, 0 uses 60
+}
+
+// AsLLMConfig returns an LLMConfig for initializing the backend's adapter
+func (c *LLMBackendConfig) AsLLMConfig(enableReasoning bool) *LLMConfig {
+	return &LLMConfig{
+		Provider:        c.Provider,
+		EnableReasoning: enableReasoning,
+		Ollama:          c.Ollama,
+		OpenAI:          c.OpenAI,
+	}
+}
+
 // WebSearchConfig holds configuration for web search functionality
 type WebSearchConfig struct {
 	Enabled        bool     `json:"enabled"`
@@ -52,6 +271,25 @@ type WebSearchConfig struct {
 type SecondaryLLMConfig struct {
 	Provider string      `json:"provider"`
 	Ollama   OllamaConfig `json:"ollama"`
+	OpenAI   OpenAIConfig `json:"openai"`
+}
+
+// SetModelName sets the model name of the secondary LLM's provider
+func (c *SecondaryLLMConfig) SetModelName(name string) {
+	if c.Provider == "openai" {
+		c.OpenAI.Model = name
+		return
+	}
+	c.Ollama.Model = name
+}
+
+// AsLLMConfig returns an LLMConfig for initializing the secondary LLM adapter
+func (c *SecondaryLLMConfig) AsLLMConfig() *LLMConfig {
+	return &LLMConfig{
+		Provider: c.Provider,
+		Ollama:   c.Ollama,
+		OpenAI:   c.OpenAI,
+	}
 }
 
 // ImageLLMConfig holds configuration for the image analysis LLM
@@ -59,24 +297,77 @@ type ImageLLMConfig struct {
 	Enabled  bool        `json:"enabled"`
 	Provider string      `json:"provider"`
 	Ollama   OllamaConfig `json:"ollama"`
+	OpenAI   OpenAIConfig `json:"openai"`
 }
 
-// FamilyServiceConfig holds configuration for the family service webhook
-type FamilyServiceConfig struct {
-	Enabled        bool          `json:"enabled"`
-	WebhookURL     string        `json:"webhook_url"`
-	TimeoutSeconds time.Duration `json:"timeout_seconds"`
+// SetModelName sets the model name of the image analysis LLM's provider
+func (c *ImageLLMConfig) SetModelName(name string) {
+	if c.Provider == "openai" {
+		c.OpenAI.Model = name
+		return
+	}
+	c.Ollama.Model = name
 }
 
-// FoodServiceConfig holds configuration for the food service webhook
-type FoodServiceConfig struct {
-	Enabled        bool          `json:"enabled"`
-	WebhookURL     string        `json:"webhook_url"`
-	TimeoutSeconds time.Duration `json:"timeout_seconds"`
+// AsLLMConfig returns an LLMConfig for initializing the image analysis LLM adapter
+func (c *ImageLLMConfig) AsLLMConfig() *LLMConfig {
+	return &LLMConfig{
+		Provider: c.Provider,
+		Ollama:   c.Ollama,
+		OpenAI:   c.OpenAI,
+	}
+}
+
+// WebhookServiceConfig holds configuration for an external chat service, such as an n8n workflow
+type WebhookServiceConfig struct {
+	Name           string               `json:"name"`
+	Enabled        bool                 `json:"enabled"`
+	Trigger        string               `json:"trigger"`     // Keyword that sends a message to the service, such as "@family"
+	Description    string               `json:"description"` // Shown in the help listing, and to the LLM when Tool is set
+	MentionOnly    bool                 `json:"mention_only"`
+	Priority       int                  `json:"priority"` // Command priority, higher is matched first
+	URL            string               `json:"url"`
+	Method         string               `json:"method"`        // Defaults to POST
+	BodyTemplate   string               `json:"body_template"` // Go template with .ChatInput, .Sender, .Group and .Memories, and a json function
+	ResponsePath   string               `json:"response_path"` // Dot path to the reply in the JSON response, such as "0.text", empty uses the whole body
+	TimeoutSeconds time.Duration        `json:"timeout_seconds"`
+	Headers        map[string]string    `json:"headers"`
+	Tool           bool                 `json:"tool"` // Also offer the service to the LLM as a tool
+	Auth           WebhookAuthConfig    `json:"auth"`
+	Retry          WebhookRetryConfig   `json:"retry"`
+	CircuitBreaker WebhookBreakerConfig `json:"circuit_breaker"`
+}
+
+// WebhookAuthConfig holds how requests to a webhook service are authenticated
+type WebhookAuthConfig struct {
+	Type            string `json:"type"` // "", "bearer", "basic" or "hmac"
+	Token           string `json:"token"`
+	Username        string `json:"username"`
+	Password        string `json:"password"`
+	Secret          string `json:"secret"`           // HMAC-SHA256 key
+	SignatureHeader string `json:"signature_header"` // Defaults to X-Signature
+	TimestampHeader string `json:"timestamp_header"` // Defaults to X-Timestamp
+}
+
+// WebhookRetryConfig holds how failed requests to a webhook service are retried
+// Connection errors before the request is sent, 429 and 5xx responses are retried with exponential backoff
+// Timeouts are not, since the service may already be running the request
+type WebhookRetryConfig struct {
+	MaxAttempts           int           `json:"max_attempts"`            // Including the first request, 0 uses 1 so services are only retried when they ask for it
+	InitialBackoffSeconds time.Duration `json:"initial_backoff_seconds"` // 0 uses 1
+	MaxBackoffSeconds     time.Duration `json:"max_backoff_seconds"`     // 0 uses 10
 }
 
-// WebServiceConfig holds configuration for the web search service webhook
-type WebServiceConfig struct {
+// WebhookBreakerConfig holds when a failing webhook service is considered down
+// While the breaker is open calls fail at once instead of waiting for each timeout
+type WebhookBreakerConfig struct {
+	FailureThreshold int           `json:"failure_threshold"` // Consecutive failed calls that open the breaker, 0 uses 5
+	CooldownSeconds  time.Duration `json:"cooldown_seconds"`  // How long the breaker stays open before a trial call, 0 uses 60
+}
+
+// LegacyServiceConfig holds the old per-service webhook settings
+// Deprecated: converted to WhatsAppConfig.Services when the configuration is loaded
+type LegacyServiceConfig struct {
 	Enabled        bool          `json:"enabled"`
 	WebhookURL     string        `json:"webhook_url"`
 	TimeoutSeconds time.Duration `json:"timeout_seconds"`
@@ -90,6 +381,52 @@ type ComfyUIServiceConfig struct {
 	TimeoutSeconds time.Duration `json:"timeout_seconds"`
 }
 
+// DirectMessagesConfig holds configuration for private chats with the bot
+type DirectMessagesConfig struct {
+	Enabled         bool     `json:"enabled"`
+	AllowedContacts []string `json:"allowed_contacts"` // Contact JIDs or phone numbers, "*" allows everyone
+}
+
+// MessageLogConfig holds configuration for the passive log of group messages used for digests
+type MessageLogConfig struct {
+	Enabled             bool   `json:"enabled"`
+	SQLitePath          string `json:"sqlite_path"`            // Defaults to message_log.db in the data directory
+	RetentionHours      int    `json:"retention_hours"`        // Older messages are deleted, 0 keeps them
+	MaxMessagesPerGroup int    `json:"max_messages_per_group"` // Older messages beyond this are deleted, 0 keeps them
+}
+
+// ResponseRulesConfig holds settings for canned responses and guard rules
+type ResponseRulesConfig struct {
+	Enabled       bool          `json:"enabled"`
+	Path          string        `json:"path"`           // Defaults to response_rules.json in the data directory
+	ReloadSeconds time.Duration `json:"reload_seconds"` // How often the file is checked for changes, 0 disables reloading
+}
+
+// PersonaConfig holds how the bot presents itself in the groups it is assigned to
+type PersonaConfig struct {
+	Name            string   `json:"name"`
+	SystemPrompt    string   `json:"system_prompt"`    // Empty uses the LLM adapter's default prompt
+	Tone            string   `json:"tone"`             // Such as "playful" or "formal", added to the system prompt
+	Language        string   `json:"language"`         // Replies are written in this language when set
+	AllowedCommands []string `json:"allowed_commands"` // Command names usable in the group, empty allows all
+	Creator         string   `json:"creator"`          // Used when asked who made the bot
+	Home            string   `json:"home"`             // Used when asked where the bot is running
+	ModelAlias      string   `json:"model_alias"`      // Used when asked which model powers the bot
+}
+
+// AllowsCommand reports whether a command may be used with this persona
+func (p *PersonaConfig) AllowsCommand(name string) bool {
+	if len(p.AllowedCommands) == 0 {
+		return true
+	}
+	for _, allowed := range p.AllowedCommands {
+		if strings.EqualFold(allowed, name) {
+			return true
+		}
+	}
+	return false
+}
+
 // WhatsAppConfig holds configuration for the WhatsApp integration
 type WhatsAppConfig struct {
 	Enabled      bool     `json:"enabled"`
@@ -98,10 +435,97 @@ type WhatsAppConfig struct {
 	TriggerWord  string   `json:"trigger_word"` // Deprecated: kept for backward compatibility
 	StoreDir     string   `json:"store_dir"`
 	AllowedGroups []string `json:"allowed_groups"`
-	FamilyService FamilyServiceConfig `json:"family_service"`
-	FoodService   FoodServiceConfig   `json:"food_service"`
-	WebService    WebServiceConfig    `json:"web_service"`
+	Services      []WebhookServiceConfig `json:"services"`
+	FamilyService *LegacyServiceConfig `json:"family_service,omitempty"` // Deprecated: use Services
+	FoodService   *LegacyServiceConfig `json:"food_service,omitempty"`   // Deprecated: use Services
+	WebService    *LegacyServiceConfig `json:"web_service,omitempty"`    // Deprecated: use Services
 	ComfyUIService ComfyUIServiceConfig `json:"comfyui_service"`
+	DirectMessages DirectMessagesConfig `json:"direct_messages"`
+	MessageLog     MessageLogConfig     `json:"message_log"`
+	ResponseRules  ResponseRulesConfig  `json:"response_rules"`
+	Personas       map[string]PersonaConfig `json:"personas"`
+	DefaultPersona string               `json:"default_persona"` // Persona used by groups without an assignment
+	GroupPersonas  map[string]string    `json:"group_personas"`  // Group JID to persona key
+	QueuePositionMessage string `json:"queue_position_message"` // Sent when a reply has to wait for the LLM, %d is the place in line. Empty sends nothing
+	BusyMessage          string `json:"busy_message"`           // Sent when the LLM queue is full or the reply waited too long
+}
+
+// migrateLegacyServices converts the old family, food and web service settings into Services
+// Services that are already configured by name are left alone
+func (c *WhatsAppConfig) migrateLegacyServices() {
+	legacy := []struct {
+		config  *LegacyServiceConfig
+		service WebhookServiceConfig
+	}{
+		{c.FamilyService, WebhookServiceConfig{
+			Name:         "family",
+			Trigger:      "@family",
+			Description:  "Ask the family service about the family, such as family members, birthdays and events.",
+			Priority:     80,
+			ResponsePath: "response",
+			Tool:         true,
+		}},
+		{c.FoodService, WebhookServiceConfig{
+			Name:         "food",
+			Trigger:      "@food",
+			Description:  "Ask the food service about food, such as meals, recipes and groceries.",
+			Priority:     60,
+			ResponsePath: "0.text",
+			Tool:         true,
+		}},
+		{c.WebService, WebhookServiceConfig{
+			Name:         "web",
+			Trigger:      "@web",
+			Description:  "Search the web",
+			MentionOnly:  true,
+			Priority:     50,
+			BodyTemplate: `{"topic": {{json .ChatInput}}}`,
+			ResponsePath: "whatsapp_message",
+		}},
+	}
+
+	for _, entry := range legacy {
+		if entry.config == nil || c.Service(entry.service.Name) != nil {
+			continue
+		}
+		service := entry.service
+		service.Enabled = entry.config.Enabled
+		service.URL = entry.config.WebhookURL
+		service.TimeoutSeconds = entry.config.TimeoutSeconds
+		c.Services = append(c.Services, service)
+	}
+
+	c.FamilyService = nil
+	c.FoodService = nil
+	c.WebService = nil
+}
+
+// Service returns the webhook service with the given name, or nil
+func (c *WhatsAppConfig) Service(name string) *WebhookServiceConfig {
+	for i := range c.Services {
+		if c.Services[i].Name == name {
+			return &c.Services[i]
+		}
+	}
+	return nil
+}
+
+// PersonaKey returns the key of the persona assigned to a chat, or the default persona key
+func (c *WhatsAppConfig) PersonaKey(chatJID string) string {
+	if key, ok := c.GroupPersonas[chatJID]; ok {
+		return key
+	}
+	return c.DefaultPersona
+}
+
+// PersonaFor returns the persona assigned to a chat, then the default persona,
+// and finally a plain persona with only the bot name
+func (c *WhatsAppConfig) PersonaFor(chatJID string) PersonaConfig {
+	persona := c.Personas[c.PersonaKey(chatJID)]
+	if persona.Name == "" {
+		persona.Name = c.BotName
+	}
+	return persona
 }
 
 // LoadConfig loads configuration from a JSON file
@@ -128,12 +552,25 @@ func LoadConfig(path string) (*Config, error) {
 			DefaultTimeout:  100 * time.Second,
 			DefaultMaxToken: 256,
 		},
+		History: HistoryConfig{
+			MaxContextTokens:  6000,
+			MinRecentMessages: 4,
+			SummaryMaxWords:   200,
+		},
+		WhatsApp: WhatsAppConfig{
+			// Configs written before response rules existed still get the built-in answers
+			ResponseRules: ResponseRulesConfig{
+				Enabled:       true,
+				ReloadSeconds: 5,
+			},
+		},
 	}
 
 	decoder := json.NewDecoder(file)
 	if err := decoder.Decode(config); err != nil {
 		return nil, err
 	}
+	config.WhatsApp.migrateLegacyServices()
 
 	return config, nil
 }
@@ -154,6 +591,12 @@ func DefaultConfig() *Config {
 				MaxTokens:      4096,
 				TimeoutSeconds: 100,
 			},
+			OpenAI: OpenAIConfig{
+				Endpoint:       "http://localhost:8000/v1",
+				Model:          "",
+				MaxTokens:      4096,
+				TimeoutSeconds: 100,
+			},
 			DefaultTimeout:  100 * time.Second,
 			DefaultMaxToken: 4096,
 		},
@@ -192,20 +635,72 @@ func DefaultConfig() *Config {
 			TriggerWord:  "@sasi", // Deprecated: kept for backward compatibility
 			StoreDir:     "./data/whatsapp",
 			AllowedGroups: []string{},
-			FamilyService: FamilyServiceConfig{
-				Enabled:        true,
-				WebhookURL:     "http://192.168.1.132:5678/webhook/f65ba2b8-582c-4575-b4b9-02b26edc3ea0/chat",
-				TimeoutSeconds: 30,
+			DirectMessages: DirectMessagesConfig{
+				Enabled:         false,
+				AllowedContacts: []string{},
 			},
-			FoodService: FoodServiceConfig{
-				Enabled:        true,
-				WebhookURL:     "http://192.168.1.132:5678/webhook/appify",
-				TimeoutSeconds: 30,
+			MessageLog: MessageLogConfig{
+				Enabled:             true,
+				SQLitePath:          "",
+				RetentionHours:      72,
+				MaxMessagesPerGroup: 2000,
 			},
-			WebService: WebServiceConfig{
-				Enabled:        true,
-				WebhookURL:     "http://192.168.1.132:7000/search",
-				TimeoutSeconds: 30,
+			ResponseRules: ResponseRulesConfig{
+				Enabled:       true,
+				Path:          "",
+				ReloadSeconds: 5,
+			},
+			Personas: map[string]PersonaConfig{
+				"sasi": {
+					Name:         "Sasi",
+					SystemPrompt: "",
+					Tone:         "",
+					Language:     "",
+					Creator:      "Avarachan",
+					Home:         "Avarachan's garage",
+					ModelAlias:   "Avarran 007",
+				},
+			},
+			DefaultPersona: "sasi",
+			GroupPersonas:  map[string]string{},
+			QueuePositionMessage: "⏳ You're #%d in line, I'll reply as soon as I can.",
+			BusyMessage:          "😔 I'm too busy to answer right now, please try again in a few minutes.",
+			// The services ship disabled, set a URL and enable the ones you run
+			Services: []WebhookServiceConfig{
+				{
+					Name:           "family",
+					Enabled:        false,
+					Trigger:        "@family",
+					Description:    "Ask the family service about the family, such as family members, birthdays and events.",
+					Priority:       80,
+					URL:            "",
+					ResponsePath:   "response",
+					TimeoutSeconds: 30,
+					Tool:           true,
+				},
+				{
+					Name:           "food",
+					Enabled:        false,
+					Trigger:        "@food",
+					Description:    "Ask the food service about food, such as meals, recipes and groceries.",
+					Priority:       60,
+					URL:            "",
+					ResponsePath:   "0.text",
+					TimeoutSeconds: 30,
+					Tool:           true,
+				},
+				{
+					Name:           "web",
+					Enabled:        false,
+					Trigger:        "@web",
+					Description:    "Search the web",
+					MentionOnly:    true,
+					Priority:       50,
+					URL:            "",
+					BodyTemplate:   `{"topic": {{json .ChatInput}}}`,
+					ResponsePath:   "whatsapp_message",
+					TimeoutSeconds: 30,
+				},
 			},
 			ComfyUIService: ComfyUIServiceConfig{
 				Enabled:        true,
@@ -214,5 +709,78 @@ func DefaultConfig() *Config {
 				TimeoutSeconds: 60,
 			},
 		},
+		Storage: StorageConfig{
+			ChatRepository: "memory",
+			SQLitePath:     "",
+		},
+		History: HistoryConfig{
+			MaxContextTokens:  6000,
+			MinRecentMessages: 4,
+			SummaryMaxWords:   200,
+		},
+		Tools: ToolsConfig{
+			Enabled:                 false,
+			MaxIterations:           5,
+			ImageGenerationEndpoint: "",
+		},
+		Embeddings: EmbeddingsConfig{
+			Enabled:        true,
+			Endpoint:       "http://localhost:11434",
+			Model:          "nomic-embed-text",
+			TopK:           5,
+			MinSimilarity:  0.3,
+			TimeoutSeconds: 30,
+		},
+		Speech: SpeechConfig{
+			Enabled:        false,
+			API:            "whispercpp",
+			Endpoint:       "http://localhost:8081",
+			Model:          "Systran/faster-whisper-small",
+			Language:       "",
+			TimeoutSeconds: 120,
+		},
+		Documents: DocumentsConfig{
+			Enabled:       true,
+			SQLitePath:    "",
+			MaxFileSizeMB: 20,
+			ChunkSize:     1000,
+			ChunkOverlap:  150,
+			TopK:          4,
+		},
+		Scheduler: SchedulerConfig{
+			Enabled:             true,
+			SQLitePath:          "",
+			Timezone:            "",
+			PollIntervalSeconds: 30,
+			MaxJobsPerChat:      25,
+			MinIntervalMinutes:  60,
+		},
+		Hooks: HooksConfig{
+			Enabled: false,
+			Hooks:   []InboundHookConfig{},
+		},
+		Auth: AuthConfig{
+			Enabled:        false,
+			Users:          []AdminUserConfig{},
+			APITokens:      []APITokenConfig{},
+			SessionHours:   12,
+			SecureCookies:  false,
+			AllowedOrigins: []string{},
+		},
+		Health: HealthConfig{
+			TimeoutSeconds: 3,
+			CacheSeconds:   5,
+		},
+		LLMRouting: LLMRoutingConfig{
+			Enabled:            false,
+			CooldownSeconds:    30,
+			HealthCheckSeconds: 15,
+		},
+		LLMQueue: LLMQueueConfig{
+			Enabled:        true,
+			Concurrency:    2,
+			MaxQueued:      20,
+			MaxWaitSeconds: 60,
+		},
 	}
 }
@@ -32,7 +32,9 @@ func SaveConfig(config *Config, path string) error {
 	}
 	
 	// Marshal config to JSON
+	config.RLock()
 	data, err := json.MarshalIndent(config, "", "  ")
+	config.RUnlock()
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,224 @@
+package http
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/vibin/chat-bot/internal/core/domain"
+	"github.com/vibin/chat-bot/internal/core/services"
+)
+
+const (
+	sessionCookie = "chatbot_session"
+	csrfCookie    = "chatbot_csrf" // Readable by the admin pages, which echo it in the X-CSRF-Token header
+	csrfHeader    = "X-CSRF-Token"
+)
+
+// identityKey is the context key for the identity of an admin request
+type identityKey struct{}
+
+// peerAddrKey is the context key for the address of the connection a request arrived on
+type peerAddrKey struct{}
+
+// LoginRequest represents the request structure for logging in
+type LoginRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// setupAuthRoutes sets up the login endpoints
+func (h *Handler) setupAuthRoutes(r chi.Router) {
+	r.Route("/auth", func(r chi.Router) {
+		r.Post("/login", h.handleLogin)
+		r.Post("/logout", h.handleLogout)
+		r.With(h.requireAuth).Get("/me", h.handleMe)
+	})
+}
+
+// requireAuth authenticates admin requests with a session cookie or an API token
+// and checks that the caller's role allows the request. Mutating requests made with
+// a session cookie must carry the session's CSRF token. Without an auth service every request is let through
+func (h *Handler) requireAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if h.auth == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		identity, csrfToken, ok := h.authenticate(r)
+		if !ok {
+			if isPageRequest(r) {
+				http.Redirect(w, r, "/login?next="+url.QueryEscape(r.URL.RequestURI()), http.StatusSeeOther)
+				return
+			}
+			h.respondWithError(w, http.StatusUnauthorized, "Authentication required")
+			return
+		}
+
+		if csrfToken != "" && isMutating(r.Method) {
+			if subtle.ConstantTimeCompare([]byte(r.Header.Get(csrfHeader)), []byte(csrfToken)) != 1 {
+				h.respondWithError(w, http.StatusForbidden, "Missing or invalid CSRF token")
+				return
+			}
+		}
+
+		if required := requiredRole(r); !identity.Role.Includes(required) {
+			h.logger.Warn("Forbidden admin request", "name", identity.Name, "role", identity.Role, "required", required, "path", r.URL.Path)
+			h.respondWithError(w, http.StatusForbidden, "Your role does not allow this")
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), identityKey{}, identity)))
+	})
+}
+
+// authenticate returns the identity of a request, and the CSRF token it needs when it uses a session
+func (h *Handler) authenticate(r *http.Request) (domain.Identity, string, bool) {
+	if header := r.Header.Get("Authorization"); strings.HasPrefix(header, "Bearer ") {
+		identity, ok := h.auth.AuthenticateToken(strings.TrimPrefix(header, "Bearer "))
+		return identity, "", ok
+	}
+
+	cookie, err := r.Cookie(sessionCookie)
+	if err != nil {
+		return domain.Identity{}, "", false
+	}
+	session, ok := h.auth.Session(cookie.Value)
+	if !ok {
+		return domain.Identity{}, "", false
+	}
+	return session.Identity, session.CSRFToken, true
+}
+
+// requiredRole returns the least role allowed to make a request
+// Reading needs a viewer, changing memories a memory editor, and any other change an admin
+func requiredRole(r *http.Request) domain.Role {
+	if !isMutating(r.Method) {
+		return domain.RoleViewer
+	}
+	if strings.HasPrefix(r.URL.Path, "/api/whatsapp/memory/") {
+		return domain.RoleMemoryEditor
+	}
+	return domain.RoleAdmin
+}
+
+// isMutating reports whether a request method can change state
+func isMutating(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+	return true
+}
+
+// isPageRequest reports whether a request is for an admin page rather than the API or metrics
+func isPageRequest(r *http.Request) bool {
+	return strings.HasPrefix(r.URL.Path, "/admin/")
+}
+
+// LoginPage serves the login form
+func (h *Handler) LoginPage(w http.ResponseWriter, r *http.Request) {
+	http.ServeFile(w, r, "./web/templates/login.html")
+}
+
+// handleLogin checks a username and password and sets the session and CSRF cookies
+func (h *Handler) handleLogin(w http.ResponseWriter, r *http.Request) {
+	if h.auth == nil {
+		h.respondWithError(w, http.StatusNotFound, "Authentication is not enabled")
+		return
+	}
+
+	// Requiring JSON stops plain cross-site form posts
+	if !strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		h.respondWithError(w, http.StatusUnsupportedMediaType, "Content-Type must be application/json")
+		return
+	}
+
+	var request LoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	session, err := h.auth.Login(request.Username, request.Password, clientIP(r))
+	if errors.Is(err, services.ErrTooManyAttempts) {
+		h.respondWithError(w, http.StatusTooManyRequests, "Too many failed login attempts, try again later")
+		return
+	}
+	if err != nil {
+		h.respondWithError(w, http.StatusUnauthorized, "Invalid username or password")
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookie,
+		Value:    session.ID,
+		Path:     "/",
+		Expires:  session.ExpiresAt,
+		HttpOnly: true,
+		Secure:   h.config.Auth.SecureCookies,
+		SameSite: http.SameSiteLaxMode,
+	})
+	http.SetCookie(w, &http.Cookie{
+		Name:     csrfCookie,
+		Value:    session.CSRFToken,
+		Path:     "/",
+		Expires:  session.ExpiresAt,
+		Secure:   h.config.Auth.SecureCookies,
+		SameSite: http.SameSiteStrictMode,
+	})
+
+	h.respondWithJSON(w, http.StatusOK, session.Identity)
+}
+
+// rememberPeerAddr keeps the connection address before RealIP replaces it with a client supplied header
+func rememberPeerAddr(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), peerAddrKey{}, r.RemoteAddr)))
+	})
+}
+
+// clientIP returns the address of the connection without the port
+// X-Forwarded-For and X-Real-IP are ignored, as anyone could set them to dodge the login rate limit
+func clientIP(r *http.Request) string {
+	addr, ok := r.Context().Value(peerAddrKey{}).(string)
+	if !ok {
+		addr = r.RemoteAddr
+	}
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
+
+// handleLogout ends the session and clears its cookies
+func (h *Handler) handleLogout(w http.ResponseWriter, r *http.Request) {
+	if h.auth != nil {
+		if cookie, err := r.Cookie(sessionCookie); err == nil {
+			h.auth.Logout(cookie.Value)
+		}
+	}
+
+	for _, name := range []string{sessionCookie, csrfCookie} {
+		http.SetCookie(w, &http.Cookie{Name: name, Value: "", Path: "/", MaxAge: -1})
+	}
+
+	h.respondWithJSON(w, http.StatusOK, map[string]string{"message": "Logged out"})
+}
+
+// handleMe returns who is logged in
+func (h *Handler) handleMe(w http.ResponseWriter, r *http.Request) {
+	identity, ok := r.Context().Value(identityKey{}).(domain.Identity)
+	if !ok {
+		// Auth is disabled, so everyone is an admin
+		identity = domain.Identity{Name: "anonymous", Role: domain.RoleAdmin}
+	}
+	h.respondWithJSON(w, http.StatusOK, identity)
+}
@@ -3,6 +3,8 @@ package http
 import (
 	"encoding/json"
 	"net/http"
+
+	"github.com/vibin/chat-bot/config"
 )
 
 // BotAdminPage serves the bot admin UI
@@ -55,3 +57,63 @@ func (h *Handler) handleSendBotMessage(w http.ResponseWriter, r *http.Request) {
 		"message": "Message sent successfully",
 	})
 }
+
+// PersonaSettings represents the persona profiles and their group assignments
+type PersonaSettings struct {
+	Personas       map[string]config.PersonaConfig `json:"personas"`
+	DefaultPersona string                          `json:"default_persona"`
+	GroupPersonas  map[string]string               `json:"group_personas"`
+	Commands       []string                        `json:"commands,omitempty"` // Command names that can be allowed, read only
+}
+
+// handleGetPersonas returns the persona profiles and the groups they are assigned to
+func (h *Handler) handleGetPersonas(w http.ResponseWriter, r *http.Request) {
+	personas, defaultPersona, groupPersonas := h.whatsappAdapter.GetPersonas()
+	h.respondWithJSON(w, http.StatusOK, PersonaSettings{
+		Personas:       personas,
+		DefaultPersona: defaultPersona,
+		GroupPersonas:  groupPersonas,
+		Commands:       h.whatsappAdapter.GetCommandNames(),
+	})
+}
+
+// handleUpdatePersonas replaces the persona profiles and group assignments and saves the configuration
+func (h *Handler) handleUpdatePersonas(w http.ResponseWriter, r *http.Request) {
+	var request PersonaSettings
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if request.Personas == nil {
+		request.Personas = map[string]config.PersonaConfig{}
+	}
+	if request.GroupPersonas == nil {
+		request.GroupPersonas = map[string]string{}
+	}
+	for key := range request.Personas {
+		if key == "" {
+			h.respondWithError(w, http.StatusBadRequest, "Persona keys must not be empty")
+			return
+		}
+	}
+
+	// The adapter validates the assignments and applies them to new messages
+	// The configuration is locked too so a concurrent save doesn't read the maps while they are replaced
+	h.config.Lock()
+	err := h.whatsappAdapter.UpdatePersonas(request.Personas, request.DefaultPersona, request.GroupPersonas)
+	h.config.Unlock()
+	if err != nil {
+		h.respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = config.SaveConfig(h.config, config.GetConfigPath())
+	if err != nil {
+		h.logger.Error("Failed to save config", "error", err)
+		h.respondWithError(w, http.StatusInternalServerError, "Failed to save configuration")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, map[string]string{"message": "Personas updated successfully"})
+}
@@ -13,8 +13,12 @@ import (
 	"github.com/vibin/chat-bot/internal/core/ports"
 	"github.com/vibin/chat-bot/internal/core/services"
 	"github.com/vibin/chat-bot/internal/logger"
+	"github.com/vibin/chat-bot/internal/metrics"
 )
 
+// requestTimeout bounds every request except reply generation
+const requestTimeout = 60 * time.Second
+
 // Handler is the HTTP handler for the chat application
 type Handler struct {
 	service *services.ChatService
@@ -22,6 +26,12 @@ type Handler struct {
 	router  *chi.Mux
 	config  *config.Config
 	whatsappAdapter ports.WhatsAppPort
+	scheduler *services.Scheduler // Optional, set with SetScheduler
+	rules     *services.ResponseRuleService // Optional, set with SetResponseRules
+	hooks     *services.HookService // Optional, set with SetHookService
+	auth      *services.AuthService // Optional, set with SetAuthService, admin routes are open without it
+	health    *services.HealthService // Optional, set with SetHealthService
+	models    *services.ModelService // Optional, set with SetModelService
 }
 
 // NewHandler creates a new HTTP handler
@@ -37,60 +47,132 @@ func NewHandler(service *services.ChatService, cfg *config.Config, whatsappAdapt
 	return h
 }
 
+// SetScheduler enables the scheduled job admin endpoints
+func (h *Handler) SetScheduler(scheduler *services.Scheduler) {
+	h.scheduler = scheduler
+}
+
+// SetResponseRules enables the response rule admin endpoints
+func (h *Handler) SetResponseRules(rules *services.ResponseRuleService) {
+	h.rules = rules
+}
+
+// SetHookService enables the inbound webhook endpoint
+func (h *Handler) SetHookService(hooks *services.HookService) {
+	h.hooks = hooks
+}
+
+// SetAuthService requires a login or API token for the admin pages and API
+func (h *Handler) SetAuthService(auth *services.AuthService) {
+	h.auth = auth
+}
+
+// SetHealthService enables the dependency probes of the health and readiness endpoints
+func (h *Handler) SetHealthService(health *services.HealthService) {
+	h.health = health
+}
+
 // setupRouter sets up the Chi router with middleware and routes
 func (h *Handler) setupRouter() {
 	r := chi.NewRouter()
 	
 	// Middleware
 	r.Use(middleware.RequestID)
+	r.Use(rememberPeerAddr)
 	r.Use(middleware.RealIP)
 	r.Use(LoggerMiddleware(h.logger))
 	r.Use(middleware.Recoverer)
-	r.Use(middleware.Timeout(60 * time.Second))
 	
 	// CORS middleware
+	// With auth enabled only the configured origins may make credentialed cross-site calls
+	allowedOrigins := []string{"*"}
+	if h.config.Auth.Enabled {
+		allowedOrigins = h.config.Auth.AllowedOrigins
+	}
 	r.Use(cors.Handler(cors.Options{
-		AllowedOrigins:   []string{"*"},
+		AllowOriginFunc: func(r *http.Request, origin string) bool {
+			for _, allowed := range allowedOrigins {
+				if allowed == "*" || allowed == origin {
+					return true
+				}
+			}
+			return false
+		},
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Content-Type", "X-CSRF-Token"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
 		ExposedHeaders:   []string{"Link"},
 		AllowCredentials: true,
 		MaxAge:           300,
 	}))
 	
-	// Static files
-	r.Handle("/static/*", http.StripPrefix("/static/", http.FileServer(http.Dir("./web/static"))))
-	
-	// API routes
-	r.Route("/api", func(r chi.Router) {
-		r.Route("/chats", func(r chi.Router) {
-			r.Get("/", h.ListChats)
-			r.Post("/", h.CreateChat)
-			r.Route("/{chatID}", func(r chi.Router) {
-				r.Get("/", h.GetChat)
-				r.Post("/messages", h.SendMessage)
-				r.Delete("/", h.DeleteChat)
+	// Generating a reply can take longer than requestTimeout and the server's write timeout,
+	// it is bounded by the LLM timeouts instead
+	r.With(h.withoutWriteDeadline).Post("/api/chats/{chatID}/messages", h.SendMessage)
+	r.With(h.withoutWriteDeadline).Post("/api/chats/{chatID}/messages/stream", h.StreamMessage)
+	r.With(h.withoutWriteDeadline).Post("/v1/chat/completions", h.ChatCompletions)
+	
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.Timeout(requestTimeout))
+		
+		// Static files
+		r.Handle("/static/*", http.StripPrefix("/static/", http.FileServer(http.Dir("./web/static"))))
+	
+		// API routes
+		r.Route("/api", func(r chi.Router) {
+			r.Route("/chats", func(r chi.Router) {
+				r.Get("/", h.ListChats)
+				r.Post("/", h.CreateChat)
+				r.Route("/{chatID}", func(r chi.Router) {
+					r.Get("/", h.GetChat)
+					r.Delete("/", h.DeleteChat)
+				})
 			})
-		})
 		
-		r.Get("/model", h.GetModelInfo)
+			r.Get("/model", h.GetModelInfo)
 		
-		// WhatsApp admin routes
-		if h.config.WhatsApp.Enabled && h.whatsappAdapter != nil {
-			h.setupWhatsAppAdminRoutes(r)
-		}
-	})
+			// Model management, the routes answer 503 until a model service is set
+			h.setupModelRoutes(r)
+		
+			// Admin login
+			h.setupAuthRoutes(r)
+		
+			// Inbound webhooks that post into WhatsApp groups, authenticated per hook
+			r.Post("/hooks/{hookID}", h.handleHook)
+		
+			// WhatsApp admin routes
+			if h.config.WhatsApp.Enabled && h.whatsappAdapter != nil {
+				h.setupWhatsAppAdminRoutes(r)
+			}
+		})
 	
-	// Web UI routes
-	r.Get("/", h.HomePage)
-	r.Get("/chat/{chatID}", h.ChatPage)
+		// Health and readiness probes, open so orchestrators can call them
+		r.Get("/healthz", h.handleHealthz)
+		r.Get("/readyz", h.handleReadyz)
 	
-	// WhatsApp admin UI
-	if h.config.WhatsApp.Enabled {
-		r.Get("/admin/whatsapp", h.WhatsAppAdminPage)
-		r.Get("/admin/memory", h.MemoryAdminPage)
-		r.Get("/admin/bot", h.BotAdminPage)
-	}
+		// Prometheus metrics, scrapers authenticate with a viewer API token when admin login is enabled
+		r.With(h.requireAuth).Handle("/metrics", metrics.Handler())
+	
+		// OpenAI-compatible API routes
+		r.Route("/v1", func(r chi.Router) {
+			r.Get("/models", h.ListModels)
+		})
+	
+		// Web UI routes
+		r.Get("/", h.HomePage)
+		r.Get("/chat/{chatID}", h.ChatPage)
+	
+		r.Get("/login", h.LoginPage)
+	
+		// WhatsApp admin UI
+		if h.config.WhatsApp.Enabled {
+			r.Group(func(r chi.Router) {
+				r.Use(h.requireAuth)
+				r.Get("/admin/whatsapp", h.WhatsAppAdminPage)
+				r.Get("/admin/memory", h.MemoryAdminPage)
+				r.Get("/admin/bot", h.BotAdminPage)
+			})
+		}
+	})
 	
 	h.router = r
 }
@@ -219,6 +301,16 @@ func (h *Handler) respondWithJSON(w http.ResponseWriter, code int, payload inter
 	w.Write(response)
 }
 
+// withoutWriteDeadline lifts the server's write timeout for a request, so slow or streamed replies are not cut off
+func (h *Handler) withoutWriteDeadline(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := http.NewResponseController(w).SetWriteDeadline(time.Time{}); err != nil {
+			h.logger.Warn("Failed to lift the write deadline", "path", r.URL.Path, "error", err)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
 // LoggerMiddleware is a middleware that logs HTTP requests
 func LoggerMiddleware(log logger.Logger) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
@@ -0,0 +1,38 @@
+package http
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/vibin/chat-bot/internal/core/domain"
+)
+
+// handleHealthz reports the status of every dependency
+// It answers 200 while the server runs, so a failing dependency does not get the process restarted
+func (h *Handler) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	h.respondWithJSON(w, http.StatusOK, h.healthReport(r))
+}
+
+// handleReadyz reports the status of every dependency
+// It answers 503 while a critical dependency is down, so no traffic is sent until it is back
+func (h *Handler) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	report := h.healthReport(r)
+	status := http.StatusOK
+	if !report.Ready {
+		status = http.StatusServiceUnavailable
+	}
+	h.respondWithJSON(w, status, report)
+}
+
+// healthReport probes the dependencies, or reports the server alone when no health service is set
+func (h *Handler) healthReport(r *http.Request) domain.HealthReport {
+	if h.health == nil {
+		return domain.HealthReport{
+			Status:     domain.HealthUp,
+			Ready:      true,
+			CheckedAt:  time.Now(),
+			Components: []domain.ComponentHealth{},
+		}
+	}
+	return h.health.Check(r.Context())
+}
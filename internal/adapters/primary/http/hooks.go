@@ -0,0 +1,63 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/vibin/chat-bot/internal/core/services"
+)
+
+// maxHookPayload limits the size of an inbound webhook body
+const maxHookPayload = 64 << 10
+
+// handleHook posts the message of an inbound webhook into its WhatsApp group
+// The body is a JSON object used by the hook's template, or plain text posted as the message
+func (h *Handler) handleHook(w http.ResponseWriter, r *http.Request) {
+	if h.hooks == nil {
+		h.respondWithError(w, http.StatusServiceUnavailable, "Hooks are not enabled")
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxHookPayload))
+	if err != nil {
+		h.respondWithError(w, http.StatusBadRequest, "Failed to read request body")
+		return
+	}
+
+	var payload map[string]interface{}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		payload = map[string]interface{}{"message": string(body)}
+	}
+
+	message, err := h.hooks.Deliver(r.Context(), chi.URLParam(r, "hookID"), hookToken(r), payload)
+	switch {
+	case errors.Is(err, services.ErrHookNotFound):
+		h.respondWithError(w, http.StatusNotFound, "Hook not found")
+	case errors.Is(err, services.ErrHookUnauthorized):
+		h.respondWithError(w, http.StatusUnauthorized, "Invalid hook token")
+	case errors.Is(err, services.ErrHookRateLimited):
+		h.respondWithError(w, http.StatusTooManyRequests, "Rate limit exceeded")
+	case errors.Is(err, services.ErrHookBadPayload):
+		h.respondWithError(w, http.StatusBadRequest, err.Error())
+	case err != nil:
+		h.logger.Error("Failed to deliver hook message", "hook_id", chi.URLParam(r, "hookID"), "error", err)
+		h.respondWithError(w, http.StatusBadGateway, "Failed to deliver message")
+	default:
+		h.respondWithJSON(w, http.StatusOK, map[string]interface{}{
+			"success": true,
+			"message": message,
+		})
+	}
+}
+
+// hookToken returns the token of a hook request, from a bearer token or the X-Hook-Token header
+func hookToken(r *http.Request) string {
+	if token := r.Header.Get("X-Hook-Token"); token != "" {
+		return token
+	}
+	return strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+}
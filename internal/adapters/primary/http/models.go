@@ -0,0 +1,203 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/vibin/chat-bot/config"
+	"github.com/vibin/chat-bot/internal/core/domain"
+	"github.com/vibin/chat-bot/internal/core/services"
+)
+
+// ModelRequest represents a request naming a model to pull or unload
+type ModelRequest struct {
+	Name string `json:"name"`
+}
+
+// SwitchModelRequest represents a request to change the model a role uses
+type SwitchModelRequest struct {
+	Role  domain.ModelRole `json:"role"` // "main", "secondary" or "image"
+	Model string           `json:"model"`
+}
+
+// ModelListResponse represents the installed models and the model each role uses
+type ModelListResponse struct {
+	Models []domain.ModelDetails       `json:"models"`
+	Active map[domain.ModelRole]string `json:"active"`
+}
+
+// SetModelService enables the model management endpoints
+func (h *Handler) SetModelService(models *services.ModelService) {
+	h.models = models
+}
+
+// setupModelRoutes sets up routes for listing, pulling, unloading and switching models
+func (h *Handler) setupModelRoutes(r chi.Router) {
+	r.Route("/models", func(r chi.Router) {
+		r.Use(h.requireAuth)
+		r.Get("/", h.handleListModels)
+		r.Get("/show", h.handleShowModel)
+		r.Post("/pull", h.handlePullModel)
+		r.Get("/pulls", h.handleListPulls)
+		r.Post("/unload", h.handleUnloadModel)
+		r.Get("/active", h.handleActiveModels)
+		r.Put("/active", h.handleSwitchModel)
+	})
+}
+
+// handleListModels returns the models installed on the LLM server and what the bot uses each for
+func (h *Handler) handleListModels(w http.ResponseWriter, r *http.Request) {
+	if h.models == nil {
+		h.respondWithError(w, http.StatusServiceUnavailable, "Model management is not enabled")
+		return
+	}
+
+	models, err := h.models.ListModels(r.Context())
+	if err != nil {
+		h.respondWithModelError(w, err)
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, ModelListResponse{Models: models, Active: h.models.ActiveModels()})
+}
+
+// handleShowModel returns the details, context length and capabilities of the model named in the query
+func (h *Handler) handleShowModel(w http.ResponseWriter, r *http.Request) {
+	if h.models == nil {
+		h.respondWithError(w, http.StatusServiceUnavailable, "Model management is not enabled")
+		return
+	}
+
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		h.respondWithError(w, http.StatusBadRequest, "Name is required")
+		return
+	}
+
+	model, err := h.models.ShowModel(r.Context(), name)
+	if err != nil {
+		h.respondWithModelError(w, err)
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, model)
+}
+
+// handlePullModel starts downloading a model, its progress is served by handleListPulls
+func (h *Handler) handlePullModel(w http.ResponseWriter, r *http.Request) {
+	if h.models == nil {
+		h.respondWithError(w, http.StatusServiceUnavailable, "Model management is not enabled")
+		return
+	}
+
+	var request ModelRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	if request.Name == "" {
+		h.respondWithError(w, http.StatusBadRequest, "Name is required")
+		return
+	}
+
+	pull, err := h.models.Pull(request.Name)
+	if err != nil {
+		h.respondWithModelError(w, err)
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusAccepted, pull)
+}
+
+// handleListPulls returns the progress of model downloads
+func (h *Handler) handleListPulls(w http.ResponseWriter, r *http.Request) {
+	if h.models == nil {
+		h.respondWithError(w, http.StatusServiceUnavailable, "Model management is not enabled")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, h.models.Pulls())
+}
+
+// handleUnloadModel frees the memory a model uses on the LLM server
+func (h *Handler) handleUnloadModel(w http.ResponseWriter, r *http.Request) {
+	if h.models == nil {
+		h.respondWithError(w, http.StatusServiceUnavailable, "Model management is not enabled")
+		return
+	}
+
+	var request ModelRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	if request.Name == "" {
+		h.respondWithError(w, http.StatusBadRequest, "Name is required")
+		return
+	}
+
+	if err := h.models.Unload(r.Context(), request.Name); err != nil {
+		h.respondWithModelError(w, err)
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, map[string]string{"message": "Model unloaded"})
+}
+
+// handleActiveModels returns the model each role uses
+func (h *Handler) handleActiveModels(w http.ResponseWriter, r *http.Request) {
+	if h.models == nil {
+		h.respondWithError(w, http.StatusServiceUnavailable, "Model management is not enabled")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, h.models.ActiveModels())
+}
+
+// handleSwitchModel changes the model a role uses without a restart and saves it to the configuration
+func (h *Handler) handleSwitchModel(w http.ResponseWriter, r *http.Request) {
+	if h.models == nil {
+		h.respondWithError(w, http.StatusServiceUnavailable, "Model management is not enabled")
+		return
+	}
+
+	var request SwitchModelRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	if request.Role == "" || request.Model == "" {
+		h.respondWithError(w, http.StatusBadRequest, "Role and model are required")
+		return
+	}
+
+	if err := h.models.Switch(r.Context(), request.Role, request.Model); err != nil {
+		h.respondWithModelError(w, err)
+		return
+	}
+
+	if err := config.SaveConfig(h.config, config.GetConfigPath()); err != nil {
+		h.logger.Error("Failed to save config", "error", err)
+		h.respondWithError(w, http.StatusInternalServerError, "Model switched but the configuration could not be saved")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, h.models.ActiveModels())
+}
+
+// respondWithModelError maps model service errors to HTTP status codes
+func (h *Handler) respondWithModelError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrModelManagementUnsupported), errors.Is(err, services.ErrModelSwitchUnsupported):
+		h.respondWithError(w, http.StatusNotImplemented, err.Error())
+	case errors.Is(err, services.ErrUnknownModelRole), errors.Is(err, services.ErrModelNotFound):
+		h.respondWithError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrPullInProgress):
+		h.respondWithError(w, http.StatusConflict, err.Error())
+	default:
+		h.logger.Error("Model request failed", "error", err)
+		h.respondWithError(w, http.StatusBadGateway, err.Error())
+	}
+}
@@ -0,0 +1,257 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/vibin/chat-bot/internal/core/domain"
+)
+
+// OpenAIChatRequest represents an OpenAI /v1/chat/completions request
+type OpenAIChatRequest struct {
+	Model    string              `json:"model"`
+	Messages []OpenAIChatMessage `json:"messages"`
+	Stream   bool                `json:"stream"`
+}
+
+// OpenAIChatMessage represents a single message in an OpenAI request
+// Content is either a plain string or a list of text and image_url parts
+type OpenAIChatMessage struct {
+	Role    string          `json:"role"`
+	Content json.RawMessage `json:"content"`
+}
+
+// openAIContentPart is a single part of a multi-part message
+type openAIContentPart struct {
+	Type     string `json:"type"`
+	Text     string `json:"text"`
+	ImageURL struct {
+		URL string `json:"url"`
+	} `json:"image_url"`
+}
+
+// openAIChoice is a single choice in a completion or completion chunk
+type openAIChoice struct {
+	Index        int                `json:"index"`
+	Message      *openAIResponseMsg `json:"message,omitempty"`
+	Delta        *openAIResponseMsg `json:"delta,omitempty"`
+	FinishReason *string            `json:"finish_reason"`
+}
+
+// openAIResponseMsg is the assistant message returned to the client
+type openAIResponseMsg struct {
+	Role    string `json:"role,omitempty"`
+	Content string `json:"content"`
+}
+
+// openAICompletion is a completion or completion chunk returned to the client
+type openAICompletion struct {
+	ID      string         `json:"id"`
+	Object  string         `json:"object"`
+	Created int64          `json:"created"`
+	Model   string         `json:"model"`
+	Choices []openAIChoice `json:"choices"`
+}
+
+// ChatCompletions handles OpenAI-compatible chat completion requests using the chat service
+func (h *Handler) ChatCompletions(w http.ResponseWriter, r *http.Request) {
+	var req OpenAIChatRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondWithOpenAIError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if len(req.Messages) == 0 {
+		h.respondWithOpenAIError(w, http.StatusBadRequest, "messages must not be empty")
+		return
+	}
+
+	messages, err := convertOpenAIMessages(req.Messages)
+	if err != nil {
+		h.respondWithOpenAIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	completion := openAICompletion{
+		ID:      "chatcmpl-" + uuid.New().String(),
+		Created: time.Now().Unix(),
+		Model:   h.service.GetModelName(),
+	}
+
+	if req.Stream {
+		h.streamChatCompletion(w, r, completion, messages)
+		return
+	}
+
+	var response string
+	last := messages[len(messages)-1]
+	if len(last.Images) > 0 {
+		// Images go through the dedicated image analysis model
+		response, err = h.service.CompletionWithImageAnalysis(r.Context(), last)
+	} else {
+		response, err = h.service.GenerateCompletion(r.Context(), messages)
+	}
+	if err != nil {
+		h.logger.Error("Failed to generate completion", "error", err)
+		h.respondWithOpenAIError(w, http.StatusInternalServerError, "Failed to generate completion")
+		return
+	}
+
+	stop := "stop"
+	completion.Object = "chat.completion"
+	completion.Choices = []openAIChoice{{
+		Index:        0,
+		Message:      &openAIResponseMsg{Role: "assistant", Content: response},
+		FinishReason: &stop,
+	}}
+
+	h.respondWithJSON(w, http.StatusOK, completion)
+}
+
+// streamChatCompletion streams a completion as OpenAI chat.completion.chunk events
+func (h *Handler) streamChatCompletion(w http.ResponseWriter, r *http.Request, completion openAICompletion, messages []domain.Message) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		h.respondWithOpenAIError(w, http.StatusInternalServerError, "Streaming not supported")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	completion.Object = "chat.completion.chunk"
+	writeChunk := func(delta openAIResponseMsg, finishReason *string) {
+		completion.Choices = []openAIChoice{{Index: 0, Delta: &delta, FinishReason: finishReason}}
+		h.writeSSEData(w, flusher, completion)
+	}
+
+	// The first chunk announces the assistant role
+	writeChunk(openAIResponseMsg{Role: "assistant"}, nil)
+
+	var err error
+	last := messages[len(messages)-1]
+	if len(last.Images) > 0 {
+		// Image analysis is not streamed, send the whole answer as one chunk
+		var response string
+		response, err = h.service.CompletionWithImageAnalysis(r.Context(), last)
+		if err == nil {
+			writeChunk(openAIResponseMsg{Content: response}, nil)
+		}
+	} else {
+		_, err = h.service.GenerateCompletionStream(r.Context(), messages, func(token string) {
+			writeChunk(openAIResponseMsg{Content: token}, nil)
+		})
+	}
+	if err != nil {
+		h.logger.Error("Failed to stream completion", "error", err)
+		h.writeSSEData(w, flusher, map[string]interface{}{
+			"error": map[string]string{"message": "Failed to generate completion", "type": "server_error"},
+		})
+		return
+	}
+
+	stop := "stop"
+	writeChunk(openAIResponseMsg{}, &stop)
+
+	fmt.Fprint(w, "data: [DONE]\n\n")
+	flusher.Flush()
+}
+
+// ListModels handles the OpenAI-compatible model list request
+func (h *Handler) ListModels(w http.ResponseWriter, r *http.Request) {
+	h.respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"object": "list",
+		"data": []map[string]interface{}{
+			{
+				"id":       h.service.GetModelName(),
+				"object":   "model",
+				"created":  time.Now().Unix(),
+				"owned_by": "chat-bot",
+			},
+		},
+	})
+}
+
+// convertOpenAIMessages maps OpenAI request messages onto domain messages
+func convertOpenAIMessages(openAIMessages []OpenAIChatMessage) ([]domain.Message, error) {
+	messages := make([]domain.Message, 0, len(openAIMessages))
+
+	for i, msg := range openAIMessages {
+		message := domain.NewMessage(msg.Role, "")
+
+		// Plain string content
+		var text string
+		if err := json.Unmarshal(msg.Content, &text); err == nil {
+			message.Content = text
+			messages = append(messages, message)
+			continue
+		}
+
+		// Multi-part content with text and images
+		var parts []openAIContentPart
+		if err := json.Unmarshal(msg.Content, &parts); err != nil {
+			return nil, fmt.Errorf("invalid content in message %d", i)
+		}
+
+		var texts []string
+		for _, part := range parts {
+			switch part.Type {
+			case "text":
+				texts = append(texts, part.Text)
+			case "image_url":
+				message.Images = append(message.Images, stripDataURLPrefix(part.ImageURL.URL))
+			}
+		}
+
+		message.Content = strings.Join(texts, "\n")
+		if len(message.Images) > 0 {
+			message.Type = domain.MessageTypeImageAnalysis
+		}
+		messages = append(messages, message)
+	}
+
+	return messages, nil
+}
+
+// stripDataURLPrefix returns the base64 payload of a data URL
+func stripDataURLPrefix(url string) string {
+	if strings.HasPrefix(url, "data:") {
+		if idx := strings.Index(url, ","); idx != -1 {
+			return url[idx+1:]
+		}
+	}
+	return url
+}
+
+// writeSSEData writes an unnamed Server-Sent Event with a JSON payload and flushes it
+func (h *Handler) writeSSEData(w http.ResponseWriter, flusher http.Flusher, payload interface{}) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		h.logger.Error("Failed to marshal SSE payload", "error", err)
+		return
+	}
+
+	fmt.Fprintf(w, "data: %s\n\n", data)
+	flusher.Flush()
+}
+
+// respondWithOpenAIError sends an error response in the OpenAI error format
+func (h *Handler) respondWithOpenAIError(w http.ResponseWriter, code int, message string) {
+	errorType := "invalid_request_error"
+	if code >= http.StatusInternalServerError {
+		errorType = "server_error"
+	}
+
+	h.respondWithJSON(w, code, map[string]interface{}{
+		"error": map[string]string{
+			"message": message,
+			"type":    errorType,
+		},
+	})
+}
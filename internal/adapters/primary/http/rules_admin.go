@@ -0,0 +1,130 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/vibin/chat-bot/internal/core/domain"
+	"github.com/vibin/chat-bot/internal/core/services"
+)
+
+// TestRuleRequest represents a message to check against the response rules without sending anything
+type TestRuleRequest struct {
+	Message string `json:"message"`
+	GroupID string `json:"group_id"` // Selects the group scope and persona, optional
+	Sender  string `json:"sender"`
+}
+
+// TestRuleResponse represents the outcome of a dry run
+type TestRuleResponse struct {
+	Matched  bool   `json:"matched"`
+	RuleID   string `json:"rule_id,omitempty"`
+	Response string `json:"response,omitempty"`
+}
+
+// setupResponseRuleRoutes sets up routes for managing response rules
+func (h *Handler) setupResponseRuleRoutes(r chi.Router) {
+	r.Route("/rules", func(r chi.Router) {
+		r.Get("/", h.handleListRules)
+		r.Post("/", h.handleSaveRule)
+		r.Post("/test", h.handleTestRule)
+		r.Put("/{ruleID}", h.handleSaveRule)
+		r.Delete("/{ruleID}", h.handleDeleteRule)
+	})
+}
+
+// handleListRules returns the response rules in the order they are checked
+func (h *Handler) handleListRules(w http.ResponseWriter, r *http.Request) {
+	if h.rules == nil {
+		h.respondWithError(w, http.StatusServiceUnavailable, "Response rules are not enabled")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, h.rules.Rules())
+}
+
+// handleSaveRule creates a rule, or replaces the rule with the same ID
+func (h *Handler) handleSaveRule(w http.ResponseWriter, r *http.Request) {
+	if h.rules == nil {
+		h.respondWithError(w, http.StatusServiceUnavailable, "Response rules are not enabled")
+		return
+	}
+
+	var rule domain.ResponseRule
+	if err := json.NewDecoder(r.Body).Decode(&rule); err != nil {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	if ruleID := chi.URLParam(r, "ruleID"); ruleID != "" {
+		rule.ID = ruleID
+	}
+
+	if err := h.rules.SaveRule(rule); err != nil {
+		h.respondWithError(w, http.StatusBadRequest, "Failed to save rule: "+err.Error())
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, rule)
+}
+
+// handleDeleteRule deletes a rule
+func (h *Handler) handleDeleteRule(w http.ResponseWriter, r *http.Request) {
+	if h.rules == nil {
+		h.respondWithError(w, http.StatusServiceUnavailable, "Response rules are not enabled")
+		return
+	}
+
+	if err := h.rules.DeleteRule(chi.URLParam(r, "ruleID")); err != nil {
+		h.respondWithError(w, http.StatusNotFound, "Rule not found")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, map[string]string{"message": "Rule deleted successfully"})
+}
+
+// handleTestRule reports which rule would answer a message and what it would reply
+func (h *Handler) handleTestRule(w http.ResponseWriter, r *http.Request) {
+	if h.rules == nil {
+		h.respondWithError(w, http.StatusServiceUnavailable, "Response rules are not enabled")
+		return
+	}
+
+	var request TestRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	if request.Message == "" {
+		h.respondWithError(w, http.StatusBadRequest, "Message is required")
+		return
+	}
+
+	// Personas are replaced at runtime under the configuration lock
+	h.config.RLock()
+	personaKey := h.config.WhatsApp.PersonaKey(request.GroupID)
+	persona := h.config.WhatsApp.PersonaFor(request.GroupID)
+	h.config.RUnlock()
+
+	match, err := h.rules.Match(r.Context(), services.RuleInput{
+		Message:    request.Message,
+		ChatJID:    request.GroupID,
+		Sender:     request.Sender,
+		PersonaKey: personaKey,
+		Persona:    persona,
+	})
+	if err != nil {
+		h.respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if match == nil {
+		h.respondWithJSON(w, http.StatusOK, TestRuleResponse{Matched: false})
+		return
+	}
+	h.respondWithJSON(w, http.StatusOK, TestRuleResponse{
+		Matched:  true,
+		RuleID:   match.RuleID,
+		Response: match.Response,
+	})
+}
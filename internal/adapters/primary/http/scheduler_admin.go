@@ -0,0 +1,98 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/vibin/chat-bot/internal/core/domain"
+)
+
+// CreateJobRequest represents the request structure for scheduling a message
+// Either Cron or RunAt must be set, or Text to have the LLM work out the schedule
+type CreateJobRequest struct {
+	GroupID string    `json:"group_id"`
+	Message string    `json:"message"`
+	Cron    string    `json:"cron"`
+	RunAt   time.Time `json:"run_at"`
+	Text    string    `json:"text"` // Natural language such as "every Monday at 9 remind us to put the bins out"
+}
+
+// setupSchedulerRoutes sets up routes for managing scheduled jobs
+func (h *Handler) setupSchedulerRoutes(r chi.Router) {
+	r.Route("/jobs", func(r chi.Router) {
+		r.Get("/", h.handleListJobs)
+		r.Post("/", h.handleCreateJob)
+		r.Delete("/{jobID}", h.handleDeleteJob)
+	})
+}
+
+// handleListJobs returns the scheduled jobs, optionally filtered by group_id
+func (h *Handler) handleListJobs(w http.ResponseWriter, r *http.Request) {
+	if h.scheduler == nil {
+		h.respondWithError(w, http.StatusServiceUnavailable, "Scheduler is not enabled")
+		return
+	}
+
+	jobs, err := h.scheduler.ListJobs(r.Context(), r.URL.Query().Get("group_id"))
+	if err != nil {
+		h.logger.Error("Failed to list jobs", "error", err)
+		h.respondWithError(w, http.StatusInternalServerError, "Failed to list jobs")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, jobs)
+}
+
+// handleCreateJob schedules a message for a group
+func (h *Handler) handleCreateJob(w http.ResponseWriter, r *http.Request) {
+	if h.scheduler == nil {
+		h.respondWithError(w, http.StatusServiceUnavailable, "Scheduler is not enabled")
+		return
+	}
+
+	var request CreateJobRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if request.GroupID == "" {
+		h.respondWithError(w, http.StatusBadRequest, "Group ID is required")
+		return
+	}
+
+	var err error
+	var job *domain.ScheduledJob
+	if request.Text != "" {
+		job, err = h.scheduler.ScheduleFromText(r.Context(), request.GroupID, request.Text, "admin")
+	} else {
+		if request.Cron == "" && request.RunAt.IsZero() {
+			h.respondWithError(w, http.StatusBadRequest, "Either cron or run_at is required")
+			return
+		}
+		job, err = h.scheduler.CreateJob(r.Context(), request.GroupID, request.Message, request.Cron, request.RunAt, "admin")
+	}
+	if err != nil {
+		h.respondWithError(w, http.StatusBadRequest, "Failed to schedule message: "+err.Error())
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusCreated, job)
+}
+
+// handleDeleteJob cancels a scheduled job
+func (h *Handler) handleDeleteJob(w http.ResponseWriter, r *http.Request) {
+	if h.scheduler == nil {
+		h.respondWithError(w, http.StatusServiceUnavailable, "Scheduler is not enabled")
+		return
+	}
+
+	if err := h.scheduler.CancelJob(r.Context(), "", chi.URLParam(r, "jobID")); err != nil {
+		h.respondWithError(w, http.StatusNotFound, "Job not found")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, map[string]string{"message": "Job cancelled successfully"})
+}
@@ -0,0 +1,64 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// StreamMessage handles the send message request and streams the response as Server-Sent Events
+// Each token is sent as a "token" event, followed by a "done" event carrying the saved chat
+func (h *Handler) StreamMessage(w http.ResponseWriter, r *http.Request) {
+	chatID := chi.URLParam(r, "chatID")
+	
+	var req struct {
+		Content string `json:"content"`
+	}
+	
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	
+	// Make sure the chat exists before switching to an event stream
+	if _, err := h.service.GetChat(r.Context(), chatID); err != nil {
+		h.respondWithError(w, http.StatusNotFound, "Chat not found")
+		return
+	}
+	
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		h.respondWithError(w, http.StatusInternalServerError, "Streaming not supported")
+		return
+	}
+	
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	
+	chat, err := h.service.SendMessageStream(r.Context(), chatID, req.Content, func(token string) {
+		h.writeSSEEvent(w, flusher, "token", map[string]string{"content": token})
+	})
+	if err != nil {
+		h.writeSSEEvent(w, flusher, "error", map[string]string{"error": "Failed to send message"})
+		return
+	}
+	
+	h.writeSSEEvent(w, flusher, "done", chat)
+}
+
+// writeSSEEvent writes a single Server-Sent Event with a JSON payload and flushes it
+func (h *Handler) writeSSEEvent(w http.ResponseWriter, flusher http.Flusher, event string, payload interface{}) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		h.logger.Error("Failed to marshal SSE payload", "event", event, "error", err)
+		return
+	}
+	
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
+	flusher.Flush()
+}
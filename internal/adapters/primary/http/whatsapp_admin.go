@@ -14,6 +14,9 @@ func (h *Handler) setupWhatsAppAdminRoutes(r chi.Router) {
 
 	// Register routes
 	r.Route("/whatsapp", func(r chi.Router) {
+		// Every admin route needs a login or API token when auth is enabled
+		r.Use(h.requireAuth)
+		
 		// Group list and management
 		r.Get("/groups", h.handleGetGroups)
 		r.Post("/groups", h.handleUpdateGroups)
@@ -24,6 +27,16 @@ func (h *Handler) setupWhatsAppAdminRoutes(r chi.Router) {
 		// Bot messaging
 		r.Post("/send", h.handleSendBotMessage)
 		
+		// Persona profiles
+		r.Get("/personas", h.handleGetPersonas)
+		r.Post("/personas", h.handleUpdatePersonas)
+		
+		// Scheduled jobs
+		h.setupSchedulerRoutes(r)
+		
+		// Response rules
+		h.setupResponseRuleRoutes(r)
+		
 		// Memory management endpoints
 		r.Route("/memory", func(r chi.Router) {
 			r.Get("/all", h.handleGetAllMemories)
@@ -81,16 +94,19 @@ func (h *Handler) handleUpdateGroups(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	
-	// Update allowed groups in adapter
+	// Update allowed groups in adapter and config
+	// The configuration is locked so a concurrent save doesn't read the list while it is replaced
+	h.config.Lock()
 	err = h.whatsappAdapter.UpdateAllowedGroups(requestData.AllowedGroups)
+	if err == nil {
+		h.config.WhatsApp.AllowedGroups = requestData.AllowedGroups
+	}
+	h.config.Unlock()
 	if err != nil {
 		h.respondWithError(w, http.StatusInternalServerError, "Failed to update allowed groups")
 		return
 	}
 	
-	// Update config
-	h.config.WhatsApp.AllowedGroups = requestData.AllowedGroups
-	
 	// Save config
 	err = config.SaveConfig(h.config, config.GetConfigPath())
 	if err != nil {
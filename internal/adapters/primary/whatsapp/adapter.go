@@ -10,8 +10,11 @@ import (
 
 	"github.com/mdp/qrterminal/v3"
 	"github.com/vibin/chat-bot/config"
+	"github.com/vibin/chat-bot/internal/core/domain"
+	"github.com/vibin/chat-bot/internal/core/ports"
 	"github.com/vibin/chat-bot/internal/core/services"
 	"github.com/vibin/chat-bot/internal/logger"
+	"github.com/vibin/chat-bot/internal/metrics"
 	"go.mau.fi/whatsmeow"
 	waProto "go.mau.fi/whatsmeow/binary/proto"
 	"go.mau.fi/whatsmeow/store"
@@ -38,9 +41,16 @@ type WhatsAppAdapter struct {
 	limiter      *rate.Limiter // Rate limiter for WhatsApp API calls
 	memoryManager *MemoryManager // Memory manager for context and memories
 	memoryService *services.MemoryService // Service for persistent memory storage
+	memoryExtractor *services.MemoryExtractor // Extracts memories from conversations
+	speech       ports.SpeechToTextPort // Transcribes voice notes, nil when disabled
+	documents    *services.DocumentService // Answers questions about shared documents, nil when disabled
+	messageLog   ports.MessageLogPort // Passive log of group messages for digests, nil when disabled
+	scheduler    *services.Scheduler // Posts reminders and recurring messages, nil when disabled
+	webhook      ports.WebhookPort // Calls the configured webhook services
 	formatter    *WhatsAppFormatter // Formatter for enhancing WhatsApp messages
-	responses    *PredefinedResponses // Handler for predefined responses
+	rules        *services.ResponseRuleService // Canned responses and guard rules, nil when disabled
 	processedMsgs sync.Map // Track processed message IDs to prevent duplicates
+	commands     *CommandRouter // Registered message commands
 }
 
 // Conversation represents an active conversation
@@ -75,8 +85,9 @@ func NewWhatsAppAdapter(chatService *services.ChatService, config *config.Config
 		limiter:      limiter,
 		memoryManager: NewMemoryManager(),
 		formatter:    NewWhatsAppFormatter(),
-		responses:    NewPredefinedResponses(),
+		commands:     NewCommandRouter(),
 	}
+	adapter.registerDefaultCommands()
 
 	return adapter, nil
 }
@@ -157,6 +168,17 @@ func (a *WhatsAppAdapter) IsConnected() bool {
 	return a.client != nil && a.client.IsConnected()
 }
 
+// Ping reports whether the client is connected and logged in to WhatsApp
+func (a *WhatsAppAdapter) Ping(ctx context.Context) error {
+	if !a.IsConnected() {
+		return fmt.Errorf("not connected")
+	}
+	if !a.client.IsLoggedIn() {
+		return fmt.Errorf("not logged in, scan the QR code")
+	}
+	return nil
+}
+
 // Start starts listening for messages
 func (a *WhatsAppAdapter) Start(ctx context.Context) error {
 	a.log.Info("WhatsApp adapter is starting")
@@ -171,15 +193,22 @@ func (a *WhatsAppAdapter) Start(ctx context.Context) error {
 	a.log.Info("Starting periodic memory synchronization")
 	memSyncTicker := time.NewTicker(5 * time.Minute)
 	
+	// Apply the message log retention limits every hour
+	pruneTicker := time.NewTicker(time.Hour)
+	
 	// Start memory sync goroutine
 	go func() {
+		a.pruneMessageLog()
 		for {
 			select {
 			case <-memSyncTicker.C:
 				a.syncAllMemories()
+			case <-pruneTicker.C:
+				a.pruneMessageLog()
 			case <-ctx.Done():
 				a.log.Info("Context done, stopping memory sync ticker")
 				memSyncTicker.Stop()
+				pruneTicker.Stop()
 				// Perform final sync on shutdown
 				a.syncAllMemories()
 				return
@@ -225,28 +254,61 @@ func (a *WhatsAppAdapter) handleMessage(evt *events.Message) {
 		}
 		a.log.Info("Processing new message", "message_id", messageID)
 	}
+	a.countReceived(evt)
 	
-	// Skip direct messages as requested
-	if !evt.Info.IsGroup {
+	// Direct messages are opt-in and limited to allowed contacts
+	isDirect := !evt.Info.IsGroup
+	if isDirect {
+		// Skip our own messages and contacts that are not allowed
+		if evt.Info.IsFromMe {
+			a.countIgnored(evt, ignoredFromMe)
+			return
+		}
+		if !a.isDirectMessageAllowed(evt) {
+			a.countIgnored(evt, ignoredNotAllowed)
+			return
+		}
+	} else if !a.isGroupAllowed(evt.Info.Chat.String()) {
+		// The group is not in the allowed groups list
+		a.countIgnored(evt, ignoredNotAllowed)
 		return
 	}
 
-	// Get group ID as string
-	groupJID := evt.Info.Chat.String()
+	// Shared documents are stored for questions before the caption is handled
+	if a.documents != nil && evt.Message.GetDocumentMessage() != nil {
+		a.countHandled(evt, commandDocument)
+		go a.handleDocument(evt)
+		return
+	}
 
-	// Check if this group is in allowed groups list
-	if !a.isGroupAllowed(groupJID) {
+	// Voice notes are transcribed first and then handled like text messages
+	if a.speech != nil && evt.Message.GetAudioMessage() != nil {
+		go a.handleVoiceNote(evt)
 		return
 	}
 
+	a.routeMessage(evt, a.getMessageText(evt))
+}
+
+// routeMessage checks a message's text for trigger words and dispatches it to a command or the chat model
+// Voice notes pass their transcript as the text
+func (a *WhatsAppAdapter) routeMessage(evt *events.Message, message string) {
+	messageID := evt.Info.ID
+	isDirect := !evt.Info.IsGroup
+
+	// Get group ID as string
+	groupJID := evt.Info.Chat.String()
+
 	// Get the conversation ID
 	conversationID := a.getOrCreateConversation(evt)
 	
 	// Check if this is a reply to our bot's message
 	isReplyToBot := a.isReplyToBot(evt)
 
-	// Get message content
-	message := a.getMessageText(evt)
+	// Keep every group message for digests, documents are logged when they are stored
+	if evt.Message.GetDocumentMessage() == nil {
+		a.logGroupMessage(evt, conversationID, message)
+	}
 	
 	// Check if it's an image message
 	hasImage := a.hasImage(evt)
@@ -262,22 +324,11 @@ func (a *WhatsAppAdapter) handleMessage(evt *events.Message) {
 	hasMessageText := message != ""
 	
 	// Check if it contains any of the trigger words
-	isMention := false
-	if hasMessageText {
-		for _, triggerWord := range a.config.TriggerWords {
-			if strings.Contains(strings.ToLower(message), strings.ToLower(triggerWord)) {
-				isMention = true
-				break
-			}
-		}
-		
-		// Fallback to the deprecated single trigger word if needed
-		if !isMention && a.config.TriggerWord != "" {
-			isMention = strings.Contains(strings.ToLower(message), strings.ToLower(a.config.TriggerWord))
-		}
-	}
+	// Every direct message is addressed to the bot, so no trigger word is needed
+	isMention := isDirect || a.mentionsBot(message)
 	
 	if !isMention && !isReplyToBot {
+		a.countIgnored(evt, ignoredNotAddressed)
 		return
 	}
 
@@ -289,117 +340,56 @@ func (a *WhatsAppAdapter) handleMessage(evt *events.Message) {
 		"is_reply", isReplyToBot, 
 		"is_mention", isMention)
 
-	// Check if this is a ComfyUI request (highest priority)
-	isComfyRequest := a.isComfyUIRequest(message)
-	a.log.Info("ComfyUI request check", 
-		"is_comfy_request", isComfyRequest, 
-		"has_image", hasImage, 
-		"has_message_text", hasMessageText,
-		"message", message)
-	
-	if hasMessageText && hasImage && isComfyRequest {
-		a.log.Info("Processing ComfyUI request with image", "group", groupJID, "message", message)
-		go a.processAndReplyWithComfyUI(conversationID, evt)
-		return
-	} else if hasMessageText && isComfyRequest {
-		a.log.Info("Received ComfyUI request but no image was attached", "group", groupJID, "message", message)
-		a.sendReply("Please attach an image to process with avarachan", evt)
-		return
+	// Clean the message by removing the trigger words if present
+	cleanMessage := message
+	if isMention {
+		cleanMessage = a.stripTriggerWords(message)
 	}
-	
-	// Check if this is an image generation request (second priority)
-	if hasMessageText && a.isImageGenerationRequest(message) {
-		a.log.Info("Processing image generation request", "group", groupJID, "message", message)
-		go a.processAndReplyWithImageGeneration(conversationID, evt)
-		return
+
+	// Dispatch to the first matching command in priority order
+	request := &CommandRequest{
+		ConversationID: conversationID,
+		Message:        message,
+		CleanMessage:   cleanMessage,
+		Event:          evt,
+		HasImage:       hasImage,
+		IsMention:      isMention,
+		IsReplyToBot:   isReplyToBot,
+		Persona:        a.persona(groupJID),
 	}
-	
-	// Check if this is a family request
-	if hasMessageText && a.isFamilyRequest(message) {
-		a.log.Info("Processing family request", "group", groupJID, "message", message)
-		go a.processAndReplyWithFamilyHandler(conversationID, message, evt)
+	if a.dispatchCommand(request) {
 		return
 	}
-	
-	// Note: food request check moved to after image generation for better priority order
 
-	// Next priority for image analysis if the message contains an image
-	if hasImage {
-		// Even without caption text, process images in replies to bot
-		if isReplyToBot {
-			a.log.Info("Processing image message (reply to bot)", "group", groupJID)
-			go a.processAndReplyWithImageAnalysis(conversationID, evt)
-			return
-		}
-		
-		// For images with captions, check if caption has trigger words
-		if hasMessageText && isMention {
-			a.log.Info("Processing image message with trigger word", "group", groupJID, "message", message)
-			go a.processAndReplyWithImageAnalysis(conversationID, evt)
-			return
-		}
-		
-		// For images without captions or trigger words, ignore
-		if !hasMessageText {
-			return
-		}
-	}
-	
-	// If it's just text without an image, make sure it has text
+	// Anything else needs text for the chat model
 	if !hasMessageText {
+		a.countIgnored(evt, ignoredNoText)
 		return
 	}
 
-	// Check special handlers first with the full message (before cleaning it)
-	// This is important because some special handlers need to detect keywords
-	
-	// Check if this is a food request
-	if hasMessageText && a.isFoodRequest(message) {
-		a.log.Info("Detected food request - processing", "message", message)
-		go a.processAndReplyWithFoodHandler(conversationID, message, evt)
-		return
-	}
-	
-	// Check if this is a web search request
-	if hasMessageText && a.isWebRequest(message) {
-		a.log.Info("Detected web search request - processing", "message", message)
-		go a.processAndReplyWithWebHandler(conversationID, message, evt)
-		return
-	}
-	
-	// Clean the message by removing the trigger word if present
-	cleanMessage := message
-	if isMention {
-		// Replace all trigger words
-		for _, triggerWord := range a.config.TriggerWords {
-			cleanMessage = strings.ReplaceAll(
-				strings.ToLower(cleanMessage), 
-				strings.ToLower(triggerWord), 
-				"",
-			)
-		}
-		
-		// Also handle the deprecated single trigger word
-		if a.config.TriggerWord != "" {
-			cleanMessage = strings.ReplaceAll(
-				strings.ToLower(cleanMessage), 
-				strings.ToLower(a.config.TriggerWord), 
-				"",
-			)
-		}
-	}
-	cleanMessage = strings.TrimSpace(cleanMessage)
-	
-	// Generate response asynchronously
-	go a.processAndReply(conversationID, cleanMessage, evt, isReplyToBot)
+	// Direct messages always get the conversation context and memories
+	a.countHandled(evt, commandChat)
+	go a.processAndReply(conversationID, cleanMessage, evt, isReplyToBot || isDirect)
 }
 
 // processAndReply processes a message and sends a reply
 func (a *WhatsAppAdapter) processAndReply(conversationID string, message string, evt *events.Message, isReplyToBot bool) {
 	// Extract user ID from the message event
 	userID := evt.Info.Sender.String()
-	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	
+	// Allow enough time for tool calls such as image generation
+	ctx, cancel := context.WithTimeout(context.Background(), 180*time.Second)
 	defer cancel()
+	
+	// Tools such as memory act on behalf of the sender
+	ctx = ports.WithToolScope(ctx, ports.ToolScope{UserID: userID})
+	
+	// Answer as the persona assigned to the chat
+	persona := a.persona(evt.Info.Chat.String())
+	ctx = withPersona(ctx, persona)
+	
+	// Wait for the LLM in line with the other chats
+	ctx = a.withQueueInfo(ctx, evt)
 
 	// Record in conversation history
 	a.recordMessage(conversationID, fmt.Sprintf("User: %s", message))
@@ -407,9 +397,8 @@ func (a *WhatsAppAdapter) processAndReply(conversationID string, message string,
 	// Always add to context for this conversation
 	a.memoryManager.AddContextMessage(userID, conversationID, fmt.Sprintf("User: %s", message))
 	
-	// Check if we have a predefined response for this message
-	if predefinedResponse, found := a.responses.CheckForPredefinedResponse(message); found {
-		a.log.Info("Using predefined response")
+	// Check if a response rule answers this message
+	if predefinedResponse, found := a.matchResponseRule(ctx, message, evt, persona); found {
 		
 		// Record the response in our conversation
 		a.recordMessage(conversationID, fmt.Sprintf("Bot: %s", predefinedResponse))
@@ -423,15 +412,10 @@ func (a *WhatsAppAdapter) processAndReply(conversationID string, message string,
 	}
 
 	// Create a chat if it doesn't exist
-	chat, err := a.chatService.GetChat(ctx, conversationID)
+	chat, err := a.getOrCreateChat(ctx, conversationID)
 	if err != nil {
-		// Create a new chat if it doesn't exist
-		chatName := fmt.Sprintf("WhatsApp: %s", conversationID)
-		chat, err = a.chatService.CreateChat(ctx, chatName)
-		if err != nil {
-			a.log.Error("Failed to create chat", "error", err)
-			return
-		}
+		a.log.Error("Failed to create chat", "error", err)
+		return
 	}
 	
 	// If this is a reply to our bot, enhance the message with context and memories
@@ -439,7 +423,7 @@ func (a *WhatsAppAdapter) processAndReply(conversationID string, message string,
 	if isReplyToBot {
 		// Get context and memories for this conversation
 		context := a.memoryManager.GetContext(userID, conversationID)
-		memories := a.memoryManager.GetMemories(userID, conversationID)
+		memories := a.getRelevantMemories(ctx, userID, conversationID, message)
 		
 		// Build enhanced message with context and memories
 		var contextStr strings.Builder
@@ -472,20 +456,12 @@ func (a *WhatsAppAdapter) processAndReply(conversationID string, message string,
 	updatedChat, err := a.chatService.SendMessage(ctx, chat.ID, enhancedMessage)
 	if err != nil {
 		a.log.Error("Failed to process message", "error", err)
+		a.replyIfBusy(err, evt)
 		return
 	}
 	
 	// Get the response (last message from the assistant)
-	var response string
-	if len(updatedChat.Messages) > 0 {
-		for i := len(updatedChat.Messages) - 1; i >= 0; i-- {
-			msg := updatedChat.Messages[i]
-			if msg.Role == "assistant" {
-				response = msg.Content
-				break
-			}
-		}
-	}
+	response := lastAssistantMessage(updatedChat)
 	
 	if response == "" {
 		a.log.Error("No response generated")
@@ -498,13 +474,38 @@ func (a *WhatsAppAdapter) processAndReply(conversationID string, message string,
 	// Also add to context
 	a.memoryManager.AddContextMessage(userID, conversationID, fmt.Sprintf("Bot: %s", response))
 	
-	// Extract potential memories from this exchange
-	a.memoryManager.ExtractMemories(userID, conversationID, message, response)
+	// Extract durable facts about the user from this exchange in the background
+	if a.memoryExtractor != nil {
+		a.memoryExtractor.ExtractAsync(userID, conversationID, message, response)
+	}
 	
-	// Send the response
+	// Send any images created by tools, then the response
+	a.sendToolImages(updatedChat, evt)
 	a.sendReply(response, evt)
 }
 
+// getOrCreateChat returns the chat of a conversation, creating it on first use
+func (a *WhatsAppAdapter) getOrCreateChat(ctx context.Context, conversationID string) (*domain.Chat, error) {
+	chat, err := a.chatService.GetChat(ctx, conversationID)
+	if err == nil {
+		return chat, nil
+	}
+
+	// Create a new chat keyed by the conversation so history is found again
+	chatName := fmt.Sprintf("WhatsApp: %s", conversationID)
+	return a.chatService.CreateChatWithID(ctx, conversationID, chatName)
+}
+
+// lastAssistantMessage returns the content of the newest assistant message in a chat
+func lastAssistantMessage(chat *domain.Chat) string {
+	for i := len(chat.Messages) - 1; i >= 0; i-- {
+		if chat.Messages[i].Role == "assistant" {
+			return chat.Messages[i].Content
+		}
+	}
+	return ""
+}
+
 // recordMessage adds a message to the conversation history
 func (a *WhatsAppAdapter) recordMessage(conversationID string, message string) {
 	a.mutex.Lock()
@@ -534,17 +535,25 @@ func (a *WhatsAppAdapter) getOrCreateConversation(evt *events.Message) string {
 	
 	groupJID := evt.Info.Chat.String()
 	
-	// Generate a conversation ID
+	// Generate a conversation ID, direct messages get their own namespace
 	conversationID := fmt.Sprintf("whatsapp-%s", groupJID)
+	if !evt.Info.IsGroup {
+		conversationID = fmt.Sprintf("whatsapp-dm-%s", evt.Info.Chat.ToNonAD().String())
+	}
 	
 	// Check if conversation exists
 	_, exists := a.conversations[conversationID]
 	if !exists {
+		groupName := evt.Info.PushName
+		if evt.Info.IsGroup {
+			groupName = a.getGroupName(evt.Info.Chat)
+		}
+		
 		// Create new conversation
 		a.conversations[conversationID] = &Conversation{
 			ID:        conversationID,
 			GroupID:   groupJID,
-			GroupName: a.getGroupName(evt.Info.Chat),
+			GroupName: groupName,
 			Messages:  []string{},
 			LastActivity: time.Now(),
 		}
@@ -569,6 +578,10 @@ func (a *WhatsAppAdapter) getGroupName(jid types.JID) string {
 
 // isGroupAllowed checks if the group is in the allowed list
 func (a *WhatsAppAdapter) isGroupAllowed(groupJID string) bool {
+	// The list is replaced at runtime by UpdateAllowedGroups
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+	
 	// If no allowed groups configured, don't allow any
 	if len(a.config.AllowedGroups) == 0 {
 		return false
@@ -591,6 +604,45 @@ func (a *WhatsAppAdapter) isGroupAllowed(groupJID string) bool {
 	return false
 }
 
+// mentionsBot checks if a message contains any of the trigger words
+func (a *WhatsAppAdapter) mentionsBot(message string) bool {
+	if message == "" {
+		return false
+	}
+
+	lowerMessage := strings.ToLower(message)
+	for _, triggerWord := range a.config.TriggerWords {
+		if strings.Contains(lowerMessage, strings.ToLower(triggerWord)) {
+			return true
+		}
+	}
+
+	// Fallback to the deprecated single trigger word if needed
+	return a.config.TriggerWord != "" && strings.Contains(lowerMessage, strings.ToLower(a.config.TriggerWord))
+}
+
+// isDirectMessageAllowed checks if direct messages are enabled and the sender is in the allowed contacts
+func (a *WhatsAppAdapter) isDirectMessageAllowed(evt *events.Message) bool {
+	if !a.config.DirectMessages.Enabled {
+		return false
+	}
+
+	chatJID := evt.Info.Chat.ToNonAD().String()
+	senderJID := evt.Info.Sender.ToNonAD().String()
+	for _, allowed := range a.config.DirectMessages.AllowedContacts {
+		if allowed == "*" || allowed == chatJID || allowed == senderJID {
+			return true
+		}
+
+		// Bare phone numbers match the user part of the JID
+		if allowed == evt.Info.Chat.User || allowed == evt.Info.Sender.User {
+			return true
+		}
+	}
+
+	return false
+}
+
 // getMessageText extracts text from the message
 func (a *WhatsAppAdapter) getMessageText(evt *events.Message) string {
 	// Check for direct conversation text
@@ -610,6 +662,11 @@ func (a *WhatsAppAdapter) getMessageText(evt *events.Message) string {
 		return caption
 	}
 	
+	// Check for document caption
+	if evt.Message.GetDocumentMessage() != nil {
+		return evt.Message.GetDocumentMessage().GetCaption()
+	}
+	
 	return ""
 }
 
@@ -617,14 +674,18 @@ func (a *WhatsAppAdapter) getMessageText(evt *events.Message) string {
 func (a *WhatsAppAdapter) sendReply(response string, evt *events.Message) {
 	if a.client == nil || !a.client.IsConnected() {
 		a.log.Error("WhatsApp client not connected")
+		metrics.WhatsAppSendFailures.WithLabelValues(sendFailNotConnected).Inc()
 		return
 	}
 	
 	// Apply rate limiting
 	ctx := context.Background()
+	waitStart := time.Now()
 	err := a.limiter.Wait(ctx)
+	metrics.WhatsAppRateLimitWait.Observe(time.Since(waitStart).Seconds())
 	if err != nil {
 		a.log.Error("Rate limiter error", "error", err)
+		metrics.WhatsAppSendFailures.WithLabelValues(sendFailRateLimiter).Inc()
 		return
 	}
 
@@ -658,8 +719,10 @@ func (a *WhatsAppAdapter) sendReply(response string, evt *events.Message) {
 	_, err = a.client.SendMessage(sendCtx, evt.Info.Chat, msg)
 	if err != nil {
 		a.log.Error("Failed to send WhatsApp reply", "error", err)
+		metrics.WhatsAppSendFailures.WithLabelValues(sendFailSend).Inc()
 		return
 	}
 	
 	a.log.Info("WhatsApp reply sent successfully")
+	a.logBotReply(evt, formattedResponse)
 }
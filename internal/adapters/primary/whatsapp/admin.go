@@ -63,16 +63,19 @@ func (h *AdminHandler) HandleUpdateGroups(w http.ResponseWriter, r *http.Request
 		return
 	}
 	
-	// Update allowed groups in adapter
+	// Update allowed groups in adapter and config
+	// The configuration is locked so a concurrent save doesn't read the list while it is replaced
+	h.cfg.Lock()
 	err = h.adapter.UpdateAllowedGroups(requestData.AllowedGroups)
+	if err == nil {
+		h.cfg.WhatsApp.AllowedGroups = requestData.AllowedGroups
+	}
+	h.cfg.Unlock()
 	if err != nil {
 		h.respondWithError(w, http.StatusInternalServerError, "Failed to update allowed groups")
 		return
 	}
 	
-	// Update config
-	h.cfg.WhatsApp.AllowedGroups = requestData.AllowedGroups
-	
 	// Save config
 	err = config.SaveConfig(h.cfg, config.GetConfigPath())
 	if err != nil {
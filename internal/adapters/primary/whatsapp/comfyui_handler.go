@@ -102,17 +102,25 @@ func (a *WhatsAppAdapter) extractComfyUIRequest(message string) WhatsAppComfyReq
 	return WhatsAppComfyRequest{IsValid: true, Prompt: prompt, HighQuality: highQuality}
 }
 
-// isComfyUIRequest checks if a message is a ComfyUI request
-func (a *WhatsAppAdapter) isComfyUIRequest(message string) bool {
-	request := a.extractComfyUIRequest(message)
-	return request.IsValid
+// comfyUICommand transforms an attached image with the ComfyUI workflow
+func (a *WhatsAppAdapter) comfyUICommand() Command {
+	return Command{
+		Name:        "comfyui",
+		Usage:       "@img[=high] <prompt>",
+		Help:        "Transform the attached image, add =high for more steps",
+		Keywords:    []string{"@img"},
+		MentionOnly: true,
+		NeedsImage:  true,
+		ImageHint:   "Please attach an image to process with avarachan",
+		Priority:    100,
+		Handle: func(req *CommandRequest) {
+			a.processAndReplyWithComfyUI(req.ConversationID, req.Message, req.Event)
+		},
+	}
 }
 
 // processAndReplyWithComfyUI processes an image and sends it to ComfyUI for processing
-func (a *WhatsAppAdapter) processAndReplyWithComfyUI(conversationID string, evt *events.Message) {
-	// Get the message text
-	message := a.getMessageText(evt)
-	
+func (a *WhatsAppAdapter) processAndReplyWithComfyUI(conversationID string, message string, evt *events.Message) {
 	// Extract the ComfyUI request details including any custom prompt
 	comfyRequest := a.extractComfyUIRequest(message)
 	
@@ -525,6 +533,24 @@ func (a *WhatsAppAdapter) uploadImageToComfyUI(imagePath, uploadURL string) (str
 	return name, nil
 }
 
+// PingComfyUI checks that the ComfyUI server answers its system stats endpoint
+func (a *WhatsAppAdapter) PingComfyUI(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, a.config.ComfyUIService.Endpoint+"/system_stats", nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("received status %s", resp.Status)
+	}
+	return nil
+}
+
 // downloadImage downloads an image from a URL
 func (a *WhatsAppAdapter) downloadImage(url string) ([]byte, error) {
 	// Create HTTP client with timeout
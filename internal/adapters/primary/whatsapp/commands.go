@@ -0,0 +1,271 @@
+package whatsapp
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/vibin/chat-bot/config"
+	"go.mau.fi/whatsmeow/types/events"
+)
+
+// AttachmentType is the kind of media a command reacts to
+type AttachmentType string
+
+const (
+	AttachmentNone  AttachmentType = ""
+	AttachmentImage AttachmentType = "image"
+)
+
+// Command is a WhatsApp command that can be registered with the command router
+// A command matches when all of its configured triggers match the message
+type Command struct {
+	Name  string
+	Usage string // Shown after the bot's trigger word in the help listing
+	Help  string
+
+	// Triggers
+	Keywords    []string       // All keywords must appear in the message, case-insensitive
+	Prefix      string         // The message without trigger words must start with this word
+	Pattern     *regexp.Regexp // Matched against the raw message
+	Attachment  AttachmentType // The message must carry this kind of attachment
+	MentionOnly bool           // Only match when a trigger word is used, not on plain replies to the bot
+
+	// NeedsImage makes the router answer with ImageHint instead of running
+	// the handler when the command matches a message without an image
+	NeedsImage bool
+	ImageHint  string
+
+	Priority int         // Higher priority commands are matched first
+	Enabled  func() bool // Optional, the command is skipped when this returns false
+	Handle   func(req *CommandRequest)
+}
+
+// CommandRequest is the message a command is asked to handle
+type CommandRequest struct {
+	ConversationID string
+	Message        string // The raw message text
+	CleanMessage   string // The message text without trigger words
+	Event          *events.Message
+	HasImage       bool
+	IsMention      bool
+	IsReplyToBot   bool
+	Persona        config.PersonaConfig // Persona of the chat, which limits the commands that can be used
+}
+
+// CommandRouter dispatches WhatsApp messages to registered commands by priority
+type CommandRouter struct {
+	mutex    sync.RWMutex
+	commands []Command
+}
+
+// NewCommandRouter creates an empty command router
+func NewCommandRouter() *CommandRouter {
+	return &CommandRouter{}
+}
+
+// Register adds a command, replacing any command with the same name
+func (r *CommandRouter) Register(cmd Command) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for i, existing := range r.commands {
+		if existing.Name == cmd.Name {
+			r.commands = append(r.commands[:i], r.commands[i+1:]...)
+			break
+		}
+	}
+
+	r.commands = append(r.commands, cmd)
+	sort.SliceStable(r.commands, func(i, j int) bool {
+		return r.commands[i].Priority > r.commands[j].Priority
+	})
+}
+
+// Commands returns the registered commands in priority order
+func (r *CommandRouter) Commands() []Command {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	commands := make([]Command, len(r.commands))
+	copy(commands, r.commands)
+	return commands
+}
+
+// Match returns the highest priority enabled command matching the request
+// that the persona of the chat allows
+func (r *CommandRouter) Match(req *CommandRequest) (Command, bool) {
+	for _, cmd := range r.Commands() {
+		if cmd.Enabled != nil && !cmd.Enabled() {
+			continue
+		}
+		if !allowsCommand(req.Persona, cmd) {
+			continue
+		}
+		if cmd.matches(req) {
+			return cmd, true
+		}
+	}
+	return Command{}, false
+}
+
+// allowsCommand reports whether a persona may use a command
+// Help is always available so users can see what they may use
+func allowsCommand(persona config.PersonaConfig, cmd Command) bool {
+	return cmd.Name == "help" || persona.AllowsCommand(cmd.Name)
+}
+
+// matches checks the command's triggers against a request
+func (c Command) matches(req *CommandRequest) bool {
+	// A command without any trigger would swallow every message
+	if len(c.Keywords) == 0 && c.Prefix == "" && c.Pattern == nil && c.Attachment == AttachmentNone {
+		return false
+	}
+
+	if c.MentionOnly && !req.IsMention {
+		return false
+	}
+
+	lowerMessage := strings.ToLower(req.Message)
+	for _, keyword := range c.Keywords {
+		if !strings.Contains(lowerMessage, strings.ToLower(keyword)) {
+			return false
+		}
+	}
+
+	if c.Prefix != "" {
+		fields := strings.Fields(strings.ToLower(req.CleanMessage))
+		if len(fields) == 0 || fields[0] != strings.ToLower(c.Prefix) {
+			return false
+		}
+	}
+
+	if c.Pattern != nil && !c.Pattern.MatchString(req.Message) {
+		return false
+	}
+
+	if c.Attachment == AttachmentImage && !req.HasImage {
+		return false
+	}
+
+	return true
+}
+
+// RegisterCommand adds a command to the adapter's command router
+func (a *WhatsAppAdapter) RegisterCommand(cmd Command) {
+	a.commands.Register(cmd)
+}
+
+// registerDefaultCommands registers the built-in commands
+func (a *WhatsAppAdapter) registerDefaultCommands() {
+	a.RegisterCommand(a.helpCommand())
+	a.RegisterCommand(a.summaryCommand())
+	a.RegisterCommand(a.remindersCommand())
+	a.RegisterCommand(a.cancelReminderCommand())
+	a.RegisterCommand(a.remindCommand())
+	a.RegisterCommand(a.transcribeCommand())
+	a.RegisterCommand(a.comfyUICommand())
+	a.RegisterCommand(a.imageGenerationCommand())
+	a.RegisterCommand(a.documentCommand())
+	a.RegisterCommand(a.imageAnalysisCommand())
+	a.registerServiceCommands()
+}
+
+// dispatchCommand runs the command matching the request
+// Returns false when no command matched
+func (a *WhatsAppAdapter) dispatchCommand(req *CommandRequest) bool {
+	cmd, found := a.commands.Match(req)
+	if !found {
+		return false
+	}
+	a.countHandled(req.Event, cmd.Name)
+
+	if cmd.NeedsImage && !req.HasImage {
+		a.log.Info("Command needs an image but none was attached", "command", cmd.Name, "message", req.Message)
+		a.sendReply(cmd.ImageHint, req.Event)
+		return true
+	}
+
+	a.log.Info("Dispatching command", "command", cmd.Name, "conversation_id", req.ConversationID)
+	go cmd.Handle(req)
+	return true
+}
+
+// helpCommand lists the available commands
+func (a *WhatsAppAdapter) helpCommand() Command {
+	return Command{
+		Name:        "help",
+		Usage:       "help",
+		Help:        "Show this list of commands",
+		Prefix:      "help",
+		MentionOnly: true,
+		Priority:    1000,
+		Handle: func(req *CommandRequest) {
+			a.sendReply(a.helpText(req.Persona), req.Event)
+		},
+	}
+}
+
+// helpText builds the help listing from the commands a persona may use
+func (a *WhatsAppAdapter) helpText(persona config.PersonaConfig) string {
+	trigger := a.primaryTriggerWord()
+
+	var sb strings.Builder
+	if persona.Name != "" {
+		sb.WriteString(fmt.Sprintf("*%s commands*\n", persona.Name))
+	} else {
+		sb.WriteString("*Commands*\n")
+	}
+
+	for _, cmd := range a.commands.Commands() {
+		if cmd.Enabled != nil && !cmd.Enabled() {
+			continue
+		}
+		if !allowsCommand(persona, cmd) {
+			continue
+		}
+
+		usage := strings.TrimSpace(trigger + " " + cmd.Usage)
+		if cmd.Usage == "" && cmd.Attachment == AttachmentImage {
+			usage = "[image] " + usage
+		}
+		sb.WriteString(fmt.Sprintf("\n• %s\n  %s", usage, cmd.Help))
+	}
+
+	sb.WriteString(fmt.Sprintf("\n\nAnything else after %s is answered by the chat model.", trigger))
+	return sb.String()
+}
+
+// primaryTriggerWord returns the trigger word shown in help listings
+func (a *WhatsAppAdapter) primaryTriggerWord() string {
+	if len(a.config.TriggerWords) > 0 {
+		return a.config.TriggerWords[0]
+	}
+	return a.config.TriggerWord
+}
+
+// stripTriggerWords removes all trigger words from a message
+func (a *WhatsAppAdapter) stripTriggerWords(message string) string {
+	cleanMessage := message
+	for _, triggerWord := range a.config.TriggerWords {
+		cleanMessage = strings.ReplaceAll(
+			strings.ToLower(cleanMessage),
+			strings.ToLower(triggerWord),
+			"",
+		)
+	}
+
+	// Also handle the deprecated single trigger word
+	if a.config.TriggerWord != "" {
+		cleanMessage = strings.ReplaceAll(
+			strings.ToLower(cleanMessage),
+			strings.ToLower(a.config.TriggerWord),
+			"",
+		)
+	}
+
+	// Spoken trigger words in voice notes are usually followed by a comma
+	return strings.TrimLeft(strings.TrimSpace(cleanMessage), ",.:;!? ")
+}
@@ -0,0 +1,180 @@
+package whatsapp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/vibin/chat-bot/internal/core/ports"
+	"github.com/vibin/chat-bot/internal/core/services"
+	"go.mau.fi/whatsmeow/types/events"
+)
+
+// SetDocumentService sets the service that stores shared documents and answers questions about them
+func (a *WhatsAppAdapter) SetDocumentService(documents *services.DocumentService) {
+	a.documents = documents
+}
+
+// handleDocument stores a shared document and then handles its caption like a text message
+func (a *WhatsAppAdapter) handleDocument(evt *events.Message) {
+	docMsg := evt.Message.GetDocumentMessage()
+	fileName := docMsg.GetFileName()
+	if fileName == "" {
+		fileName = docMsg.GetTitle()
+	}
+	caption := docMsg.GetCaption()
+	isMention := !evt.Info.IsGroup || a.mentionsBot(caption)
+
+	conversationID := a.getOrCreateConversation(evt)
+	a.logGroupMessage(evt, conversationID, caption)
+
+	if !a.documents.Supports(docMsg.GetMimetype(), fileName) {
+		a.log.Info("Ignoring unsupported document", "file_name", fileName, "mime", docMsg.GetMimetype())
+		a.routeMessage(evt, a.getMessageText(evt))
+		return
+	}
+	if int64(docMsg.GetFileLength()) > a.documents.MaxFileSize() {
+		a.log.Info("Ignoring document that is too large", "file_name", fileName, "size_bytes", docMsg.GetFileLength())
+		if isMention {
+			a.sendReply(fmt.Sprintf("Sorry, %s is too large for me to read.", fileName), evt)
+		}
+		return
+	}
+
+	data, err := a.client.Download(docMsg)
+	if err != nil {
+		a.log.Error("Failed to download document", "file_name", fileName, "error", err)
+		return
+	}
+
+	// Embedding a long document chunk by chunk takes a while
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	doc, err := a.documents.Ingest(ctx, conversationID, fileName, docMsg.GetMimetype(), data)
+	if err != nil {
+		a.log.Error("Failed to ingest document", "file_name", fileName, "error", err)
+		if isMention {
+			a.sendReply(fmt.Sprintf("Sorry, I couldn't read %s: %v", fileName, err), evt)
+		}
+		return
+	}
+
+	// A caption that only mentions the bot asks it to read the file
+	if isMention && a.stripTriggerWords(caption) == "" {
+		pages := ""
+		if doc.Pages > 0 {
+			pages = fmt.Sprintf(" (%d pages)", doc.Pages)
+		}
+		a.sendReply(fmt.Sprintf("📄 I've read %s%s. Ask me about it with %s @doc <question>.",
+			fileName, pages, a.primaryTriggerWord()), evt)
+		return
+	}
+
+	a.routeMessage(evt, a.getMessageText(evt))
+}
+
+// documentCommand answers questions about the documents shared in the conversation
+func (a *WhatsAppAdapter) documentCommand() Command {
+	return Command{
+		Name:        "doc",
+		Usage:       "@doc <question>",
+		Help:        "Ask about the PDFs and text files shared in this chat, or list them",
+		Keywords:    []string{"@doc"},
+		MentionOnly: true,
+		Priority:    85,
+		Enabled: func() bool {
+			return a.documents != nil
+		},
+		Handle: func(req *CommandRequest) {
+			question := strings.TrimSpace(strings.ReplaceAll(req.CleanMessage, "@doc", ""))
+			a.processAndReplyWithDocuments(req.ConversationID, question, req.Event)
+		},
+	}
+}
+
+// processAndReplyWithDocuments answers a question from the conversation's documents with page citations
+func (a *WhatsAppAdapter) processAndReplyWithDocuments(conversationID, question string, evt *events.Message) {
+	userID := evt.Info.Sender.String()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 180*time.Second)
+	defer cancel()
+	ctx = ports.WithToolScope(ctx, ports.ToolScope{UserID: userID})
+	ctx = withPersona(ctx, a.persona(evt.Info.Chat.String()))
+	ctx = a.withQueueInfo(ctx, evt)
+
+	if question == "" {
+		a.sendReply(a.documentList(ctx, conversationID), evt)
+		return
+	}
+
+	chunks, err := a.documents.Search(ctx, conversationID, question)
+	if err != nil {
+		a.log.Error("Failed to search documents", "error", err)
+		a.sendReply("Sorry, I couldn't search the documents right now.", evt)
+		return
+	}
+	if len(chunks) == 0 {
+		documents, _ := a.documents.ListDocuments(ctx, conversationID)
+		if len(documents) == 0 {
+			a.sendReply("No documents have been shared in this chat yet. Send a PDF or text file first.", evt)
+		} else {
+			a.sendReply("I couldn't find anything about that in the documents shared here.", evt)
+		}
+		return
+	}
+
+	chat, err := a.getOrCreateChat(ctx, conversationID)
+	if err != nil {
+		a.log.Error("Failed to create chat", "error", err)
+		return
+	}
+
+	a.recordMessage(conversationID, fmt.Sprintf("User: %s", question))
+	a.memoryManager.AddContextMessage(userID, conversationID, fmt.Sprintf("User: %s", question))
+
+	updatedChat, err := a.chatService.SendMessageWithPrompt(ctx, chat.ID, question, a.documents.BuildQuestionPrompt(question, chunks))
+	if err != nil {
+		a.log.Error("Failed to answer document question", "error", err)
+		if !a.replyIfBusy(err, evt) {
+			a.sendReply("Sorry, I couldn't answer that right now.", evt)
+		}
+		return
+	}
+
+	response := lastAssistantMessage(updatedChat)
+	if response == "" {
+		a.log.Error("No response generated")
+		return
+	}
+
+	a.recordMessage(conversationID, fmt.Sprintf("Bot: %s", response))
+	a.memoryManager.AddContextMessage(userID, conversationID, fmt.Sprintf("Bot: %s", response))
+
+	a.sendReply(fmt.Sprintf("%s\n\n📄 _Sources: %s_", response, a.documents.FormatSources(chunks)), evt)
+}
+
+// documentList lists the documents shared in a conversation
+func (a *WhatsAppAdapter) documentList(ctx context.Context, conversationID string) string {
+	documents, err := a.documents.ListDocuments(ctx, conversationID)
+	if err != nil {
+		a.log.Error("Failed to list documents", "error", err)
+		return "Sorry, I couldn't list the documents right now."
+	}
+	if len(documents) == 0 {
+		return "No documents have been shared in this chat yet. Send a PDF or text file first."
+	}
+
+	var sb strings.Builder
+	sb.WriteString("*Documents in this chat*\n")
+	for _, doc := range documents {
+		if doc.Pages > 0 {
+			sb.WriteString(fmt.Sprintf("\n• %s (%d pages)", doc.FileName, doc.Pages))
+		} else {
+			sb.WriteString(fmt.Sprintf("\n• %s", doc.FileName))
+		}
+	}
+	sb.WriteString(fmt.Sprintf("\n\nAsk with %s @doc <question>.", a.primaryTriggerWord()))
+	return sb.String()
+}
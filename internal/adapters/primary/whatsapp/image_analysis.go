@@ -36,6 +36,19 @@ func (a *WhatsAppAdapter) hasImage(evt *events.Message) bool {
 	return evt.Message.GetImageMessage() != nil
 }
 
+// imageAnalysisCommand describes an attached image
+func (a *WhatsAppAdapter) imageAnalysisCommand() Command {
+	return Command{
+		Name:       "image-analysis",
+		Help:       "Describe or answer questions about the attached image",
+		Attachment: AttachmentImage,
+		Priority:   70,
+		Handle: func(req *CommandRequest) {
+			a.processAndReplyWithImageAnalysis(req.ConversationID, req.Event)
+		},
+	}
+}
+
 // extractImageData extracts the image data and caption from a message
 func (a *WhatsAppAdapter) extractImageData(evt *events.Message) (*ImageMessage, error) {
 	a.log.Info("Extracting image data from message", "message_id", evt.Info.ID)
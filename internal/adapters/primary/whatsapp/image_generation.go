@@ -25,12 +25,19 @@ type ImageGenerationCommand struct {
 	Size   string
 }
 
-// isImageGenerationRequest checks if the message is a request to generate an image
-func (a *WhatsAppAdapter) isImageGenerationRequest(message string) bool {
-	lowerMessage := strings.ToLower(message)
-
-	// Check for "@sasi @image" pattern
-	return strings.Contains(lowerMessage, "@sasi") && strings.Contains(lowerMessage, "@image")
+// imageGenerationCommand generates an image from a text prompt
+func (a *WhatsAppAdapter) imageGenerationCommand() Command {
+	return Command{
+		Name:        "image",
+		Usage:       "@image <prompt>",
+		Help:        "Generate an image from a description",
+		Keywords:    []string{"@image"},
+		MentionOnly: true,
+		Priority:    90,
+		Handle: func(req *CommandRequest) {
+			a.processAndReplyWithImageGeneration(req.ConversationID, req.Message, req.Event)
+		},
+	}
 }
 
 // parseImageGenerationCommand parses the image generation command
@@ -67,9 +74,8 @@ func (a *WhatsAppAdapter) parseImageGenerationCommand(message string) *ImageGene
 }
 
 // processAndReplyWithImageGeneration handles image generation request and sends reply
-func (a *WhatsAppAdapter) processAndReplyWithImageGeneration(conversationID string, evt *events.Message) {
-	// Extract message text and parse command
-	messageText := a.getMessageText(evt)
+func (a *WhatsAppAdapter) processAndReplyWithImageGeneration(conversationID string, messageText string, evt *events.Message) {
+	// Parse the command from the message text
 	cmd := a.parseImageGenerationCommand(messageText)
 
 	// Log details
@@ -0,0 +1,43 @@
+package whatsapp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/vibin/chat-bot/internal/core/ports"
+	"go.mau.fi/whatsmeow/types/events"
+)
+
+// withQueueInfo has the LLM calls made for a message take turns with the other chats,
+// and tells the sender their place in line the first time one has to wait
+func (a *WhatsAppAdapter) withQueueInfo(ctx context.Context, evt *events.Message) context.Context {
+	var once sync.Once
+	return ports.WithLLMQueueInfo(ctx, ports.LLMQueueInfo{
+		Group:    evt.Info.Chat.String(),
+		Priority: ports.LLMPriorityNormal,
+		OnQueued: func(position int) {
+			message := a.config.QueuePositionMessage
+			if message == "" {
+				return
+			}
+			if strings.Contains(message, "%d") {
+				message = fmt.Sprintf(message, position)
+			}
+			// The queue waits for this callback, so the reply is sent without holding it up
+			once.Do(func() { go a.sendReply(message, evt) })
+		},
+	})
+}
+
+// replyIfBusy tells the sender to try again later when an LLM call failed because too many requests were queued
+func (a *WhatsAppAdapter) replyIfBusy(err error, evt *events.Message) bool {
+	if !ports.IsLLMBusy(err) {
+		return false
+	}
+	if a.config.BusyMessage != "" {
+		a.sendReply(a.config.BusyMessage, evt)
+	}
+	return true
+}
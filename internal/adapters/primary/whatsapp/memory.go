@@ -136,15 +136,3 @@ func (m *MemoryManager) ClearContext(userID, conversationID string) {
 	key := createSessionKey(userID, conversationID)
 	delete(m.context, key)
 }
-
-// ExtractMemories extracts potential memories from a message
-// In a real implementation, this would use NLP or some heuristic to identify
-// personal information, preferences, etc.
-func (m *MemoryManager) ExtractMemories(userID, conversationID string, userMessage string, botResponse string) {
-	// This is a simplified implementation
-	// In a real system, you'd use NLP or pattern matching to extract memories
-	
-	// For now, we'll just add the entire exchange as context
-	m.AddContextMessage(userID, conversationID, "User: "+userMessage)
-	m.AddContextMessage(userID, conversationID, "Bot: "+botResponse)
-}
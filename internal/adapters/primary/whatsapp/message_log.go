@@ -0,0 +1,257 @@
+package whatsapp
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vibin/chat-bot/internal/core/domain"
+	"github.com/vibin/chat-bot/internal/core/ports"
+	"github.com/vibin/chat-bot/internal/core/services"
+	"go.mau.fi/whatsmeow/types/events"
+)
+
+const (
+	// defaultDigestWindow is how far back a digest looks when the reader has not written before
+	defaultDigestWindow = 24 * time.Hour
+
+	// maxDigestMessages caps the messages read for a digest
+	maxDigestMessages = 1000
+)
+
+// SetMessageLog sets the passive log of group messages used for digests
+func (a *WhatsAppAdapter) SetMessageLog(messageLog ports.MessageLogPort) {
+	a.messageLog = messageLog
+}
+
+// logGroupMessage records a group message in the message log
+func (a *WhatsAppAdapter) logGroupMessage(evt *events.Message, conversationID, message string) {
+	if a.messageLog == nil || !evt.Info.IsGroup {
+		return
+	}
+
+	content := describeMessage(evt, message)
+	if content == "" {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := a.messageLog.Append(ctx, domain.LoggedMessage{
+		ConversationID: conversationID,
+		MessageID:      evt.Info.ID,
+		SenderID:       evt.Info.Sender.ToNonAD().String(),
+		SenderName:     evt.Info.PushName,
+		Content:        content,
+		FromBot:        evt.Info.IsFromMe,
+		Timestamp:      evt.Info.Timestamp,
+	})
+	if err != nil {
+		a.log.Warn("Failed to log group message", "error", err)
+	}
+}
+
+// logBotReply records a reply sent by the bot in the message log
+func (a *WhatsAppAdapter) logBotReply(evt *events.Message, response string) {
+	if a.messageLog == nil || !evt.Info.IsGroup {
+		return
+	}
+
+	senderID := ""
+	if a.client != nil && a.client.Store.ID != nil {
+		senderID = a.client.Store.ID.ToNonAD().String()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := a.messageLog.Append(ctx, domain.LoggedMessage{
+		ConversationID: fmt.Sprintf("whatsapp-%s", evt.Info.Chat.String()),
+		SenderID:       senderID,
+		SenderName:     a.config.BotName,
+		Content:        response,
+		FromBot:        true,
+		Timestamp:      time.Now(),
+	})
+	if err != nil {
+		a.log.Warn("Failed to log bot reply", "error", err)
+	}
+}
+
+// describeMessage returns the text to log for a message, with placeholders for media
+func describeMessage(evt *events.Message, message string) string {
+	var media string
+	switch {
+	case evt.Message.GetImageMessage() != nil:
+		media = "[image]"
+	case evt.Message.GetAudioMessage() != nil:
+		media = "[voice note]"
+	case evt.Message.GetVideoMessage() != nil:
+		media = "[video]"
+	case evt.Message.GetDocumentMessage() != nil:
+		media = fmt.Sprintf("[document: %s]", evt.Message.GetDocumentMessage().GetFileName())
+	case evt.Message.GetStickerMessage() != nil:
+		media = "[sticker]"
+	}
+
+	switch {
+	case media != "" && message != "":
+		return media + " " + message
+	case media != "":
+		return media
+	default:
+		return message
+	}
+}
+
+// pruneMessageLog applies the configured retention limits to the message log
+func (a *WhatsAppAdapter) pruneMessageLog() {
+	if a.messageLog == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	maxAge := time.Duration(a.config.MessageLog.RetentionHours) * time.Hour
+	deleted, err := a.messageLog.Prune(ctx, maxAge, a.config.MessageLog.MaxMessagesPerGroup)
+	if err != nil {
+		a.log.Error("Failed to prune message log", "error", err)
+		return
+	}
+	if deleted > 0 {
+		a.log.Info("Pruned message log", "deleted", deleted)
+	}
+}
+
+// summaryCommand sends a digest of the group conversation the reader missed
+func (a *WhatsAppAdapter) summaryCommand() Command {
+	return Command{
+		Name:        "summary",
+		Usage:       "summary [since 2h | last 200]",
+		Help:        "Catch up on what you missed: topics, decisions and questions for you",
+		Prefix:      "summary",
+		MentionOnly: true,
+		Priority:    95,
+		Enabled: func() bool {
+			return a.messageLog != nil
+		},
+		Handle: func(req *CommandRequest) {
+			args := strings.TrimSpace(strings.TrimPrefix(req.CleanMessage, "summary"))
+			a.processAndReplyWithDigest(req.ConversationID, args, req.Event)
+		},
+	}
+}
+
+// processAndReplyWithDigest summarizes the logged messages selected by args for the sender
+func (a *WhatsAppAdapter) processAndReplyWithDigest(conversationID, args string, evt *events.Message) {
+	if !evt.Info.IsGroup {
+		a.sendReply("Summaries are only available in groups.", evt)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 180*time.Second)
+	defer cancel()
+	ctx = a.withQueueInfo(ctx, evt)
+
+	since, limit, err := parseDigestRange(args, time.Now())
+	if err != nil {
+		a.sendReply(fmt.Sprintf("%v. Try %s summary, %s summary since 2h or %s summary last 200.",
+			err, a.primaryTriggerWord(), a.primaryTriggerWord(), a.primaryTriggerWord()), evt)
+		return
+	}
+
+	// Without a range, catch up since the sender last wrote in the group
+	if args == "" {
+		since = time.Now().Add(-defaultDigestWindow)
+		lastSeen, err := a.messageLog.LastFrom(ctx, conversationID, evt.Info.Sender.ToNonAD().String(), evt.Info.Timestamp)
+		if err != nil {
+			a.log.Warn("Failed to find the sender's last message", "error", err)
+		} else if lastSeen.After(since) {
+			since = lastSeen
+		}
+	}
+
+	logged, err := a.messageLog.Since(ctx, conversationID, since, limit+1)
+	if err != nil {
+		a.log.Error("Failed to read message log", "error", err)
+		a.sendReply("Sorry, I couldn't read the conversation history.", evt)
+		return
+	}
+
+	// The summary request itself is not part of the digest
+	messages := make([]domain.LoggedMessage, 0, len(logged))
+	for _, msg := range logged {
+		if msg.MessageID != evt.Info.ID {
+			messages = append(messages, msg)
+		}
+	}
+	if len(messages) > limit {
+		messages = messages[len(messages)-limit:]
+	}
+	if len(messages) == 0 {
+		a.sendReply("Nothing new was said in that time.", evt)
+		return
+	}
+
+	digest, err := a.chatService.DigestConversation(ctx, messages, services.DigestReader{
+		Name:  evt.Info.PushName,
+		Phone: evt.Info.Sender.User,
+	})
+	if err != nil {
+		a.log.Error("Failed to generate digest", "error", err)
+		if !a.replyIfBusy(err, evt) {
+			a.sendReply("Sorry, I couldn't summarize the conversation right now.", evt)
+		}
+		return
+	}
+
+	header := fmt.Sprintf("📋 _Summary of %d messages since %s_\n\n", len(messages), messages[0].Timestamp.Local().Format("Mon 15:04"))
+	a.sendReply(header+digest, evt)
+}
+
+// parseDigestRange parses "since <duration>" or "last <count>" into a start time and message limit
+// Durations accept Go syntax such as 90m or 2h30m, and days such as 2d
+func parseDigestRange(args string, now time.Time) (time.Time, int, error) {
+	fields := strings.Fields(strings.ToLower(args))
+	if len(fields) == 0 {
+		return time.Time{}, maxDigestMessages, nil
+	}
+	if len(fields) != 2 {
+		return time.Time{}, 0, fmt.Errorf("I didn't understand %q", args)
+	}
+
+	switch fields[0] {
+	case "since":
+		value := fields[1]
+		var window time.Duration
+		if days, ok := strings.CutSuffix(value, "d"); ok {
+			n, err := strconv.Atoi(days)
+			if err != nil || n <= 0 {
+				return time.Time{}, 0, fmt.Errorf("%q is not a valid duration", value)
+			}
+			window = time.Duration(n) * 24 * time.Hour
+		} else {
+			d, err := time.ParseDuration(value)
+			if err != nil || d <= 0 {
+				return time.Time{}, 0, fmt.Errorf("%q is not a valid duration", value)
+			}
+			window = d
+		}
+		return now.Add(-window), maxDigestMessages, nil
+	case "last":
+		n, err := strconv.Atoi(fields[1])
+		if err != nil || n <= 0 {
+			return time.Time{}, 0, fmt.Errorf("%q is not a valid number of messages", fields[1])
+		}
+		if n > maxDigestMessages {
+			n = maxDigestMessages
+		}
+		return time.Time{}, n, nil
+	default:
+		return time.Time{}, 0, fmt.Errorf("I didn't understand %q", args)
+	}
+}
@@ -0,0 +1,54 @@
+package whatsapp
+
+import (
+	"github.com/vibin/chat-bot/internal/metrics"
+	"go.mau.fi/whatsmeow/types/events"
+)
+
+// Metric labels for messages the bot did not act on
+const (
+	ignoredFromMe       = "from_me"
+	ignoredNotAllowed   = "not_allowed"
+	ignoredNotAddressed = "not_addressed"
+	ignoredNoText       = "no_text"
+)
+
+// Metric labels for messages handled without a registered command
+const (
+	commandChat     = "chat" // Answered by the chat model
+	commandDocument = "document"
+)
+
+// Metric labels for replies that could not be sent
+const (
+	sendFailNotConnected = "not_connected"
+	sendFailRateLimiter  = "rate_limiter"
+	sendFailSend         = "send"
+)
+
+// metricsGroup returns the group label of a message
+// Only allowed groups get their own label
+func (a *WhatsAppAdapter) metricsGroup(evt *events.Message) string {
+	if !evt.Info.IsGroup {
+		return metrics.DirectChat
+	}
+	if !a.isGroupAllowed(evt.Info.Chat.String()) {
+		return metrics.OtherChat
+	}
+	return evt.Info.Chat.String()
+}
+
+// countReceived counts a new message
+func (a *WhatsAppAdapter) countReceived(evt *events.Message) {
+	metrics.WhatsAppMessagesReceived.WithLabelValues(a.metricsGroup(evt)).Inc()
+}
+
+// countHandled counts a message the bot acted on with the given command
+func (a *WhatsAppAdapter) countHandled(evt *events.Message, command string) {
+	metrics.WhatsAppMessagesHandled.WithLabelValues(a.metricsGroup(evt), command).Inc()
+}
+
+// countIgnored counts a message the bot did not act on
+func (a *WhatsAppAdapter) countIgnored(evt *events.Message, reason string) {
+	metrics.WhatsAppMessagesIgnored.WithLabelValues(a.metricsGroup(evt), reason).Inc()
+}
@@ -1,6 +1,7 @@
 package whatsapp
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"time"
@@ -23,11 +24,46 @@ func (a *WhatsAppAdapter) InitializeMemoryDB() (*services.MemoryService, error)
 	return memoryService, nil
 }
 
+// InMemoryMemoryStore returns a store keeping extracted memories in the in-memory memory manager
+// It is used when the memory database can't be opened, so memories still last until a restart
+func (a *WhatsAppAdapter) InMemoryMemoryStore() services.MemoryStore {
+	return inMemoryStore{manager: a.memoryManager}
+}
+
+// inMemoryStore adapts the MemoryManager to services.MemoryStore
+type inMemoryStore struct {
+	manager *MemoryManager
+}
+
+// GetMemoriesAsStrings returns the contents of a user's in-memory memories
+func (s inMemoryStore) GetMemoriesAsStrings(userID, conversationID string) ([]string, error) {
+	s.manager.mutex.RLock()
+	defer s.manager.mutex.RUnlock()
+
+	memories := s.manager.memories[createSessionKey(userID, conversationID)]
+	contents := make([]string, len(memories))
+	for i, memory := range memories {
+		contents[i] = memory.Content
+	}
+	return contents, nil
+}
+
+// AddMemory adds an in-memory memory
+func (s inMemoryStore) AddMemory(userID, conversationID, content string) error {
+	s.manager.AddMemory(userID, conversationID, content)
+	return nil
+}
+
 // SetMemoryService sets the memory service for the adapter
 func (a *WhatsAppAdapter) SetMemoryService(memoryService *services.MemoryService) {
 	a.memoryService = memoryService
 }
 
+// SetMemoryExtractor sets the extractor that stores memories from conversations
+func (a *WhatsAppAdapter) SetMemoryExtractor(memoryExtractor *services.MemoryExtractor) {
+	a.memoryExtractor = memoryExtractor
+}
+
 // SyncMemoryToDatabase syncs in-memory memories to the database
 func (a *WhatsAppAdapter) SyncMemoryToDatabase(userID, conversationID string) error {
 	if a.memoryService == nil {
@@ -171,6 +207,32 @@ func (a *WhatsAppAdapter) GetPersistentMemories(userID, conversationID string) [
 	return merged
 }
 
+// getRelevantMemories returns the memories most relevant to a message
+// It falls back to all memories if semantic retrieval is unavailable
+func (a *WhatsAppAdapter) getRelevantMemories(ctx context.Context, userID, conversationID, message string) []Memory {
+	if a.memoryService == nil {
+		return a.memoryManager.GetMemories(userID, conversationID)
+	}
+
+	dbMemories, err := a.memoryService.RelevantMemories(ctx, userID, conversationID, message)
+	if err != nil {
+		a.log.Warn("Failed to retrieve relevant memories, using all memories", "error", err)
+		return a.GetPersistentMemories(userID, conversationID)
+	}
+
+	memories := make([]Memory, len(dbMemories))
+	for i, dbMemory := range dbMemories {
+		memories[i] = Memory{
+			Content:   dbMemory.Content,
+			CreatedAt: dbMemory.CreatedAt,
+			LastUsed:  dbMemory.LastUsed,
+			UseCount:  dbMemory.UseCount,
+		}
+	}
+
+	return memories
+}
+
 // mergeMemories merges two sets of memories, removing duplicates based on content
 func mergeMemories(a, b []Memory) []Memory {
 	seen := make(map[string]bool)
@@ -0,0 +1,95 @@
+package whatsapp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/vibin/chat-bot/config"
+	"github.com/vibin/chat-bot/internal/core/ports"
+)
+
+// persona returns the persona assigned to a chat
+func (a *WhatsAppAdapter) persona(chatJID string) config.PersonaConfig {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	return a.config.PersonaFor(chatJID)
+}
+
+// withPersona returns a context whose LLM calls use the persona's system prompt
+func withPersona(ctx context.Context, persona config.PersonaConfig) context.Context {
+	return ports.WithSystemPrompt(ctx, personaSystemPrompt(persona))
+}
+
+// personaSystemPrompt builds the system prompt for a persona
+// An empty result keeps the LLM adapter's default prompt
+func personaSystemPrompt(persona config.PersonaConfig) string {
+	if persona.SystemPrompt == "" && persona.Tone == "" && persona.Language == "" {
+		return ""
+	}
+
+	prompt := persona.SystemPrompt
+	if prompt == "" {
+		prompt = fmt.Sprintf("You are %s, a helpful assistant in a WhatsApp chat. Keep your responses concise and to the point unless the user specifically asks for detailed explanations or descriptions.", persona.Name)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(prompt)
+	if persona.Tone != "" {
+		sb.WriteString(fmt.Sprintf("\nUse a %s tone.", persona.Tone))
+	}
+	if persona.Language != "" {
+		sb.WriteString(fmt.Sprintf("\nAlways reply in %s.", persona.Language))
+	}
+	return sb.String()
+}
+
+// GetPersonas returns copies of the persona profiles, the default persona and the group assignments
+func (a *WhatsAppAdapter) GetPersonas() (map[string]config.PersonaConfig, string, map[string]string) {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	personas := make(map[string]config.PersonaConfig, len(a.config.Personas))
+	for key, persona := range a.config.Personas {
+		personas[key] = persona
+	}
+	groupPersonas := make(map[string]string, len(a.config.GroupPersonas))
+	for groupID, key := range a.config.GroupPersonas {
+		groupPersonas[groupID] = key
+	}
+	return personas, a.config.DefaultPersona, groupPersonas
+}
+
+// UpdatePersonas replaces the persona profiles and their group assignments
+func (a *WhatsAppAdapter) UpdatePersonas(personas map[string]config.PersonaConfig, defaultPersona string, groupPersonas map[string]string) error {
+	if defaultPersona != "" {
+		if _, ok := personas[defaultPersona]; !ok {
+			return fmt.Errorf("default persona %q does not exist", defaultPersona)
+		}
+	}
+	for groupID, key := range groupPersonas {
+		if _, ok := personas[key]; !ok {
+			return fmt.Errorf("persona %q assigned to %s does not exist", key, groupID)
+		}
+	}
+
+	a.mutex.Lock()
+	a.config.Personas = personas
+	a.config.DefaultPersona = defaultPersona
+	a.config.GroupPersonas = groupPersonas
+	a.mutex.Unlock()
+
+	a.log.Info("Updated personas", "personas", len(personas), "assigned_groups", len(groupPersonas))
+	return nil
+}
+
+// GetCommandNames returns the names of the registered commands, for persona command lists
+func (a *WhatsAppAdapter) GetCommandNames() []string {
+	commands := a.commands.Commands()
+	names := make([]string, 0, len(commands))
+	for _, cmd := range commands {
+		names = append(names, cmd.Name)
+	}
+	return names
+}
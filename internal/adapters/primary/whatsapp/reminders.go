@@ -0,0 +1,139 @@
+package whatsapp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/vibin/chat-bot/internal/core/services"
+	"go.mau.fi/whatsmeow/types/events"
+)
+
+// SetScheduler sets the scheduler used for reminders and recurring messages
+func (a *WhatsAppAdapter) SetScheduler(scheduler *services.Scheduler) {
+	a.scheduler = scheduler
+}
+
+// remindCommand schedules a reminder or recurring message described in natural language
+func (a *WhatsAppAdapter) remindCommand() Command {
+	return Command{
+		Name:        "remind",
+		Usage:       "remind us <when> to <what>",
+		Help:        "Schedule a reminder, once (Friday 6pm) or repeating (every Monday at 9)",
+		Prefix:      "remind",
+		MentionOnly: true,
+		Priority:    94,
+		Enabled: func() bool {
+			return a.scheduler != nil
+		},
+		Handle: func(req *CommandRequest) {
+			a.processAndReplyWithReminder(req.CleanMessage, req.Event)
+		},
+	}
+}
+
+// remindersCommand lists the scheduled messages of the chat
+func (a *WhatsAppAdapter) remindersCommand() Command {
+	return Command{
+		Name:        "reminders",
+		Usage:       "reminders",
+		Help:        "List the reminders scheduled in this chat",
+		Prefix:      "reminders",
+		MentionOnly: true,
+		Priority:    94,
+		Enabled: func() bool {
+			return a.scheduler != nil
+		},
+		Handle: func(req *CommandRequest) {
+			a.sendReply(a.reminderList(req.Event), req.Event)
+		},
+	}
+}
+
+// cancelReminderCommand cancels a scheduled message of the chat by ID
+func (a *WhatsAppAdapter) cancelReminderCommand() Command {
+	return Command{
+		Name:        "cancel-reminder",
+		Usage:       "cancel reminder <id>",
+		Help:        "Cancel a scheduled reminder",
+		Keywords:    []string{"reminder"},
+		Prefix:      "cancel",
+		MentionOnly: true,
+		Priority:    94,
+		Enabled: func() bool {
+			return a.scheduler != nil
+		},
+		Handle: func(req *CommandRequest) {
+			fields := strings.Fields(req.CleanMessage)
+			id := ""
+			if len(fields) > 2 {
+				id = fields[len(fields)-1]
+			}
+			a.processAndReplyWithCancel(id, req.Event)
+		},
+	}
+}
+
+// processAndReplyWithReminder asks the scheduler to create a job from the request and confirms it
+func (a *WhatsAppAdapter) processAndReplyWithReminder(request string, evt *events.Message) {
+	if !evt.Info.IsGroup {
+		a.sendReply("Reminders are only available in groups.", evt)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	job, err := a.scheduler.ScheduleFromText(ctx, evt.Info.Chat.String(), request, evt.Info.Sender.ToNonAD().String())
+	if err != nil {
+		a.log.Warn("Failed to schedule reminder", "request", request, "error", err)
+		a.sendReply(fmt.Sprintf("Sorry, I couldn't schedule that: %v", err), evt)
+		return
+	}
+
+	a.sendReply(fmt.Sprintf("⏰ Got it, I'll post \"%s\" %s.\n_Cancel with %s cancel reminder %s_",
+		job.Message, a.scheduler.DescribeJob(job), a.primaryTriggerWord(), job.ID), evt)
+}
+
+// processAndReplyWithCancel cancels a job of the chat and confirms it
+func (a *WhatsAppAdapter) processAndReplyWithCancel(id string, evt *events.Message) {
+	if id == "" {
+		a.sendReply(fmt.Sprintf("Which one? Send %s reminders to see their IDs.", a.primaryTriggerWord()), evt)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := a.scheduler.CancelJob(ctx, evt.Info.Chat.String(), id); err != nil {
+		a.log.Warn("Failed to cancel reminder", "job_id", id, "error", err)
+		a.sendReply(fmt.Sprintf("I couldn't find a reminder with ID %s here.", id), evt)
+		return
+	}
+
+	a.sendReply(fmt.Sprintf("🗑️ Reminder %s cancelled.", id), evt)
+}
+
+// reminderList lists the jobs scheduled in the chat of a message
+func (a *WhatsAppAdapter) reminderList(evt *events.Message) string {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	jobs, err := a.scheduler.ListJobs(ctx, evt.Info.Chat.String())
+	if err != nil {
+		a.log.Error("Failed to list reminders", "error", err)
+		return "Sorry, I couldn't list the reminders right now."
+	}
+	if len(jobs) == 0 {
+		return fmt.Sprintf("No reminders are scheduled here. Add one with %s remind us <when> to <what>.", a.primaryTriggerWord())
+	}
+
+	var sb strings.Builder
+	sb.WriteString("*Reminders in this chat*\n")
+	for _, job := range jobs {
+		sb.WriteString(fmt.Sprintf("\n• `%s` %s, %s", job.ID, job.Message, a.scheduler.DescribeJob(job)))
+	}
+	sb.WriteString(fmt.Sprintf("\n\nCancel one with %s cancel reminder <id>.", a.primaryTriggerWord()))
+	return sb.String()
+}
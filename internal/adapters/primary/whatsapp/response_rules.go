@@ -0,0 +1,44 @@
+package whatsapp
+
+import (
+	"context"
+
+	"github.com/vibin/chat-bot/config"
+	"github.com/vibin/chat-bot/internal/core/services"
+	"go.mau.fi/whatsmeow/types/events"
+)
+
+// SetResponseRules sets the rules that answer messages with canned responses
+func (a *WhatsAppAdapter) SetResponseRules(rules *services.ResponseRuleService) {
+	a.rules = rules
+}
+
+// matchResponseRule returns the canned response of the first rule matching a message
+func (a *WhatsAppAdapter) matchResponseRule(ctx context.Context, message string, evt *events.Message, persona config.PersonaConfig) (string, bool) {
+	if a.rules == nil {
+		return "", false
+	}
+
+	chatJID := evt.Info.Chat.String()
+	a.mutex.RLock()
+	personaKey := a.config.PersonaKey(chatJID)
+	a.mutex.RUnlock()
+
+	match, err := a.rules.Match(ctx, services.RuleInput{
+		Message:    message,
+		ChatJID:    chatJID,
+		Sender:     evt.Info.PushName,
+		PersonaKey: personaKey,
+		Persona:    persona,
+	})
+	if err != nil {
+		a.log.Error("Failed to match response rules", "error", err)
+		return "", false
+	}
+	if match == nil {
+		return "", false
+	}
+
+	a.log.Info("Using response rule", "rule_id", match.RuleID)
+	return match.Response, true
+}
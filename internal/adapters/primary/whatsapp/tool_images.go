@@ -0,0 +1,43 @@
+package whatsapp
+
+import (
+	"encoding/base64"
+
+	"github.com/vibin/chat-bot/internal/core/domain"
+	"go.mau.fi/whatsmeow/types/events"
+)
+
+// sendToolImages sends the images produced by tool calls during the latest turn of a chat
+func (a *WhatsAppAdapter) sendToolImages(chat *domain.Chat, evt *events.Message) {
+	// Find where the latest turn started
+	start := len(chat.Messages)
+	for start > 0 && chat.Messages[start-1].Role != "user" {
+		start--
+	}
+	
+	captions := make(map[string]string)
+	for _, msg := range chat.Messages[start:] {
+		// Remember the prompt of each tool call to use as the image caption
+		for _, call := range msg.ToolCalls {
+			if prompt, ok := call.Arguments["prompt"].(string); ok {
+				captions[call.Name] = prompt
+			}
+		}
+		
+		if msg.Role != "tool" {
+			continue
+		}
+		
+		for _, image := range msg.Images {
+			imageData, err := base64.StdEncoding.DecodeString(image)
+			if err != nil {
+				a.log.Error("Failed to decode tool image", "tool", msg.ToolName, "error", err)
+				continue
+			}
+			
+			if err := a.sendGeneratedImage(evt, imageData, captions[msg.ToolName]); err != nil {
+				a.log.Error("Failed to send tool image", "tool", msg.ToolName, "error", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package whatsapp
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/vibin/chat-bot/internal/core/ports"
+	waProto "go.mau.fi/whatsmeow/binary/proto"
+	"go.mau.fi/whatsmeow/types/events"
+)
+
+// SetSpeechToText sets the transcriber used for voice notes
+func (a *WhatsAppAdapter) SetSpeechToText(speech ports.SpeechToTextPort) {
+	a.speech = speech
+}
+
+// handleVoiceNote transcribes a voice note and routes the transcript like a text message
+func (a *WhatsAppAdapter) handleVoiceNote(evt *events.Message) {
+	transcript, err := a.transcribeAudio(evt.Message.GetAudioMessage())
+	if err == nil && transcript == "" {
+		err = fmt.Errorf("transcript is empty")
+	}
+	if err != nil {
+		a.log.Error("Failed to transcribe voice note", "message_id", evt.Info.ID, "error", err)
+		a.logGroupMessage(evt, a.getOrCreateConversation(evt), "")
+		return
+	}
+
+	a.log.Info("Transcribed voice note", "message_id", evt.Info.ID, "transcript", transcript)
+	a.routeMessage(evt, transcript)
+}
+
+// transcribeAudio downloads an audio message and returns its transcript
+func (a *WhatsAppAdapter) transcribeAudio(audioMsg *waProto.AudioMessage) (string, error) {
+	if a.speech == nil {
+		return "", fmt.Errorf("speech to text is not configured")
+	}
+	if audioMsg == nil {
+		return "", fmt.Errorf("no audio in message")
+	}
+
+	audio, err := a.client.Download(audioMsg)
+	if err != nil {
+		return "", fmt.Errorf("failed to download audio: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 180*time.Second)
+	defer cancel()
+
+	return a.speech.Transcribe(ctx, audio, audioMsg.GetMimetype())
+}
+
+// transcribeCommand replies with the transcript of the voice note being replied to
+func (a *WhatsAppAdapter) transcribeCommand() Command {
+	return Command{
+		Name:        "transcribe",
+		Usage:       "transcribe (reply to a voice note)",
+		Help:        "Write out what was said in a voice note",
+		Prefix:      "transcribe",
+		MentionOnly: true,
+		Priority:    95,
+		Enabled: func() bool {
+			return a.speech != nil
+		},
+		Handle: func(req *CommandRequest) {
+			a.processAndReplyWithTranscript(req.Event)
+		},
+	}
+}
+
+// processAndReplyWithTranscript transcribes the quoted voice note and sends the text back
+func (a *WhatsAppAdapter) processAndReplyWithTranscript(evt *events.Message) {
+	var audioMsg *waProto.AudioMessage
+	if contextInfo := a.getMessageContextInfo(evt); contextInfo != nil {
+		audioMsg = contextInfo.GetQuotedMessage().GetAudioMessage()
+	}
+	if audioMsg == nil {
+		a.sendReply("Please reply to a voice note with \"transcribe\" and I'll write it out.", evt)
+		return
+	}
+
+	transcript, err := a.transcribeAudio(audioMsg)
+	if err != nil {
+		a.log.Error("Failed to transcribe quoted voice note", "error", err)
+		a.sendReply("Sorry, I couldn't transcribe that voice note.", evt)
+		return
+	}
+	if transcript == "" {
+		a.sendReply("I couldn't hear any speech in that voice note.", evt)
+		return
+	}
+
+	a.sendReply(fmt.Sprintf("🎙️ _Transcript:_\n%s", transcript), evt)
+}
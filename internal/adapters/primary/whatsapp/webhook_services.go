@@ -0,0 +1,99 @@
+package whatsapp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/vibin/chat-bot/config"
+	"github.com/vibin/chat-bot/internal/core/ports"
+)
+
+// SetWebhookCaller sets the client used to call the configured webhook services
+func (a *WhatsAppAdapter) SetWebhookCaller(webhook ports.WebhookPort) {
+	a.webhook = webhook
+}
+
+// registerServiceCommands registers a command for each configured webhook service
+func (a *WhatsAppAdapter) registerServiceCommands() {
+	for _, service := range a.config.Services {
+		if service.Name == "" || service.Trigger == "" {
+			a.log.Warn("Skipping webhook service without a name or trigger", "name", service.Name)
+			continue
+		}
+		a.RegisterCommand(a.serviceCommand(service))
+	}
+}
+
+// serviceCommand forwards messages containing the service's trigger to its webhook
+func (a *WhatsAppAdapter) serviceCommand(service config.WebhookServiceConfig) Command {
+	help := service.Description
+	if help == "" {
+		help = fmt.Sprintf("Ask the %s service", service.Name)
+	}
+
+	return Command{
+		Name:        service.Name,
+		Usage:       service.Trigger + " <question>",
+		Help:        help,
+		Keywords:    []string{service.Trigger},
+		MentionOnly: service.MentionOnly,
+		Priority:    service.Priority,
+		Enabled: func() bool {
+			return service.Enabled && a.webhook != nil
+		},
+		Handle: func(req *CommandRequest) {
+			a.processAndReplyWithService(service, req)
+		},
+	}
+}
+
+// processAndReplyWithService sends a message to a webhook service and replies with its answer
+func (a *WhatsAppAdapter) processAndReplyWithService(service config.WebhookServiceConfig, req *CommandRequest) {
+	a.log.Info("Processing service request", "service", service.Name, "conversation_id", req.ConversationID)
+
+	evt := req.Event
+	userID := evt.Info.Sender.String()
+	chatInput := removeKeyword(req.CleanMessage, service.Trigger)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	memories := a.getRelevantMemories(ctx, userID, req.ConversationID, chatInput)
+	memoryContents := make([]string, len(memories))
+	for i, memory := range memories {
+		memoryContents[i] = memory.Content
+	}
+
+	reply, err := a.webhook.Call(ctx, service, ports.WebhookInput{
+		ChatInput: chatInput,
+		Sender:    evt.Info.PushName,
+		Group:     evt.Info.Chat.String(),
+		Memories:  memoryContents,
+	})
+	if errors.Is(err, ports.ErrServiceDown) {
+		a.log.Warn("Skipping request to a service that is down", "service", service.Name)
+		a.sendReply(fmt.Sprintf("The %s service seems to be down right now. Please try again in a few minutes.", service.Name), evt)
+		return
+	}
+	if err != nil {
+		a.log.Error("Webhook service request failed", "service", service.Name, "error", err)
+		a.sendReply(fmt.Sprintf("Sorry, I couldn't get an answer from the %s service. Please try again later.", service.Name), evt)
+		return
+	}
+
+	// Record the message in conversation history
+	a.recordMessage(req.ConversationID, fmt.Sprintf("User: %s", req.Message))
+	a.recordMessage(req.ConversationID, fmt.Sprintf("Bot: %s", reply))
+
+	a.sendReply(reply, evt)
+}
+
+// removeKeyword removes every occurrence of a keyword from a message, ignoring case
+func removeKeyword(message, keyword string) string {
+	pattern := regexp.MustCompile(`(?i)` + regexp.QuoteMeta(keyword))
+	return strings.TrimSpace(pattern.ReplaceAllString(message, ""))
+}
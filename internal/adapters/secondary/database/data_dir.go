@@ -0,0 +1,40 @@
+package database
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+// DataDir returns the directory used for persistent data files, creating it if needed
+// /app/data is the Docker volume from docker-compose.yml, ./data is used outside Docker
+func DataDir() (string, error) {
+	dataDir := "/app/data"
+
+	// Fallback to local ./data if running outside Docker
+	if _, err := os.Stat(dataDir); os.IsNotExist(err) {
+		dataDir = "./data"
+		log.Printf("INFO: Docker volume not found, using local data directory: %s", dataDir)
+	}
+
+	// Ensure data directory exists with proper permissions
+	if err := os.MkdirAll(dataDir, 0777); err != nil {
+		log.Printf("ERROR: Failed to create data directory: %v", err)
+		return "", fmt.Errorf("failed to create data directory: %w", err)
+	}
+
+	// Verify the directory exists and has write permissions
+	if _, err := os.Stat(dataDir); os.IsNotExist(err) {
+		log.Printf("ERROR: Data directory does not exist after creation attempt: %v", err)
+		return "", fmt.Errorf("data directory does not exist: %w", err)
+	}
+
+	// Log the full path for debugging
+	absPath, err := filepath.Abs(dataDir)
+	if err == nil {
+		log.Printf("INFO: Using data directory: %s", absPath)
+	}
+
+	return dataDir, nil
+}
@@ -1,6 +1,7 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"log"
@@ -9,6 +10,8 @@ import (
 	"sync"
 	"time"
 
+	"github.com/vibin/chat-bot/internal/metrics"
+
 	_ "github.com/mattn/go-sqlite3"
 )
 
@@ -32,31 +35,9 @@ type Memory struct {
 // NewMemoryDatabase creates a new memory database
 func NewMemoryDatabase() (*MemoryDatabase, error) {
 	// Use /app/data in Docker, which maps to the persistent volume in docker-compose.yml
-	// This is the directory that gets mounted as a volume in Docker
-	dataDir := "/app/data"
-	
-	// Fallback to local ./data if running outside Docker
-	if _, err := os.Stat(dataDir); os.IsNotExist(err) {
-		dataDir = "./data"
-		log.Printf("INFO: Docker volume not found, using local data directory: %s", dataDir)
-	}
-	
-	// Ensure data directory exists with proper permissions
-	if err := os.MkdirAll(dataDir, 0777); err != nil {
-		log.Printf("ERROR: Failed to create data directory: %v", err)
-		return nil, fmt.Errorf("failed to create data directory: %w", err)
-	}
-
-	// Verify the directory exists and has write permissions
-	if _, err := os.Stat(dataDir); os.IsNotExist(err) {
-		log.Printf("ERROR: Data directory does not exist after creation attempt: %v", err)
-		return nil, fmt.Errorf("data directory does not exist: %w", err)
-	}
-
-	// Log the full path for debugging
-	absPath, err := filepath.Abs(dataDir)
-	if err == nil {
-		log.Printf("INFO: Using data directory: %s", absPath)
+	dataDir, err := DataDir()
+	if err != nil {
+		return nil, err
 	}
 
 	// Open SQLite database with explicit journal mode and synchronous settings
@@ -136,7 +117,12 @@ func createSchema(db *sql.DB) error {
 		CREATE INDEX IF NOT EXISTS idx_memories_user_conversation 
 		ON memories(user_id, conversation_id)
 	`)
-	return err
+	if err != nil {
+		return err
+	}
+
+	// Add embedding columns to databases created before semantic retrieval
+	return ensureEmbeddingColumns(db)
 }
 
 // Close closes the database connection
@@ -144,8 +130,16 @@ func (m *MemoryDatabase) Close() error {
 	return m.db.Close()
 }
 
-// AddMemory adds a new memory to the database or updates an existing one
-func (m *MemoryDatabase) AddMemory(memory *Memory) error {
+// Ping checks that the database can be reached
+func (m *MemoryDatabase) Ping(ctx context.Context) error {
+	return m.db.PingContext(ctx)
+}
+
+// AddMemory adds a new memory to the database, or marks an existing one with the same content as used
+// It reports whether the memory was new. Existing rows keep their embedding and use count
+func (m *MemoryDatabase) AddMemory(memory *Memory) (inserted bool, err error) {
+	defer metrics.ObserveMemoryOperation("add", time.Now(), &err)
+
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 
@@ -158,9 +152,22 @@ func (m *MemoryDatabase) AddMemory(memory *Memory) error {
 		memory.LastUsed = now
 	}
 
-	// Use INSERT OR REPLACE to handle duplicates
+	// A duplicate only refreshes last_used, replacing the row would drop its embedding
+	var id int64
+	err = m.db.QueryRow(`
+		SELECT id FROM memories WHERE user_id = ? AND conversation_id = ? AND content = ?
+	`, memory.UserID, memory.ConversationID, memory.Content).Scan(&id)
+	if err == nil {
+		memory.ID = id
+		_, err = m.db.Exec(`UPDATE memories SET last_used = ? WHERE id = ?`, memory.LastUsed.Format(time.RFC3339), id)
+		return false, err
+	}
+	if err != sql.ErrNoRows {
+		return false, err
+	}
+
 	query := `
-		INSERT OR REPLACE INTO memories
+		INSERT INTO memories
 		(user_id, conversation_id, content, created_at, last_used, use_count)
 		VALUES (?, ?, ?, ?, ?, ?)
 	`
@@ -174,20 +181,21 @@ func (m *MemoryDatabase) AddMemory(memory *Memory) error {
 		memory.UseCount,
 	)
 	if err != nil {
-		return err
+		return false, err
 	}
 
-	// If this was an insert (not a replace), get the ID
-	id, err := result.LastInsertId()
+	id, err = result.LastInsertId()
 	if err == nil && id > 0 {
 		memory.ID = id
 	}
 
-	return nil
+	return true, nil
 }
 
 // GetMemories returns all memories for a conversation and user
-func (m *MemoryDatabase) GetMemories(userID, conversationID string) ([]*Memory, error) {
+func (m *MemoryDatabase) GetMemories(userID, conversationID string) (_ []*Memory, err error) {
+	defer metrics.ObserveMemoryOperation("get", time.Now(), &err)
+
 	m.mutex.RLock()
 	defer m.mutex.RUnlock()
 
@@ -235,7 +243,9 @@ func (m *MemoryDatabase) GetMemories(userID, conversationID string) ([]*Memory,
 }
 
 // GetAllMemoriesByConversation gets all memories for a specific conversation
-func (m *MemoryDatabase) GetAllMemoriesByConversation(conversationID string) ([]*Memory, error) {
+func (m *MemoryDatabase) GetAllMemoriesByConversation(conversationID string) (_ []*Memory, err error) {
+	defer metrics.ObserveMemoryOperation("get_by_conversation", time.Now(), &err)
+
 	m.mutex.RLock()
 	defer m.mutex.RUnlock()
 
@@ -283,7 +293,9 @@ func (m *MemoryDatabase) GetAllMemoriesByConversation(conversationID string) ([]
 }
 
 // GetMemoryUsers returns all users who have memories for a specific conversation
-func (m *MemoryDatabase) GetMemoryUsers(conversationID string) (map[string]int, error) {
+func (m *MemoryDatabase) GetMemoryUsers(conversationID string) (_ map[string]int, err error) {
+	defer metrics.ObserveMemoryOperation("get_users", time.Now(), &err)
+
 	m.mutex.RLock()
 	defer m.mutex.RUnlock()
 
@@ -313,7 +325,9 @@ func (m *MemoryDatabase) GetMemoryUsers(conversationID string) (map[string]int,
 }
 
 // UpdateMemory updates an existing memory
-func (m *MemoryDatabase) UpdateMemory(memory *Memory) error {
+func (m *MemoryDatabase) UpdateMemory(memory *Memory) (err error) {
+	defer metrics.ObserveMemoryOperation("update", time.Now(), &err)
+
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 
@@ -325,7 +339,7 @@ func (m *MemoryDatabase) UpdateMemory(memory *Memory) error {
 		SET content = ?, last_used = ?, use_count = ?
 		WHERE id = ?
 	`
-	_, err := m.db.Exec(
+	_, err = m.db.Exec(
 		query,
 		memory.Content,
 		memory.LastUsed.Format(time.RFC3339),
@@ -336,27 +350,33 @@ func (m *MemoryDatabase) UpdateMemory(memory *Memory) error {
 }
 
 // DeleteMemory deletes a memory by ID
-func (m *MemoryDatabase) DeleteMemory(id int64) error {
+func (m *MemoryDatabase) DeleteMemory(id int64) (err error) {
+	defer metrics.ObserveMemoryOperation("delete", time.Now(), &err)
+
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 
 	query := "DELETE FROM memories WHERE id = ?"
-	_, err := m.db.Exec(query, id)
+	_, err = m.db.Exec(query, id)
 	return err
 }
 
 // DeleteAllMemoriesForUser deletes all memories for a specific user in a conversation
-func (m *MemoryDatabase) DeleteAllMemoriesForUser(userID, conversationID string) error {
+func (m *MemoryDatabase) DeleteAllMemoriesForUser(userID, conversationID string) (err error) {
+	defer metrics.ObserveMemoryOperation("delete_for_user", time.Now(), &err)
+
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 
 	query := "DELETE FROM memories WHERE user_id = ? AND conversation_id = ?"
-	_, err := m.db.Exec(query, userID, conversationID)
+	_, err = m.db.Exec(query, userID, conversationID)
 	return err
 }
 
 // GetAllConversationsWithMemories gets a list of all conversation IDs that have memories
-func (m *MemoryDatabase) GetAllConversationsWithMemories() ([]string, error) {
+func (m *MemoryDatabase) GetAllConversationsWithMemories() (_ []string, err error) {
+	defer metrics.ObserveMemoryOperation("get_conversations", time.Now(), &err)
+
 	m.mutex.RLock()
 	defer m.mutex.RUnlock()
 
@@ -383,18 +403,22 @@ func (m *MemoryDatabase) GetAllConversationsWithMemories() ([]string, error) {
 }
 
 // GetMemoryCount returns the count of memories for a conversation
-func (m *MemoryDatabase) GetMemoryCount(conversationID string) (int, error) {
+func (m *MemoryDatabase) GetMemoryCount(conversationID string) (_ int, err error) {
+	defer metrics.ObserveMemoryOperation("count", time.Now(), &err)
+
 	m.mutex.RLock()
 	defer m.mutex.RUnlock()
 
 	var count int
 	query := "SELECT COUNT(*) FROM memories WHERE conversation_id = ?"
-	err := m.db.QueryRow(query, conversationID).Scan(&count)
+	err = m.db.QueryRow(query, conversationID).Scan(&count)
 	return count, err
 }
 
 // GetMemoryByID retrieves a memory by its ID
-func (m *MemoryDatabase) GetMemoryByID(id int64) (*Memory, error) {
+func (m *MemoryDatabase) GetMemoryByID(id int64) (_ *Memory, err error) {
+	defer metrics.ObserveMemoryOperation("get_by_id", time.Now(), &err)
+
 	m.mutex.RLock()
 	defer m.mutex.RUnlock()
 
@@ -406,7 +430,7 @@ func (m *MemoryDatabase) GetMemoryByID(id int64) (*Memory, error) {
 	var memory Memory
 	var createdAtStr, lastUsedStr string
 
-	err := m.db.QueryRow(query, id).Scan(
+	err = m.db.QueryRow(query, id).Scan(
 		&memory.ID,
 		&memory.UserID,
 		&memory.ConversationID,
@@ -432,7 +456,9 @@ func (m *MemoryDatabase) GetMemoryByID(id int64) (*Memory, error) {
 }
 
 // IncrementUseCount increments the use count for a memory
-func (m *MemoryDatabase) IncrementUseCount(id int64) error {
+func (m *MemoryDatabase) IncrementUseCount(id int64) (err error) {
+	defer metrics.ObserveMemoryOperation("increment_use", time.Now(), &err)
+
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 
@@ -442,6 +468,6 @@ func (m *MemoryDatabase) IncrementUseCount(id int64) error {
 		SET use_count = use_count + 1, last_used = ?
 		WHERE id = ?
 	`
-	_, err := m.db.Exec(query, now.Format(time.RFC3339), id)
+	_, err = m.db.Exec(query, now.Format(time.RFC3339), id)
 	return err
 }
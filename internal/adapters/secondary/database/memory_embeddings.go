@@ -0,0 +1,243 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/binary"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/vibin/chat-bot/internal/core/domain"
+	"github.com/vibin/chat-bot/internal/metrics"
+)
+
+// ScoredMemory is a memory with its similarity to a search query
+type ScoredMemory struct {
+	*Memory
+	Score float64
+}
+
+// ensureEmbeddingColumns adds the embedding columns to memory tables created before they existed
+func ensureEmbeddingColumns(db *sql.DB) error {
+	rows, err := db.Query("PRAGMA table_info(memories)")
+	if err != nil {
+		return err
+	}
+
+	columns := make(map[string]bool)
+	for rows.Next() {
+		var cid, notNull, pk int
+		var name, colType string
+		var defaultValue sql.NullString
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &defaultValue, &pk); err != nil {
+			rows.Close()
+			return err
+		}
+		columns[name] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if !columns["embedding"] {
+		log.Printf("INFO: Adding embedding column to memories table")
+		if _, err := db.Exec("ALTER TABLE memories ADD COLUMN embedding BLOB"); err != nil {
+			return err
+		}
+	}
+	if !columns["embedding_model"] {
+		if _, err := db.Exec("ALTER TABLE memories ADD COLUMN embedding_model TEXT NOT NULL DEFAULT ''"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SetMemoryEmbedding stores the embedding vector of a memory and the model that produced it
+// The vector is normalized so similarity search only needs a dot product
+func (m *MemoryDatabase) SetMemoryEmbedding(id int64, model string, vector []float32) (err error) {
+	defer metrics.ObserveMemoryOperation("set_embedding", time.Now(), &err)
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	_, err = m.db.Exec(
+		"UPDATE memories SET embedding = ?, embedding_model = ? WHERE id = ?",
+		encodeVector(domain.NormalizeVector(vector)),
+		model,
+		id,
+	)
+	return err
+}
+
+// GetMemoriesWithoutEmbedding returns memories of a user that have no embedding from the given model
+func (m *MemoryDatabase) GetMemoriesWithoutEmbedding(userID, conversationID, model string, limit int) (_ []*Memory, err error) {
+	defer metrics.ObserveMemoryOperation("get_without_embedding", time.Now(), &err)
+
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	query := `
+		SELECT id, user_id, conversation_id, content, created_at, last_used, use_count
+		FROM memories
+		WHERE user_id = ? AND conversation_id = ? AND (embedding IS NULL OR embedding_model != ?)
+		LIMIT ?
+	`
+	rows, err := m.db.Query(query, userID, conversationID, model, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var memories []*Memory
+	for rows.Next() {
+		var memory Memory
+		var createdAtStr, lastUsedStr string
+
+		if err := rows.Scan(
+			&memory.ID,
+			&memory.UserID,
+			&memory.ConversationID,
+			&memory.Content,
+			&createdAtStr,
+			&lastUsedStr,
+			&memory.UseCount,
+		); err != nil {
+			return nil, err
+		}
+
+		memory.CreatedAt, _ = time.Parse(time.RFC3339, createdAtStr)
+		memory.LastUsed, _ = time.Parse(time.RFC3339, lastUsedStr)
+
+		memories = append(memories, &memory)
+	}
+
+	return memories, rows.Err()
+}
+
+// SearchMemories returns the k memories of a user most similar to the query vector
+// It is a brute-force cosine search over the stored vectors, which only keeps the
+// current top k in memory and comfortably handles tens of thousands of rows
+func (m *MemoryDatabase) SearchMemories(userID, conversationID, model string, query []float32, k int) (_ []*ScoredMemory, err error) {
+	defer metrics.ObserveMemoryOperation("search", time.Now(), &err)
+
+	if k <= 0 {
+		return nil, nil
+	}
+
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	rows, err := m.db.Query(`
+		SELECT id, embedding
+		FROM memories
+		WHERE user_id = ? AND conversation_id = ? AND embedding IS NOT NULL AND embedding_model = ?
+	`, userID, conversationID, model)
+	if err != nil {
+		return nil, err
+	}
+
+	query = domain.NormalizeVector(query)
+
+	type scoredID struct {
+		id    int64
+		score float64
+	}
+	top := make([]scoredID, 0, k+1)
+
+	for rows.Next() {
+		var id int64
+		var blob []byte
+		if err := rows.Scan(&id, &blob); err != nil {
+			rows.Close()
+			return nil, err
+		}
+
+		score, ok := dotEncoded(query, blob)
+		if !ok {
+			continue
+		}
+		if len(top) == k && score <= top[k-1].score {
+			continue
+		}
+
+		// Insert into the sorted top k
+		pos := sort.Search(len(top), func(i int) bool { return top[i].score < score })
+		top = append(top, scoredID{})
+		copy(top[pos+1:], top[pos:])
+		top[pos] = scoredID{id: id, score: score}
+		if len(top) > k {
+			top = top[:k]
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	results := make([]*ScoredMemory, 0, len(top))
+	for _, candidate := range top {
+		memory, err := m.getMemoryByIDLocked(candidate.id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load memory %d: %w", candidate.id, err)
+		}
+		results = append(results, &ScoredMemory{Memory: memory, Score: candidate.score})
+	}
+
+	return results, nil
+}
+
+// getMemoryByIDLocked loads a memory by ID while the caller holds the mutex
+func (m *MemoryDatabase) getMemoryByIDLocked(id int64) (*Memory, error) {
+	var memory Memory
+	var createdAtStr, lastUsedStr string
+
+	err := m.db.QueryRow(`
+		SELECT id, user_id, conversation_id, content, created_at, last_used, use_count
+		FROM memories
+		WHERE id = ?
+	`, id).Scan(
+		&memory.ID,
+		&memory.UserID,
+		&memory.ConversationID,
+		&memory.Content,
+		&createdAtStr,
+		&lastUsedStr,
+		&memory.UseCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	memory.CreatedAt, _ = time.Parse(time.RFC3339, createdAtStr)
+	memory.LastUsed, _ = time.Parse(time.RFC3339, lastUsedStr)
+
+	return &memory, nil
+}
+
+// encodeVector encodes a vector as little-endian float32 values
+func encodeVector(vector []float32) []byte {
+	buf := make([]byte, 4*len(vector))
+	for i, v := range vector {
+		binary.LittleEndian.PutUint32(buf[4*i:], math.Float32bits(v))
+	}
+	return buf
+}
+
+// dotEncoded computes the dot product of a vector with an encoded vector without decoding it first
+// It reports false if the dimensions differ
+func dotEncoded(vector []float32, blob []byte) (float64, bool) {
+	if len(blob) != 4*len(vector) {
+		return 0, false
+	}
+
+	var dot float64
+	for i, v := range vector {
+		dot += float64(v) * float64(math.Float32frombits(binary.LittleEndian.Uint32(blob[4*i:])))
+	}
+	return dot, true
+}
@@ -0,0 +1,102 @@
+package documents
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ledongthuc/pdf"
+	"github.com/vibin/chat-bot/internal/core/domain"
+)
+
+// textExtensions are plain text formats that are read as is
+var textExtensions = map[string]bool{
+	".txt":      true,
+	".md":       true,
+	".markdown": true,
+}
+
+// TextExtractor implements the DocumentExtractorPort interface for PDF, plain text and Markdown files
+type TextExtractor struct{}
+
+// NewTextExtractor creates a new TextExtractor
+func NewTextExtractor() *TextExtractor {
+	return &TextExtractor{}
+}
+
+// Supports reports whether files of this type can be extracted
+func (e *TextExtractor) Supports(mimeType, fileName string) bool {
+	return isPDF(mimeType, fileName) || isText(mimeType, fileName)
+}
+
+// Extract returns the text of a file page by page
+// Plain text files have no pages and are returned as a single page numbered 0
+func (e *TextExtractor) Extract(data []byte, mimeType, fileName string) ([]domain.DocumentPage, error) {
+	switch {
+	case isPDF(mimeType, fileName):
+		return extractPDF(data)
+	case isText(mimeType, fileName):
+		if !utf8.Valid(data) {
+			return nil, fmt.Errorf("%s is not valid UTF-8 text", fileName)
+		}
+		return []domain.DocumentPage{{Number: 0, Text: string(data)}}, nil
+	default:
+		return nil, fmt.Errorf("unsupported document type: %s", mimeType)
+	}
+}
+
+// extractPDF extracts the plain text of every page of a PDF
+func extractPDF(data []byte) (pages []domain.DocumentPage, err error) {
+	// The PDF parser panics on some malformed files
+	defer func() {
+		if r := recover(); r != nil {
+			pages = nil
+			err = fmt.Errorf("failed to parse PDF: %v", r)
+		}
+	}()
+
+	reader, err := pdf.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open PDF: %w", err)
+	}
+
+	for i := 1; i <= reader.NumPage(); i++ {
+		page := reader.Page(i)
+		if page.V.IsNull() {
+			continue
+		}
+
+		text, err := page.GetPlainText(nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read page %d: %w", i, err)
+		}
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+
+		pages = append(pages, domain.DocumentPage{Number: i, Text: text})
+	}
+
+	if len(pages) == 0 {
+		return nil, fmt.Errorf("the PDF contains no extractable text, it may be a scan")
+	}
+
+	return pages, nil
+}
+
+// isPDF checks the MIME type and file extension for a PDF
+func isPDF(mimeType, fileName string) bool {
+	return strings.HasPrefix(strings.ToLower(mimeType), "application/pdf") ||
+		strings.ToLower(filepath.Ext(fileName)) == ".pdf"
+}
+
+// isText checks the MIME type and file extension for plain text or Markdown
+func isText(mimeType, fileName string) bool {
+	mimeType = strings.ToLower(mimeType)
+	if strings.HasPrefix(mimeType, "text/plain") || strings.HasPrefix(mimeType, "text/markdown") {
+		return true
+	}
+	return textExtensions[strings.ToLower(filepath.Ext(fileName))]
+}
@@ -10,6 +10,7 @@ import (
 	"net/http"
 	"regexp"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/tmc/langchaingo/llms"
@@ -17,6 +18,7 @@ import (
 	"github.com/vibin/chat-bot/config"
 	"github.com/vibin/chat-bot/internal/core/domain"
 	"github.com/vibin/chat-bot/internal/logger"
+	"github.com/vibin/chat-bot/internal/metrics"
 )
 
 // OllamaAdapter implements the LLMPort interface for the Ollama LLM provider
@@ -24,6 +26,8 @@ type OllamaAdapter struct {
 	client *ollama.LLM
 	config *config.LLMConfig
 	logger logger.Logger
+	mutex  sync.RWMutex
+	model  string // Starts as the configured model and can be switched at runtime
 }
 
 // NewOllamaAdapter creates a new OllamaAdapter
@@ -44,9 +48,24 @@ func NewOllamaAdapter(config *config.LLMConfig, log logger.Logger) (*OllamaAdapt
 		client: client,
 		config: config,
 		logger: log,
+		model:  config.Ollama.Model,
 	}, nil
 }
 
+// ModelName returns the model requests are sent to
+func (a *OllamaAdapter) ModelName() string {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+	return a.model
+}
+
+// SetModel switches the model for new requests
+func (a *OllamaAdapter) SetModel(name string) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.model = name
+}
+
 // cleanThinkingTags removes empty thinking tags from the response
 func cleanThinkingTags(input string) string {
 	// Regular expression to match empty thinking tags: <think></think> or <think> </think>
@@ -59,7 +78,15 @@ func cleanThinkingTags(input string) string {
 
 // GenerateResponse generates a response from the LLM for a given chat history
 func (a *OllamaAdapter) GenerateResponse(ctx context.Context, messages []domain.Message) (string, error) {
-	model := a.config.Ollama.Model
+	start := time.Now()
+	response, err := a.generateResponse(ctx, messages)
+	metrics.ObserveLLMRequest(a.ModelName(), "generate", start, err)
+	return response, err
+}
+
+// generateResponse does the work of GenerateResponse
+func (a *OllamaAdapter) generateResponse(ctx context.Context, messages []domain.Message) (string, error) {
+	model := a.ModelName()
 	a.logger.Info("Generating response with Ollama", "model", model)
 	
 	// Special handling for image analysis requests
@@ -71,10 +98,11 @@ func (a *OllamaAdapter) GenerateResponse(ctx context.Context, messages []domain.
 	
 	// For regular text messages, use the LangChain client
 	// Convert domain messages to LangChain messages
-	prompt := formatMessagesAsPrompt(messages, model, a.config.EnableReasoning)
+	prompt := formatMessagesAsPrompt(messages, model, a.config.EnableReasoning, systemPrompt(ctx))
 	
 	// Set generation options
 	opts := []llms.CallOption{
+		llms.WithModel(model),
 		llms.WithMaxTokens(a.config.Ollama.MaxTokens),
 		llms.WithTemperature(0.7),
 	}
@@ -84,11 +112,19 @@ func (a *OllamaAdapter) GenerateResponse(ctx context.Context, messages []domain.
 	defer cancel()
 	
 	// Generate completion
-	result, err := a.client.Call(timeoutCtx, prompt, opts...)
+	completion, err := a.client.GenerateContent(timeoutCtx, []llms.MessageContent{llms.TextParts(llms.ChatMessageTypeHuman, prompt)}, opts...)
 	if err != nil {
 		a.logger.Error("Ollama generation failed", "error", err)
 		return "", err
 	}
+	if len(completion.Choices) == 0 {
+		return "", fmt.Errorf("ollama returned no choices")
+	}
+	choice := completion.Choices[0]
+	promptTokens, _ := choice.GenerationInfo["PromptTokens"].(int)
+	completionTokens, _ := choice.GenerationInfo["CompletionTokens"].(int)
+	metrics.AddLLMTokens(model, promptTokens, completionTokens)
+	result := choice.Content
 	
 	// Process result based on model and reasoning settings
 	if strings.HasPrefix(model, "qwen3") && !a.config.EnableReasoning {
@@ -101,6 +137,7 @@ func (a *OllamaAdapter) GenerateResponse(ctx context.Context, messages []domain.
 
 // generateImageAnalysis handles image analysis requests by making direct API calls to Ollama
 func (a *OllamaAdapter) generateImageAnalysis(ctx context.Context, message domain.Message) (string, error) {
+	model := a.ModelName()
 	a.logger.Info("Processing image analysis using direct API call", "image_size", len(message.Images[0]))
 	
 	// Create a clear prompt for image analysis with formatting instructions for WhatsApp
@@ -149,14 +186,14 @@ func (a *OllamaAdapter) generateImageAnalysis(ctx context.Context, message domai
 		"base64_length", len(imageData),
 		"base64_prefix", base64Sample,
 		"checksum_first_8_bytes", checksum,
-		"model", a.config.Ollama.Model)
+		"model", model)
 
 	// Create a timestamp to prevent caching
 	timestamp := time.Now().UnixNano()
 	
 	// Create the request with system message and user message in chat format
 	request := ollamaRequest{
-		Model: a.config.Ollama.Model,
+		Model: model,
 		Messages: []ollamaMessage{
 			{
 				Role:    "user",
@@ -172,7 +209,7 @@ func (a *OllamaAdapter) generateImageAnalysis(ctx context.Context, message domai
 		Stream: false,
 	}
 
-	a.logger.Info("Sending unique request", "timestamp", timestamp, "model", a.config.Ollama.Model)
+	a.logger.Info("Sending unique request", "timestamp", timestamp, "model", model)
 	
 	// Marshal the request to JSON
 	requestJSON, err := json.Marshal(request)
@@ -256,6 +293,8 @@ func (a *OllamaAdapter) generateImageAnalysis(ctx context.Context, message domai
 	// Parse according to the actual Ollama API response format
 	var apiResp ollamaApiResponse
 	if err := json.Unmarshal(body, &apiResp); err == nil && apiResp.Message.Content != "" {
+		metrics.AddLLMTokens(model, apiResp.PromptEvalCount, apiResp.EvalCount)
+		
 		// Successfully parsed response
 		a.logger.Info("Parsed LLM API response", 
 			"model", apiResp.Model,
@@ -328,19 +367,32 @@ func (a *OllamaAdapter) generateImageAnalysis(ctx context.Context, message domai
 	return result, nil
 }
 
-// GetModelInfo returns information about the current LLM model
-func (a *OllamaAdapter) GetModelInfo(ctx context.Context) (map[string]interface{}, error) {
-	a.logger.Info("Getting model info for Ollama", "model", a.config.Ollama.Model)
-	
-	// In a real implementation, we would call the Ollama API to get model info
-	// For now, return static info
-	return map[string]interface{}{
-		"name":            a.config.Ollama.Model,
-		"provider":        "ollama",
-		"endpoint":        a.config.Ollama.Endpoint,
-		"maxTokens":       a.config.Ollama.MaxTokens,
-		"enableReasoning": a.config.EnableReasoning,
-	}, nil
+// Ping checks that Ollama is reachable by listing its models
+func (a *OllamaAdapter) Ping(ctx context.Context) error {
+	return ping(ctx, a.config.Ollama.Endpoint+"/api/tags", "")
+}
+
+// ping sends a GET request and expects a 200 response
+func ping(ctx context.Context, url, apiKey string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("received status %s", resp.Status)
+	}
+	return nil
 }
 
 // removeBase64References removes references to base64 encoding from the response
@@ -377,7 +429,7 @@ func removeBase64References(text string) string {
 }
 
 // formatMessagesAsPrompt converts a slice of domain messages to a prompt string for Ollama
-func formatMessagesAsPrompt(messages []domain.Message, model string, enableReasoning bool) string {
+func formatMessagesAsPrompt(messages []domain.Message, model string, enableReasoning bool, systemPrompt string) string {
 	// Special handling for image analysis
 	for _, msg := range messages {
 		if msg.Type == domain.MessageTypeImageAnalysis && len(msg.Images) > 0 {
@@ -440,7 +492,7 @@ func formatMessagesAsPrompt(messages []domain.Message, model string, enableReaso
 	// Add system message
 	chatMessages = append(chatMessages, chatMessage{
 		Role:    "system",
-		Content: "You are a helpful assistant. Keep your responses concise and to the point unless the user specifically asks for detailed explanations or descriptions.",
+		Content: systemPrompt,
 	})
 	
 	// Convert domain messages to chat messages
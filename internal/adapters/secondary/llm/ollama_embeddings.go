@@ -0,0 +1,103 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/vibin/chat-bot/config"
+	"github.com/vibin/chat-bot/internal/logger"
+)
+
+// OllamaEmbeddingAdapter implements the EmbeddingPort interface using Ollama's /api/embeddings endpoint
+type OllamaEmbeddingAdapter struct {
+	httpClient *http.Client
+	config     *config.EmbeddingsConfig
+	logger     logger.Logger
+}
+
+// ollamaEmbeddingRequest is the request body for /api/embeddings
+type ollamaEmbeddingRequest struct {
+	Model  string `json:"model"`
+	Prompt string `json:"prompt"`
+}
+
+// ollamaEmbeddingResponse is the response body of /api/embeddings
+type ollamaEmbeddingResponse struct {
+	Embedding []float32 `json:"embedding"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// NewOllamaEmbeddingAdapter creates a new OllamaEmbeddingAdapter
+func NewOllamaEmbeddingAdapter(config *config.EmbeddingsConfig, log logger.Logger) *OllamaEmbeddingAdapter {
+	log.Info("Initializing Ollama embedding adapter", "endpoint", config.Endpoint, "model", config.Model)
+
+	timeout := config.TimeoutSeconds * time.Second
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+
+	return &OllamaEmbeddingAdapter{
+		httpClient: &http.Client{Timeout: timeout},
+		config:     config,
+		logger:     log,
+	}
+}
+
+// Embed returns the embedding vector of a text
+func (a *OllamaEmbeddingAdapter) Embed(ctx context.Context, text string) ([]float32, error) {
+	requestJSON, err := json.Marshal(ollamaEmbeddingRequest{
+		Model:  a.config.Model,
+		Prompt: text,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	url := strings.TrimSuffix(a.config.Endpoint, "/") + "/api/embeddings"
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(requestJSON))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := a.httpClient.Do(httpReq)
+	if err != nil {
+		a.logger.Error("Failed to send embedding request", "error", err)
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		a.logger.Error("Received error response", "status", resp.Status, "body", string(body))
+		return nil, fmt.Errorf("received error response: %s", resp.Status)
+	}
+
+	var embedding ollamaEmbeddingResponse
+	if err := json.Unmarshal(body, &embedding); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	if embedding.Error != "" {
+		return nil, fmt.Errorf("ollama error: %s", embedding.Error)
+	}
+	if len(embedding.Embedding) == 0 {
+		return nil, fmt.Errorf("ollama returned an empty embedding")
+	}
+
+	return embedding.Embedding, nil
+}
+
+// ModelName returns the embedding model name
+func (a *OllamaEmbeddingAdapter) ModelName() string {
+	return a.config.Model
+}
@@ -0,0 +1,312 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/vibin/chat-bot/internal/core/domain"
+)
+
+// ollamaModelDetails is the details object of Ollama's model endpoints
+type ollamaModelDetails struct {
+	Format            string   `json:"format"`
+	Family            string   `json:"family"`
+	Families          []string `json:"families"`
+	ParameterSize     string   `json:"parameter_size"`
+	QuantizationLevel string   `json:"quantization_level"`
+}
+
+// ollamaModel is a model in the /api/tags and /api/ps responses
+type ollamaModel struct {
+	Name       string             `json:"name"`
+	Model      string             `json:"model"`
+	ModifiedAt time.Time          `json:"modified_at"`
+	Size       int64              `json:"size"`
+	Details    ollamaModelDetails `json:"details"`
+}
+
+// ollamaModelList is the response of /api/tags and /api/ps
+type ollamaModelList struct {
+	Models []ollamaModel `json:"models"`
+}
+
+// ollamaShowResponse is the response of /api/show
+type ollamaShowResponse struct {
+	Template      string                 `json:"template"`
+	Details       ollamaModelDetails     `json:"details"`
+	ModelInfo     map[string]interface{} `json:"model_info"`
+	ProjectorInfo map[string]interface{} `json:"projector_info"`
+	Capabilities  []string               `json:"capabilities"` // Only sent by recent Ollama versions
+	ModifiedAt    time.Time              `json:"modified_at"`
+}
+
+// ollamaPullProgress is one line of a streamed /api/pull response
+type ollamaPullProgress struct {
+	Status    string `json:"status"`
+	Total     int64  `json:"total"`
+	Completed int64  `json:"completed"`
+	Error     string `json:"error"`
+}
+
+// GetModelInfo returns the current model's details from Ollama's /api/show and /api/tags
+// The configured settings are still returned when the server can't be reached
+func (a *OllamaAdapter) GetModelInfo(ctx context.Context) (map[string]interface{}, error) {
+	model := a.ModelName()
+	a.logger.Info("Getting model info for Ollama", "model", model)
+
+	info := map[string]interface{}{
+		"name":            model,
+		"provider":        "ollama",
+		"endpoint":        a.config.Ollama.Endpoint,
+		"maxTokens":       a.config.Ollama.MaxTokens,
+		"enableReasoning": a.config.EnableReasoning,
+	}
+
+	details, err := a.ShowModel(ctx, model)
+	if err != nil {
+		a.logger.Warn("Failed to get Ollama model details", "model", model, "error", err)
+		return info, nil
+	}
+	info["contextLength"] = details.ContextLength
+	info["parameterSize"] = details.ParameterSize
+	info["quantization"] = details.Quantization
+	info["family"] = details.Family
+	info["families"] = details.Families
+	info["capabilities"] = details.Capabilities
+
+	// Only the model list has the size and whether the model is loaded
+	models, err := a.ListModels(ctx)
+	if err != nil {
+		a.logger.Warn("Failed to list Ollama models", "error", err)
+		return info, nil
+	}
+	for _, m := range models {
+		if sameModel(m.Name, model) {
+			info["size"] = m.Size
+			info["loaded"] = m.Loaded
+			break
+		}
+	}
+	return info, nil
+}
+
+// ListModels returns the models installed on the Ollama server and whether each is loaded
+func (a *OllamaAdapter) ListModels(ctx context.Context) ([]domain.ModelDetails, error) {
+	var installed ollamaModelList
+	if err := a.ollamaRequest(ctx, http.MethodGet, "/api/tags", nil, &installed); err != nil {
+		return nil, fmt.Errorf("failed to list models: %w", err)
+	}
+
+	// Loaded models are optional, older servers have no /api/ps
+	loaded := make(map[string]bool)
+	var running ollamaModelList
+	if err := a.ollamaRequest(ctx, http.MethodGet, "/api/ps", nil, &running); err == nil {
+		for _, m := range running.Models {
+			loaded[m.Name] = true
+		}
+	}
+
+	models := make([]domain.ModelDetails, 0, len(installed.Models))
+	for _, m := range installed.Models {
+		models = append(models, domain.ModelDetails{
+			Name:          m.Name,
+			Size:          m.Size,
+			ModifiedAt:    m.ModifiedAt,
+			Family:        m.Details.Family,
+			Families:      m.Details.Families,
+			ParameterSize: m.Details.ParameterSize,
+			Quantization:  m.Details.QuantizationLevel,
+			Loaded:        loaded[m.Name],
+		})
+	}
+	return models, nil
+}
+
+// ShowModel returns a model's details, context length and capabilities
+func (a *OllamaAdapter) ShowModel(ctx context.Context, name string) (domain.ModelDetails, error) {
+	var show ollamaShowResponse
+	if err := a.ollamaRequest(ctx, http.MethodPost, "/api/show", map[string]string{"model": name}, &show); err != nil {
+		return domain.ModelDetails{}, fmt.Errorf("failed to show model %s: %w", name, err)
+	}
+
+	return domain.ModelDetails{
+		Name:          name,
+		ModifiedAt:    show.ModifiedAt,
+		Family:        show.Details.Family,
+		Families:      show.Details.Families,
+		ParameterSize: show.Details.ParameterSize,
+		Quantization:  show.Details.QuantizationLevel,
+		ContextLength: contextLength(show.ModelInfo),
+		Capabilities:  capabilities(show),
+	}, nil
+}
+
+// PullModel downloads a model, calling onProgress with each line of the streamed status
+func (a *OllamaAdapter) PullModel(ctx context.Context, name string, onProgress func(status string, completed, total int64)) error {
+	a.logger.Info("Pulling Ollama model", "model", name)
+
+	body, err := json.Marshal(map[string]interface{}{"model": name, "stream": true})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.config.Ollama.Endpoint+"/api/pull", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return ollamaStatusError(resp)
+	}
+
+	// Ollama streams newline-delimited JSON objects
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var progress ollamaPullProgress
+		if err := json.Unmarshal(line, &progress); err != nil {
+			continue
+		}
+		if progress.Error != "" {
+			return fmt.Errorf("ollama pull error: %s", progress.Error)
+		}
+		if onProgress != nil {
+			onProgress(progress.Status, progress.Completed, progress.Total)
+		}
+		if progress.Status == "success" {
+			a.logger.Info("Pulled Ollama model", "model", name)
+			return nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read pull progress: %w", err)
+	}
+	return fmt.Errorf("pull of %s ended without success", name)
+}
+
+// UnloadModel asks Ollama to free a model's memory by setting its keep alive to zero
+func (a *OllamaAdapter) UnloadModel(ctx context.Context, name string) error {
+	a.logger.Info("Unloading Ollama model", "model", name)
+
+	request := map[string]interface{}{"model": name, "keep_alive": 0}
+	if err := a.ollamaRequest(ctx, http.MethodPost, "/api/generate", request, nil); err != nil {
+		return fmt.Errorf("failed to unload model %s: %w", name, err)
+	}
+	return nil
+}
+
+// ollamaRequest sends a JSON request to an Ollama endpoint and decodes the response into out, if set
+func (a *OllamaAdapter) ollamaRequest(ctx context.Context, method, path string, request, out interface{}) error {
+	var body io.Reader
+	if request != nil {
+		data, err := json.Marshal(request)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, a.config.Ollama.Endpoint+path, body)
+	if err != nil {
+		return err
+	}
+	if request != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return ollamaStatusError(resp)
+	}
+	if out == nil {
+		io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// ollamaStatusError returns the error of a non-200 Ollama response
+func ollamaStatusError(resp *http.Response) error {
+	var body struct {
+		Error string `json:"error"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if json.Unmarshal(data, &body) == nil && body.Error != "" {
+		return fmt.Errorf("received status %s: %s", resp.Status, body.Error)
+	}
+	return fmt.Errorf("received status %s", resp.Status)
+}
+
+// contextLength reads the context length from /api/show model info, where it is keyed by architecture
+// such as "llama.context_length"
+func contextLength(modelInfo map[string]interface{}) int {
+	for key, value := range modelInfo {
+		if !strings.HasSuffix(key, ".context_length") {
+			continue
+		}
+		if length, ok := value.(float64); ok {
+			return int(length)
+		}
+	}
+	return 0
+}
+
+// capabilities returns what a model can do
+// Older Ollama versions don't report capabilities, so they are inferred from the vision
+// projector and whether the prompt template renders tools
+func capabilities(show ollamaShowResponse) []string {
+	if len(show.Capabilities) > 0 {
+		return show.Capabilities
+	}
+
+	result := []string{"completion"}
+	vision := len(show.ProjectorInfo) > 0
+	for _, family := range show.Details.Families {
+		if family == "clip" || family == "mllama" {
+			vision = true
+		}
+	}
+	if vision {
+		result = append(result, "vision")
+	}
+	if strings.Contains(show.Template, ".Tools") {
+		result = append(result, "tools")
+	}
+	return result
+}
+
+// sameModel reports whether two Ollama model names refer to the same model
+// A name without a tag means the "latest" tag
+func sameModel(a, b string) bool {
+	return withTag(a) == withTag(b)
+}
+
+// withTag adds the "latest" tag to a model name without one
+func withTag(name string) string {
+	if strings.Contains(name, ":") {
+		return name
+	}
+	return name + ":latest"
+}
@@ -0,0 +1,263 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/vibin/chat-bot/internal/core/domain"
+	"github.com/vibin/chat-bot/internal/core/ports"
+	"github.com/vibin/chat-bot/internal/metrics"
+)
+
+// defaultSystemPrompt is the system message prepended to every text conversation
+const defaultSystemPrompt = "You are a helpful assistant. Keep your responses concise and to the point unless the user specifically asks for detailed explanations or descriptions."
+
+// systemPrompt returns the system prompt set on the context, or the default prompt
+func systemPrompt(ctx context.Context) string {
+	if prompt := ports.SystemPromptFrom(ctx); prompt != "" {
+		return prompt
+	}
+	return defaultSystemPrompt
+}
+
+// ollamaChatMessage is a single message in Ollama's /api/chat format
+type ollamaChatMessage struct {
+	Role      string           `json:"role"`
+	Content   string           `json:"content"`
+	Images    []string         `json:"images,omitempty"`
+	ToolCalls []ollamaToolCall `json:"tool_calls,omitempty"`
+	ToolName  string           `json:"tool_name,omitempty"`
+}
+
+// ollamaChatRequest is the request body for Ollama's /api/chat endpoint
+type ollamaChatRequest struct {
+	Model    string                 `json:"model"`
+	Messages []ollamaChatMessage    `json:"messages"`
+	Stream   bool                   `json:"stream"`
+	Options  map[string]interface{} `json:"options,omitempty"`
+}
+
+// ollamaChatChunk is one line of a streamed /api/chat response
+type ollamaChatChunk struct {
+	Model           string            `json:"model"`
+	CreatedAt       string            `json:"created_at"`
+	Message         ollamaChatMessage `json:"message"`
+	Done            bool              `json:"done"`
+	Error           string            `json:"error,omitempty"`
+	PromptEvalCount int               `json:"prompt_eval_count"`
+	EvalCount       int               `json:"eval_count"`
+}
+
+// GenerateResponseStream generates a response from Ollama and calls onToken with each token delta
+func (a *OllamaAdapter) GenerateResponseStream(ctx context.Context, messages []domain.Message, onToken func(token string)) (string, error) {
+	start := time.Now()
+	response, err := a.generateResponseStream(ctx, messages, onToken)
+	metrics.ObserveLLMRequest(a.ModelName(), "stream", start, err)
+	return response, err
+}
+
+// generateResponseStream does the work of GenerateResponseStream
+func (a *OllamaAdapter) generateResponseStream(ctx context.Context, messages []domain.Message, onToken func(token string)) (string, error) {
+	model := a.ModelName()
+	a.logger.Info("Streaming response with Ollama", "model", model)
+
+	request := ollamaChatRequest{
+		Model:    model,
+		Messages: buildChatMessages(messages, model, a.config.EnableReasoning, systemPrompt(ctx)),
+		Stream:   true,
+		Options: map[string]interface{}{
+			"temperature": 0.7,
+			"num_predict": a.config.Ollama.MaxTokens,
+		},
+	}
+
+	requestJSON, err := json.Marshal(request)
+	if err != nil {
+		a.logger.Error("Failed to marshal stream request", "error", err)
+		return "", err
+	}
+
+	// Create a timeout context covering the whole stream
+	timeoutCtx, cancel := context.WithTimeout(ctx, time.Duration(a.config.Ollama.TimeoutSeconds)*time.Second)
+	defer cancel()
+
+	url := fmt.Sprintf("%s/api/chat", a.config.Ollama.Endpoint)
+	httpReq, err := http.NewRequestWithContext(timeoutCtx, "POST", url, bytes.NewBuffer(requestJSON))
+	if err != nil {
+		a.logger.Error("Failed to create HTTP request", "error", err)
+		return "", fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		a.logger.Error("Failed to send stream request", "error", err)
+		return "", fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		a.logger.Error("Received error response", "status", resp.Status, "body", string(body))
+		return "", fmt.Errorf("received error response: %s", resp.Status)
+	}
+
+	// qwen3 emits an empty <think></think> block first when reasoning is disabled
+	filter := &thinkFilter{active: strings.HasPrefix(model, "qwen3") && !a.config.EnableReasoning}
+
+	var full strings.Builder
+	emit := func(delta string) {
+		if delta == "" {
+			return
+		}
+		full.WriteString(delta)
+		if onToken != nil {
+			onToken(delta)
+		}
+	}
+
+	// Ollama streams newline-delimited JSON objects
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var chunk ollamaChatChunk
+		if err := json.Unmarshal(line, &chunk); err != nil {
+			a.logger.Warn("Failed to parse stream chunk", "error", err, "chunk", string(line))
+			continue
+		}
+
+		if chunk.Error != "" {
+			a.logger.Error("Ollama stream returned error", "error", chunk.Error)
+			return full.String(), fmt.Errorf("ollama stream error: %s", chunk.Error)
+		}
+
+		emit(filter.push(chunk.Message.Content))
+
+		if chunk.Done {
+			a.logger.Info("Ollama stream finished",
+				"model", chunk.Model,
+				"prompt_eval_count", chunk.PromptEvalCount,
+				"eval_count", chunk.EvalCount)
+			metrics.AddLLMTokens(model, chunk.PromptEvalCount, chunk.EvalCount)
+			break
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		a.logger.Error("Failed to read stream", "error", err)
+		return full.String(), fmt.Errorf("failed to read stream: %w", err)
+	}
+
+	emit(filter.flush())
+
+	return strings.TrimSpace(full.String()), nil
+}
+
+// buildChatMessages converts domain messages to Ollama chat messages with the given system prompt
+func buildChatMessages(messages []domain.Message, model string, enableReasoning bool, systemPrompt string) []ollamaChatMessage {
+	chatMessages := make([]ollamaChatMessage, 0, len(messages)+1)
+	chatMessages = append(chatMessages, ollamaChatMessage{
+		Role:    "system",
+		Content: systemPrompt,
+	})
+
+	for i, msg := range messages {
+		content := msg.Content
+
+		// For qwen3 models, apply reasoning toggle
+		if strings.HasPrefix(model, "qwen3") && !enableReasoning &&
+			msg.Role == "user" && i == len(messages)-1 {
+			content = content + "/no_think"
+		}
+
+		chatMessage := ollamaChatMessage{
+			Role:     msg.Role,
+			Content:  content,
+			ToolName: msg.ToolName,
+		}
+
+		// Images produced by tools are for the user, not the model
+		if msg.Role != "tool" {
+			chatMessage.Images = msg.Images
+		}
+
+		for _, call := range msg.ToolCalls {
+			chatMessage.ToolCalls = append(chatMessage.ToolCalls, ollamaToolCall{
+				Function: ollamaToolFunction{Name: call.Name, Arguments: call.Arguments},
+			})
+		}
+
+		chatMessages = append(chatMessages, chatMessage)
+	}
+
+	return chatMessages
+}
+
+// thinkFilter strips a leading <think>...</think> block from a token stream
+type thinkFilter struct {
+	active      bool // still looking for, or inside, a leading think block
+	trimLeading bool // drop whitespace that follows a removed think block
+	buf         strings.Builder
+}
+
+// push adds a token delta and returns the part of it that can be emitted
+func (f *thinkFilter) push(delta string) string {
+	if !f.active {
+		if f.trimLeading {
+			delta = strings.TrimLeft(delta, " \t\r\n")
+			if delta != "" {
+				f.trimLeading = false
+			}
+		}
+		return delta
+	}
+
+	f.buf.WriteString(delta)
+	pending := strings.TrimLeft(f.buf.String(), " \t\r\n")
+	if pending == "" {
+		return ""
+	}
+
+	if !strings.HasPrefix(pending, "<think>") {
+		// Wait for more tokens if this could still be the start of the tag
+		if strings.HasPrefix("<think>", pending) {
+			return ""
+		}
+		f.active = false
+		return pending
+	}
+
+	end := strings.Index(pending, "</think>")
+	if end < 0 {
+		return ""
+	}
+
+	f.active = false
+	f.trimLeading = true
+	return f.push(pending[end+len("</think>"):])
+}
+
+// flush returns any buffered text that was never part of a think block
+func (f *thinkFilter) flush() string {
+	if !f.active {
+		return ""
+	}
+	f.active = false
+
+	pending := strings.TrimLeft(f.buf.String(), " \t\r\n")
+	if strings.HasPrefix(pending, "<think>") {
+		return ""
+	}
+	return pending
+}
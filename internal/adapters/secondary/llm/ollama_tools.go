@@ -0,0 +1,157 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/vibin/chat-bot/internal/core/domain"
+	"github.com/vibin/chat-bot/internal/core/ports"
+	"github.com/vibin/chat-bot/internal/metrics"
+)
+
+// ollamaToolCall is a tool call requested by the model
+type ollamaToolCall struct {
+	Function ollamaToolFunction `json:"function"`
+}
+
+// ollamaToolFunction is the function name and arguments of a tool call
+type ollamaToolFunction struct {
+	Name      string                 `json:"name"`
+	Arguments map[string]interface{} `json:"arguments"`
+}
+
+// ollamaTool describes a tool in the tools field of an /api/chat request
+type ollamaTool struct {
+	Type     string               `json:"type"`
+	Function ollamaToolDefinition `json:"function"`
+}
+
+// ollamaToolDefinition is the name, description and JSON schema of a tool
+type ollamaToolDefinition struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	Parameters  map[string]interface{} `json:"parameters"`
+}
+
+// ollamaToolRequest is an /api/chat request offering tools to the model
+type ollamaToolRequest struct {
+	Model    string                 `json:"model"`
+	Messages []ollamaChatMessage    `json:"messages"`
+	Tools    []ollamaTool           `json:"tools"`
+	Stream   bool                   `json:"stream"`
+	Options  map[string]interface{} `json:"options,omitempty"`
+}
+
+// SupportsTools reports that Ollama can call tools
+func (a *OllamaAdapter) SupportsTools() bool {
+	return true
+}
+
+// GenerateWithTools generates the next assistant message using Ollama's native tool calling
+func (a *OllamaAdapter) GenerateWithTools(ctx context.Context, messages []domain.Message, tools []ports.Tool) (domain.Message, error) {
+	start := time.Now()
+	reply, err := a.generateWithTools(ctx, messages, tools)
+	metrics.ObserveLLMRequest(a.ModelName(), "tools", start, err)
+	return reply, err
+}
+
+// generateWithTools does the work of GenerateWithTools
+func (a *OllamaAdapter) generateWithTools(ctx context.Context, messages []domain.Message, tools []ports.Tool) (domain.Message, error) {
+	model := a.ModelName()
+	a.logger.Info("Generating response with tools", "model", model, "tool_count", len(tools))
+
+	definitions := make([]ollamaTool, 0, len(tools))
+	for _, tool := range tools {
+		definitions = append(definitions, ollamaTool{
+			Type: "function",
+			Function: ollamaToolDefinition{
+				Name:        tool.Name(),
+				Description: tool.Description(),
+				Parameters:  tool.Parameters(),
+			},
+		})
+	}
+
+	request := ollamaToolRequest{
+		Model:    model,
+		Messages: buildChatMessages(messages, model, a.config.EnableReasoning, systemPrompt(ctx)),
+		Tools:    definitions,
+		Stream:   false,
+		Options: map[string]interface{}{
+			"temperature": 0.7,
+			"num_predict": a.config.Ollama.MaxTokens,
+		},
+	}
+
+	requestJSON, err := json.Marshal(request)
+	if err != nil {
+		a.logger.Error("Failed to marshal tool request", "error", err)
+		return domain.Message{}, err
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, time.Duration(a.config.Ollama.TimeoutSeconds)*time.Second)
+	defer cancel()
+
+	url := fmt.Sprintf("%s/api/chat", a.config.Ollama.Endpoint)
+	httpReq, err := http.NewRequestWithContext(timeoutCtx, "POST", url, bytes.NewBuffer(requestJSON))
+	if err != nil {
+		a.logger.Error("Failed to create HTTP request", "error", err)
+		return domain.Message{}, fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		a.logger.Error("Failed to send tool request", "error", err)
+		return domain.Message{}, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		a.logger.Error("Failed to read response", "error", err)
+		return domain.Message{}, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		a.logger.Error("Received error response", "status", resp.Status, "body", string(body))
+		return domain.Message{}, fmt.Errorf("received error response: %s", resp.Status)
+	}
+
+	var chunk ollamaChatChunk
+	if err := json.Unmarshal(body, &chunk); err != nil {
+		a.logger.Error("Failed to parse response", "error", err, "body", string(body))
+		return domain.Message{}, fmt.Errorf("failed to parse response: %w", err)
+	}
+	if chunk.Error != "" {
+		return domain.Message{}, fmt.Errorf("ollama error: %s", chunk.Error)
+	}
+	metrics.AddLLMTokens(model, chunk.PromptEvalCount, chunk.EvalCount)
+
+	content := chunk.Message.Content
+	if strings.HasPrefix(model, "qwen3") && !a.config.EnableReasoning {
+		content = cleanThinkingTags(content)
+	}
+
+	reply := domain.NewMessage("assistant", strings.TrimSpace(content))
+	for _, call := range chunk.Message.ToolCalls {
+		reply.ToolCalls = append(reply.ToolCalls, domain.ToolCall{
+			Name:      call.Function.Name,
+			Arguments: call.Function.Arguments,
+		})
+	}
+
+	a.logger.Info("Ollama tool response finished",
+		"model", chunk.Model,
+		"tool_calls", len(reply.ToolCalls),
+		"prompt_eval_count", chunk.PromptEvalCount,
+		"eval_count", chunk.EvalCount)
+
+	return reply, nil
+}
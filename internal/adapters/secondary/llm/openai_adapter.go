@@ -0,0 +1,372 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vibin/chat-bot/config"
+	"github.com/vibin/chat-bot/internal/core/domain"
+	"github.com/vibin/chat-bot/internal/logger"
+)
+
+// OpenAIAdapter implements the LLMPort interface for servers speaking the OpenAI
+// /v1/chat/completions protocol, such as vLLM and the llama.cpp server
+type OpenAIAdapter struct {
+	httpClient *http.Client
+	config     *config.LLMConfig
+	logger     logger.Logger
+	mutex      sync.RWMutex
+	model      string // Starts as the configured model and can be switched at runtime
+}
+
+// openAIMessage is a chat message in the OpenAI format
+// Content is either a plain string or a list of content parts
+type openAIMessage struct {
+	Role    string      `json:"role"`
+	Content interface{} `json:"content"`
+}
+
+// openAIContentPart is a single text or image part of a message
+type openAIContentPart struct {
+	Type     string          `json:"type"`
+	Text     string          `json:"text,omitempty"`
+	ImageURL *openAIImageURL `json:"image_url,omitempty"`
+}
+
+// openAIImageURL references an image by URL or data URL
+type openAIImageURL struct {
+	URL string `json:"url"`
+}
+
+// openAIChatRequest is the request body for /chat/completions
+type openAIChatRequest struct {
+	Model       string          `json:"model"`
+	Messages    []openAIMessage `json:"messages"`
+	MaxTokens   int             `json:"max_tokens,omitempty"`
+	Temperature float64         `json:"temperature"`
+	Stream      bool            `json:"stream"`
+}
+
+// openAIChatResponse is a /chat/completions response or a single streamed chunk
+type openAIChatResponse struct {
+	ID      string `json:"id"`
+	Model   string `json:"model"`
+	Choices []struct {
+		Index   int `json:"index"`
+		Message struct {
+			Role    string `json:"role"`
+			Content string `json:"content"`
+		} `json:"message"`
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+		FinishReason string `json:"finish_reason"`
+	} `json:"choices"`
+	Usage *struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+		TotalTokens      int `json:"total_tokens"`
+	} `json:"usage,omitempty"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// NewOpenAIAdapter creates a new OpenAIAdapter
+func NewOpenAIAdapter(config *config.LLMConfig, log logger.Logger) (*OpenAIAdapter, error) {
+	log.Info("Initializing OpenAI-compatible adapter", "endpoint", config.OpenAI.Endpoint, "model", config.OpenAI.Model)
+
+	if config.OpenAI.Endpoint == "" {
+		return nil, fmt.Errorf("openai endpoint is not configured")
+	}
+
+	return &OpenAIAdapter{
+		httpClient: &http.Client{},
+		config:     config,
+		logger:     log,
+		model:      config.OpenAI.Model,
+	}, nil
+}
+
+// ModelName returns the model requests are sent to
+func (a *OpenAIAdapter) ModelName() string {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+	return a.model
+}
+
+// SetModel switches the model for new requests
+func (a *OpenAIAdapter) SetModel(name string) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.model = name
+}
+
+// GenerateResponse generates a response from the LLM for a given chat history
+func (a *OpenAIAdapter) GenerateResponse(ctx context.Context, messages []domain.Message) (string, error) {
+	model := a.ModelName()
+	a.logger.Info("Generating response with OpenAI-compatible server", "model", model)
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, a.timeout())
+	defer cancel()
+
+	resp, err := a.sendChatRequest(timeoutCtx, messages, false)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		a.logger.Error("Failed to read response", "error", err)
+		return "", fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var completion openAIChatResponse
+	if err := json.Unmarshal(body, &completion); err != nil {
+		a.logger.Error("Failed to parse response", "error", err, "body", string(body))
+		return "", fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	if completion.Error != nil {
+		return "", fmt.Errorf("openai error: %s", completion.Error.Message)
+	}
+	if len(completion.Choices) == 0 {
+		return "", fmt.Errorf("openai response contained no choices")
+	}
+
+	if completion.Usage != nil {
+		a.logger.Info("OpenAI completion finished",
+			"model", completion.Model,
+			"prompt_tokens", completion.Usage.PromptTokens,
+			"completion_tokens", completion.Usage.CompletionTokens)
+	}
+
+	result := completion.Choices[0].Message.Content
+	if strings.HasPrefix(model, "qwen3") && !a.config.EnableReasoning {
+		result = cleanThinkingTags(result)
+	}
+
+	return result, nil
+}
+
+// GenerateResponseStream generates a response and calls onToken with each token delta
+func (a *OpenAIAdapter) GenerateResponseStream(ctx context.Context, messages []domain.Message, onToken func(token string)) (string, error) {
+	model := a.ModelName()
+	a.logger.Info("Streaming response with OpenAI-compatible server", "model", model)
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, a.timeout())
+	defer cancel()
+
+	resp, err := a.sendChatRequest(timeoutCtx, messages, true)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	filter := &thinkFilter{active: strings.HasPrefix(model, "qwen3") && !a.config.EnableReasoning}
+
+	var full strings.Builder
+	emit := func(delta string) {
+		if delta == "" {
+			return
+		}
+		full.WriteString(delta)
+		if onToken != nil {
+			onToken(delta)
+		}
+	}
+
+	// The stream is a sequence of "data: {json}" lines terminated by "data: [DONE]"
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "data:") {
+			continue
+		}
+
+		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		if data == "[DONE]" {
+			break
+		}
+
+		var chunk openAIChatResponse
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			a.logger.Warn("Failed to parse stream chunk", "error", err, "chunk", data)
+			continue
+		}
+
+		if chunk.Error != nil {
+			return full.String(), fmt.Errorf("openai stream error: %s", chunk.Error.Message)
+		}
+
+		for _, choice := range chunk.Choices {
+			emit(filter.push(choice.Delta.Content))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		a.logger.Error("Failed to read stream", "error", err)
+		return full.String(), fmt.Errorf("failed to read stream: %w", err)
+	}
+
+	emit(filter.flush())
+
+	return strings.TrimSpace(full.String()), nil
+}
+
+// sendChatRequest posts a chat completion request and returns the successful response
+func (a *OpenAIAdapter) sendChatRequest(ctx context.Context, messages []domain.Message, stream bool) (*http.Response, error) {
+	model := a.ModelName()
+
+	request := openAIChatRequest{
+		Model:       model,
+		Messages:    buildOpenAIMessages(messages, model, a.config.EnableReasoning, systemPrompt(ctx)),
+		MaxTokens:   a.config.OpenAI.MaxTokens,
+		Temperature: 0.7,
+		Stream:      stream,
+	}
+
+	requestJSON, err := json.Marshal(request)
+	if err != nil {
+		a.logger.Error("Failed to marshal request", "error", err)
+		return nil, err
+	}
+
+	url := strings.TrimSuffix(a.config.OpenAI.Endpoint, "/") + "/chat/completions"
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(requestJSON))
+	if err != nil {
+		a.logger.Error("Failed to create HTTP request", "error", err)
+		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+	if a.config.OpenAI.APIKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+a.config.OpenAI.APIKey)
+	}
+
+	resp, err := a.httpClient.Do(httpReq)
+	if err != nil {
+		a.logger.Error("Failed to send request", "error", err)
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		a.logger.Error("Received error response", "status", resp.Status, "body", string(body))
+
+		// OpenAI-compatible servers explain the failure in an error object
+		var errorBody openAIChatResponse
+		if json.Unmarshal(body, &errorBody) == nil && errorBody.Error != nil && errorBody.Error.Message != "" {
+			return nil, fmt.Errorf("received error response: %s: %s", resp.Status, errorBody.Error.Message)
+		}
+		return nil, fmt.Errorf("received error response: %s", resp.Status)
+	}
+
+	return resp, nil
+}
+
+// timeout returns the configured request timeout, defaulting to 100 seconds
+func (a *OpenAIAdapter) timeout() time.Duration {
+	if a.config.OpenAI.TimeoutSeconds <= 0 {
+		return 100 * time.Second
+	}
+	return time.Duration(a.config.OpenAI.TimeoutSeconds) * time.Second
+}
+
+// GetModelInfo returns information about the current LLM model
+func (a *OpenAIAdapter) GetModelInfo(ctx context.Context) (map[string]interface{}, error) {
+	model := a.ModelName()
+	a.logger.Info("Getting model info for OpenAI-compatible server", "model", model)
+
+	return map[string]interface{}{
+		"name":            model,
+		"provider":        "openai",
+		"endpoint":        a.config.OpenAI.Endpoint,
+		"maxTokens":       a.config.OpenAI.MaxTokens,
+		"enableReasoning": a.config.EnableReasoning,
+	}, nil
+}
+
+// Ping checks that the server is reachable by listing its models
+func (a *OpenAIAdapter) Ping(ctx context.Context) error {
+	return ping(ctx, strings.TrimSuffix(a.config.OpenAI.Endpoint, "/")+"/models", a.config.OpenAI.APIKey)
+}
+
+// buildOpenAIMessages converts domain messages to OpenAI messages with the given system prompt
+// Messages carrying images are sent as text and image_url content parts
+func buildOpenAIMessages(messages []domain.Message, model string, enableReasoning bool, systemPrompt string) []openAIMessage {
+	result := make([]openAIMessage, 0, len(messages)+1)
+	result = append(result, openAIMessage{
+		Role:    "system",
+		Content: systemPrompt,
+	})
+
+	for i, msg := range messages {
+		role := msg.Role
+		content := msg.Content
+
+		// Tool calls from Ollama's tool calling loop are replayed as plain text
+		if len(msg.ToolCalls) > 0 && content == "" {
+			continue
+		}
+		if role == "tool" {
+			role = "user"
+			content = fmt.Sprintf("Result of the %s tool:\n%s", msg.ToolName, content)
+		}
+
+		// For qwen3 models, apply reasoning toggle
+		if strings.HasPrefix(model, "qwen3") && !enableReasoning &&
+			role == "user" && i == len(messages)-1 {
+			content = content + "/no_think"
+		}
+
+		if len(msg.Images) == 0 || msg.Role == "tool" {
+			result = append(result, openAIMessage{Role: role, Content: content})
+			continue
+		}
+
+		parts := []openAIContentPart{{Type: "text", Text: content}}
+		for _, image := range msg.Images {
+			parts = append(parts, openAIContentPart{
+				Type:     "image_url",
+				ImageURL: &openAIImageURL{URL: imageDataURL(image)},
+			})
+		}
+		result = append(result, openAIMessage{Role: role, Content: parts})
+	}
+
+	return result
+}
+
+// imageDataURL turns a base64 encoded image into a data URL with a detected MIME type
+func imageDataURL(image string) string {
+	if strings.HasPrefix(image, "data:") || strings.HasPrefix(image, "http://") || strings.HasPrefix(image, "https://") {
+		return image
+	}
+
+	// Only the first few hundred bytes are needed to sniff the content type
+	sample := image
+	if len(sample) > 684 {
+		sample = sample[:684]
+	}
+
+	mimeType := "image/jpeg"
+	if decoded, err := base64.StdEncoding.DecodeString(sample); err == nil {
+		if detected := http.DetectContentType(decoded); strings.HasPrefix(detected, "image/") {
+			mimeType = detected
+		}
+	}
+
+	return "data:" + mimeType + ";base64," + image
+}
@@ -0,0 +1,170 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/vibin/chat-bot/config"
+	"github.com/vibin/chat-bot/internal/core/domain"
+	"github.com/vibin/chat-bot/internal/logger"
+)
+
+// onePixelPNG is a base64 encoded 1x1 PNG
+const onePixelPNG = "iVBORw0KGgoAAAANSUhEUgAAAAEAAAABCAYAAAAfFcSJAAAADUlEQVR42mNkYPhfDwAChwGA60e6kgAAAABJRU5ErkJggg=="
+
+// stubRequest is the part of a chat completion request the tests check
+type stubRequest struct {
+	Model    string `json:"model"`
+	Stream   bool   `json:"stream"`
+	Messages []struct {
+		Role    string          `json:"role"`
+		Content json.RawMessage `json:"content"`
+	} `json:"messages"`
+}
+
+// newStubAdapter starts a server answering /v1/chat/completions with respond and returns an adapter using it
+// Each request body is passed to the requests channel
+func newStubAdapter(t *testing.T, respond func(w http.ResponseWriter)) (*OpenAIAdapter, <-chan stubRequest) {
+	t.Helper()
+	requests := make(chan stubRequest, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer secret" {
+			t.Errorf("expected the API key to be sent, got %q", got)
+		}
+		var request stubRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("invalid request body: %v", err)
+		}
+		requests <- request
+		respond(w)
+	}))
+	t.Cleanup(server.Close)
+
+	adapter, err := NewOpenAIAdapter(&config.LLMConfig{
+		Provider: "openai",
+		OpenAI:   config.OpenAIConfig{Endpoint: server.URL + "/v1/", APIKey: "secret", Model: "test-model", TimeoutSeconds: 5},
+	}, logger.New(slog.LevelError, io.Discard))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return adapter, requests
+}
+
+func TestOpenAIAdapterGenerateResponse(t *testing.T) {
+	adapter, requests := newStubAdapter(t, func(w http.ResponseWriter) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"model": "test-model", "choices": [{"index": 0, "message": {"role": "assistant", "content": "Hello there"}}],
+			"usage": {"prompt_tokens": 3, "completion_tokens": 2, "total_tokens": 5}}`)
+	})
+
+	response, err := adapter.GenerateResponse(context.Background(), []domain.Message{domain.NewMessage("user", "Hi")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response != "Hello there" {
+		t.Errorf("expected %q, got %q", "Hello there", response)
+	}
+
+	request := <-requests
+	if request.Model != "test-model" || request.Stream {
+		t.Errorf("expected a non-streaming request for test-model, got %+v", request)
+	}
+	last := request.Messages[len(request.Messages)-1]
+	if last.Role != "user" || string(last.Content) != `"Hi"` {
+		t.Errorf("expected the user message as plain text, got %s %s", last.Role, last.Content)
+	}
+}
+
+func TestOpenAIAdapterStream(t *testing.T) {
+	adapter, requests := newStubAdapter(t, func(w http.ResponseWriter) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, token := range []string{"Hel", "lo", " there"} {
+			fmt.Fprintf(w, "data: {\"choices\": [{\"index\": 0, \"delta\": {\"content\": %q}}]}\n\n", token)
+		}
+		// Keep-alive comments and anything after [DONE] are ignored
+		io.WriteString(w, ": keep-alive\n\n")
+		io.WriteString(w, "data: [DONE]\n\n")
+		io.WriteString(w, "data: {\"choices\": [{\"index\": 0, \"delta\": {\"content\": \"ignored\"}}]}\n\n")
+	})
+
+	var tokens []string
+	response, err := adapter.GenerateResponseStream(context.Background(), []domain.Message{domain.NewMessage("user", "Hi")}, func(token string) {
+		tokens = append(tokens, token)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response != "Hello there" {
+		t.Errorf("expected %q, got %q", "Hello there", response)
+	}
+	if strings.Join(tokens, "|") != "Hel|lo| there" {
+		t.Errorf("expected each delta as a token, got %q", tokens)
+	}
+	if request := <-requests; !request.Stream {
+		t.Error("expected a streaming request")
+	}
+}
+
+func TestOpenAIAdapterImages(t *testing.T) {
+	adapter, requests := newStubAdapter(t, func(w http.ResponseWriter) {
+		io.WriteString(w, `{"choices": [{"index": 0, "message": {"role": "assistant", "content": "A pixel"}}]}`)
+	})
+
+	message := domain.NewMessage("user", "What is this?")
+	message.Images = []string{onePixelPNG}
+	if _, err := adapter.GenerateResponse(context.Background(), []domain.Message{message}); err != nil {
+		t.Fatal(err)
+	}
+
+	request := <-requests
+	var parts []openAIContentPart
+	if err := json.Unmarshal(request.Messages[len(request.Messages)-1].Content, &parts); err != nil {
+		t.Fatalf("expected content parts: %v", err)
+	}
+	if len(parts) != 2 || parts[0].Type != "text" || parts[0].Text != "What is this?" {
+		t.Fatalf("expected a text part followed by the image, got %+v", parts)
+	}
+	if parts[1].Type != "image_url" || parts[1].ImageURL == nil || parts[1].ImageURL.URL != "data:image/png;base64,"+onePixelPNG {
+		t.Errorf("expected the image as a PNG data URL, got %+v", parts[1])
+	}
+}
+
+func TestOpenAIAdapterErrorStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		stream bool
+	}{
+		{name: "completion", stream: false},
+		{name: "stream", stream: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adapter, _ := newStubAdapter(t, func(w http.ResponseWriter) {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				io.WriteString(w, `{"error": {"message": "model is loading"}}`)
+			})
+
+			messages := []domain.Message{domain.NewMessage("user", "Hi")}
+			var err error
+			if tt.stream {
+				_, err = adapter.GenerateResponseStream(context.Background(), messages, nil)
+			} else {
+				_, err = adapter.GenerateResponse(context.Background(), messages)
+			}
+			if err == nil || !strings.Contains(err.Error(), "503") || !strings.Contains(err.Error(), "model is loading") {
+				t.Fatalf("expected an error with the status and the server's message, got %v", err)
+			}
+		})
+	}
+}
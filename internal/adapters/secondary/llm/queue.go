@@ -0,0 +1,310 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/vibin/chat-bot/config"
+	"github.com/vibin/chat-bot/internal/core/domain"
+	"github.com/vibin/chat-bot/internal/core/ports"
+	"github.com/vibin/chat-bot/internal/logger"
+	"github.com/vibin/chat-bot/internal/metrics"
+)
+
+const (
+	defaultQueueMaxQueued = 20
+	defaultQueueMaxWait   = 60 * time.Second
+)
+
+// queuedRequest is a request waiting for its turn
+type queuedRequest struct {
+	group    string
+	priority int
+	seq      uint64        // Arrival order
+	ready    chan struct{} // Closed when the request may run
+}
+
+// RequestQueue limits how many requests an LLM server runs at once and queues the rest
+// Higher priorities go first, and groups of the same priority take turns so one busy group can't hold up the others
+// Adapters talking to the same server share one queue
+type RequestQueue struct {
+	name        string // Server label for logs and metrics
+	concurrency int
+	config      *config.LLMQueueConfig
+	logger      logger.Logger
+	mutex       sync.Mutex
+	running     int
+	waiting     []*queuedRequest
+	seq         uint64
+	turn        uint64
+	lastTurn    map[string]uint64 // Turn each group was last let through at
+}
+
+// NewRequestQueue creates a queue running at most concurrency requests at once
+func NewRequestQueue(name string, concurrency int, config *config.LLMQueueConfig, log logger.Logger) *RequestQueue {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	return &RequestQueue{
+		name:        name,
+		concurrency: concurrency,
+		config:      config,
+		logger:      log,
+		lastTurn:    make(map[string]uint64),
+	}
+}
+
+// QueuedLLM sends an LLM adapter's requests through a RequestQueue
+type QueuedLLM struct {
+	llm   ports.LLMPort
+	queue *RequestQueue
+}
+
+// NewQueuedLLM queues the requests to llm in queue
+func NewQueuedLLM(llm ports.LLMPort, queue *RequestQueue) *QueuedLLM {
+	return &QueuedLLM{llm: llm, queue: queue}
+}
+
+// Unwrap returns the queued adapter
+func (q *QueuedLLM) Unwrap() ports.LLMPort {
+	return q.llm
+}
+
+// GenerateResponse generates a response once it is the request's turn
+func (q *QueuedLLM) GenerateResponse(ctx context.Context, messages []domain.Message) (string, error) {
+	release, err := q.queue.acquire(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer release()
+	return q.llm.GenerateResponse(ctx, messages)
+}
+
+// GenerateResponseStream streams a response once it is the request's turn
+func (q *QueuedLLM) GenerateResponseStream(ctx context.Context, messages []domain.Message, onToken func(token string)) (string, error) {
+	release, err := q.queue.acquire(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer release()
+	return q.llm.GenerateResponseStream(ctx, messages, onToken)
+}
+
+// GenerateWithTools generates the next assistant message once it is the request's turn
+// Adapters without tool calling answer in plain text, which is logged
+func (q *QueuedLLM) GenerateWithTools(ctx context.Context, messages []domain.Message, tools []ports.Tool) (domain.Message, error) {
+	release, err := q.queue.acquire(ctx)
+	if err != nil {
+		return domain.Message{}, err
+	}
+	defer release()
+
+	if toolLLM, ok := q.llm.(ports.ToolCallingLLMPort); ok {
+		return toolLLM.GenerateWithTools(ctx, messages, tools)
+	}
+	q.queue.logger.Warn("LLM adapter has no tool calling, answering without tools", "endpoint", q.queue.name)
+	response, err := q.llm.GenerateResponse(ctx, messages)
+	return domain.NewMessage("assistant", response), err
+}
+
+// GetModelInfo returns the adapter's model info without waiting in the queue
+func (q *QueuedLLM) GetModelInfo(ctx context.Context) (map[string]interface{}, error) {
+	return q.llm.GetModelInfo(ctx)
+}
+
+// SupportsTools reports whether the queued adapter can call tools
+func (q *QueuedLLM) SupportsTools() bool {
+	return supportsTools(q.llm)
+}
+
+// supportsTools reports whether an adapter supports native tool calling
+func supportsTools(llm ports.LLMPort) bool {
+	toolLLM, ok := llm.(ports.ToolCallingLLMPort)
+	return ok && toolLLM.SupportsTools()
+}
+
+// Ping checks the adapter's backend without waiting in the queue
+func (q *QueuedLLM) Ping(ctx context.Context) error {
+	if checker, ok := q.llm.(ports.HealthCheckerPort); ok {
+		return checker.Ping(ctx)
+	}
+	return nil
+}
+
+// acquire waits for a free slot and returns the function that frees it
+func (q *RequestQueue) acquire(ctx context.Context) (func(), error) {
+	info := ports.LLMQueueInfoFrom(ctx)
+
+	request, position, err := q.enqueue(info)
+	if err != nil {
+		return nil, err
+	}
+	if request == nil {
+		return q.release, nil
+	}
+
+	q.logger.Info("LLM request queued", "endpoint", q.name, "group", info.Group, "priority", info.Priority, "position", position)
+	if info.OnQueued != nil {
+		info.OnQueued(position)
+	}
+
+	maxWait := q.config.MaxWaitSeconds * time.Second
+	if maxWait <= 0 {
+		maxWait = defaultQueueMaxWait
+	}
+	timer := time.NewTimer(maxWait)
+	defer timer.Stop()
+
+	start := time.Now()
+	select {
+	case <-request.ready:
+		metrics.LLMQueueWait.WithLabelValues(q.name).Observe(time.Since(start).Seconds())
+		return q.release, nil
+	case <-timer.C:
+		err = ports.ErrLLMQueueTimeout
+	case <-ctx.Done():
+		err = ctx.Err()
+		// Running out of time in line means the backend was busy, not that it failed
+		if errors.Is(err, context.DeadlineExceeded) {
+			err = ports.ErrLLMQueueTimeout
+		}
+	}
+
+	q.leave(request)
+	if err == ports.ErrLLMQueueTimeout {
+		metrics.LLMQueueRejected.WithLabelValues(q.name, "timeout").Inc()
+		q.logger.Warn("LLM request waited too long", "endpoint", q.name, "group", info.Group, "waited", time.Since(start))
+	}
+	return nil, err
+}
+
+// enqueue takes a free slot or puts a request in line
+// It returns a nil request when the slot was taken, or the queued request and its place in line
+func (q *RequestQueue) enqueue(info ports.LLMQueueInfo) (*queuedRequest, int, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if q.running < q.concurrency && len(q.waiting) == 0 {
+		q.running++
+		q.takeTurn(info.Group)
+		return nil, 0, nil
+	}
+
+	maxQueued := q.config.MaxQueued
+	if maxQueued <= 0 {
+		maxQueued = defaultQueueMaxQueued
+	}
+	if len(q.waiting) >= maxQueued {
+		metrics.LLMQueueRejected.WithLabelValues(q.name, "full").Inc()
+		q.logger.Warn("LLM queue is full", "endpoint", q.name, "group", info.Group, "waiting", maxQueued)
+		return nil, 0, ports.ErrLLMQueueFull
+	}
+
+	q.seq++
+	request := &queuedRequest{group: info.Group, priority: info.Priority, seq: q.seq, ready: make(chan struct{})}
+	q.waiting = append(q.waiting, request)
+	metrics.LLMQueueWaiting.WithLabelValues(q.name).Set(float64(len(q.waiting)))
+	return request, q.position(request), nil
+}
+
+// leave takes a request that stopped waiting out of line
+// The slot may have been handed over while it was giving up, then it is passed on
+func (q *RequestQueue) leave(request *queuedRequest) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	select {
+	case <-request.ready:
+		q.running--
+		q.dispatch()
+	default:
+		q.remove(request)
+	}
+	metrics.LLMQueueWaiting.WithLabelValues(q.name).Set(float64(len(q.waiting)))
+}
+
+// release frees a slot for the next request in line
+func (q *RequestQueue) release() {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	q.running--
+	q.dispatch()
+	metrics.LLMQueueWaiting.WithLabelValues(q.name).Set(float64(len(q.waiting)))
+}
+
+// dispatch lets waiting requests run while there are free slots
+// The mutex must be held
+func (q *RequestQueue) dispatch() {
+	for q.running < q.concurrency && len(q.waiting) > 0 {
+		i := nextRequest(q.waiting, q.lastTurn)
+		request := q.waiting[i]
+		q.waiting = append(q.waiting[:i], q.waiting[i+1:]...)
+		q.running++
+		q.takeTurn(request.group)
+		close(request.ready)
+	}
+}
+
+// takeTurn records that a group's request was let through
+// The mutex must be held
+func (q *RequestQueue) takeTurn(group string) {
+	q.turn++
+	q.lastTurn[group] = q.turn
+}
+
+// remove drops a request that stopped waiting
+// The mutex must be held
+func (q *RequestQueue) remove(request *queuedRequest) {
+	for i, waiting := range q.waiting {
+		if waiting == request {
+			q.waiting = append(q.waiting[:i], q.waiting[i+1:]...)
+			return
+		}
+	}
+}
+
+// position returns a request's place in line, starting at 1, by playing out the order requests would run in
+// The mutex must be held
+func (q *RequestQueue) position(request *queuedRequest) int {
+	waiting := append([]*queuedRequest(nil), q.waiting...)
+	lastTurn := make(map[string]uint64, len(q.lastTurn))
+	for group, turn := range q.lastTurn {
+		lastTurn[group] = turn
+	}
+
+	turn := q.turn
+	for position := 1; len(waiting) > 0; position++ {
+		i := nextRequest(waiting, lastTurn)
+		if waiting[i] == request {
+			return position
+		}
+		turn++
+		lastTurn[waiting[i].group] = turn
+		waiting = append(waiting[:i], waiting[i+1:]...)
+	}
+	return len(q.waiting)
+}
+
+// nextRequest returns the index of the request to run next: the highest priority,
+// then the group whose turn was longest ago, then the oldest request
+func nextRequest(waiting []*queuedRequest, lastTurn map[string]uint64) int {
+	best := 0
+	for i := 1; i < len(waiting); i++ {
+		candidate, current := waiting[i], waiting[best]
+		switch {
+		case candidate.priority != current.priority:
+			if candidate.priority > current.priority {
+				best = i
+			}
+		case lastTurn[candidate.group] != lastTurn[current.group]:
+			if lastTurn[candidate.group] < lastTurn[current.group] {
+				best = i
+			}
+		case candidate.seq < current.seq:
+			best = i
+		}
+	}
+	return best
+}
@@ -0,0 +1,318 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vibin/chat-bot/config"
+	"github.com/vibin/chat-bot/internal/core/ports"
+	"github.com/vibin/chat-bot/internal/logger"
+)
+
+// newTestQueue creates a queue with one slot
+func newTestQueue(maxQueued int) *RequestQueue {
+	return NewRequestQueue("test", 1, &config.LLMQueueConfig{MaxQueued: maxQueued, MaxWaitSeconds: 10},
+		logger.New(slog.LevelError, io.Discard))
+}
+
+// queueInfo returns a context carrying a request's group and priority
+func queueInfo(group string, priority int, onQueued func(int)) context.Context {
+	return ports.WithLLMQueueInfo(context.Background(), ports.LLMQueueInfo{Group: group, Priority: priority, OnQueued: onQueued})
+}
+
+// waitForQueued waits until n requests are in line
+func waitForQueued(t *testing.T, q *RequestQueue, n int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		q.mutex.Lock()
+		waiting := len(q.waiting)
+		q.mutex.Unlock()
+		if waiting == n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("expected %d queued requests", n)
+}
+
+// assertIdle checks that no slot is held and nobody waits
+func assertIdle(t *testing.T, q *RequestQueue) {
+	t.Helper()
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	if q.running != 0 || len(q.waiting) != 0 {
+		t.Fatalf("expected an idle queue, got %d running and %d waiting", q.running, len(q.waiting))
+	}
+}
+
+func TestRequestQueueOrder(t *testing.T) {
+	type request struct {
+		name     string
+		group    string
+		priority int
+	}
+
+	tests := []struct {
+		name      string
+		holder    string // Group of the request holding the slot while the others queue
+		requests  []request
+		order     []string
+		positions map[string]int
+	}{
+		{
+			name:      "same group keeps arrival order",
+			holder:    "other",
+			requests:  []request{{"a1", "a", 0}, {"a2", "a", 0}, {"a3", "a", 0}},
+			order:     []string{"a1", "a2", "a3"},
+			positions: map[string]int{"a1": 1, "a2": 2, "a3": 3},
+		},
+		{
+			name:      "higher priority goes first",
+			holder:    "other",
+			requests:  []request{{"low", "a", ports.LLMPriorityLow}, {"normal", "b", ports.LLMPriorityNormal}},
+			order:     []string{"normal", "low"},
+			positions: map[string]int{"low": 1, "normal": 1},
+		},
+		{
+			name:      "priority beats turns",
+			holder:    "a",
+			requests:  []request{{"b1", "b", ports.LLMPriorityLow}, {"a1", "a", ports.LLMPriorityNormal}},
+			order:     []string{"a1", "b1"},
+			positions: map[string]int{"b1": 1, "a1": 1},
+		},
+		{
+			name:      "groups take turns",
+			holder:    "other",
+			requests:  []request{{"a1", "a", 0}, {"a2", "a", 0}, {"a3", "a", 0}, {"b1", "b", 0}, {"b2", "b", 0}},
+			order:     []string{"a1", "b1", "a2", "b2", "a3"},
+			positions: map[string]int{"a1": 1, "a2": 2, "a3": 3, "b1": 2, "b2": 4},
+		},
+		{
+			name:      "recently served group waits",
+			holder:    "a",
+			requests:  []request{{"a1", "a", 0}, {"b1", "b", 0}},
+			order:     []string{"b1", "a1"},
+			positions: map[string]int{"a1": 1, "b1": 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := newTestQueue(10)
+			hold, err := q.acquire(queueInfo(tt.holder, 0, nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var mutex sync.Mutex
+			var order []string
+			positions := make(map[string]int)
+			var wg sync.WaitGroup
+			for i, r := range tt.requests {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					ctx := queueInfo(r.group, r.priority, func(position int) {
+						mutex.Lock()
+						positions[r.name] = position
+						mutex.Unlock()
+					})
+					release, err := q.acquire(ctx)
+					if err != nil {
+						t.Errorf("%s: %v", r.name, err)
+						return
+					}
+					mutex.Lock()
+					order = append(order, r.name)
+					mutex.Unlock()
+					release()
+				}()
+				// Queue the requests one at a time so their arrival order is fixed
+				waitForQueued(t, q, i+1)
+			}
+
+			hold()
+			wg.Wait()
+
+			for i := range tt.order {
+				if i >= len(order) || order[i] != tt.order[i] {
+					t.Fatalf("expected order %v, got %v", tt.order, order)
+				}
+			}
+			for name, position := range tt.positions {
+				if positions[name] != position {
+					t.Errorf("expected %s at position %d, got %d", name, position, positions[name])
+				}
+			}
+			assertIdle(t, q)
+		})
+	}
+}
+
+func TestRequestQueueGivingUp(t *testing.T) {
+	tests := []struct {
+		name      string
+		maxQueued int
+		queued    int // Requests already in line
+		ctx       func() (context.Context, context.CancelFunc)
+		err       error
+	}{
+		{
+			name:      "deadline while waiting is a queue timeout",
+			maxQueued: 10,
+			ctx: func() (context.Context, context.CancelFunc) {
+				return context.WithTimeout(context.Background(), 20*time.Millisecond)
+			},
+			err: ports.ErrLLMQueueTimeout,
+		},
+		{
+			name:      "cancelled caller",
+			maxQueued: 10,
+			ctx: func() (context.Context, context.CancelFunc) {
+				ctx, cancel := context.WithCancel(context.Background())
+				time.AfterFunc(20*time.Millisecond, cancel)
+				return ctx, cancel
+			},
+			err: context.Canceled,
+		},
+		{
+			name:      "full queue",
+			maxQueued: 1,
+			queued:    1,
+			ctx: func() (context.Context, context.CancelFunc) {
+				return context.WithCancel(context.Background())
+			},
+			err: ports.ErrLLMQueueFull,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := newTestQueue(tt.maxQueued)
+			hold, err := q.acquire(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var wg sync.WaitGroup
+			for i := 0; i < tt.queued; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					if release, err := q.acquire(context.Background()); err == nil {
+						release()
+					}
+				}()
+				waitForQueued(t, q, i+1)
+			}
+
+			ctx, cancel := tt.ctx()
+			defer cancel()
+			_, err = q.acquire(ctx)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("expected %v, got %v", tt.err, err)
+			}
+			waitForQueued(t, q, tt.queued)
+
+			hold()
+			wg.Wait()
+			assertIdle(t, q)
+		})
+	}
+}
+
+func TestRequestQueueLeave(t *testing.T) {
+	tests := []struct {
+		name    string
+		letIn   bool // Whether the slot is handed over before the request leaves
+		running int  // Slots held after leaving, by the holder when it wasn't let in
+	}{
+		{name: "leaving before the turn", letIn: false, running: 1},
+		{name: "leaving after being let in passes the slot on", letIn: true, running: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := newTestQueue(10)
+			hold, err := q.acquire(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			request, _, err := q.enqueue(ports.LLMQueueInfo{Group: "a"})
+			if err != nil || request == nil {
+				t.Fatalf("expected the request to queue, got %v", err)
+			}
+
+			if tt.letIn {
+				hold()
+				<-request.ready
+			}
+			q.leave(request)
+
+			q.mutex.Lock()
+			running, waiting := q.running, len(q.waiting)
+			q.mutex.Unlock()
+			if running != tt.running || waiting != 0 {
+				t.Fatalf("expected %d running and none waiting, got %d running and %d waiting", tt.running, running, waiting)
+			}
+
+			if !tt.letIn {
+				hold()
+			}
+			assertIdle(t, q)
+		})
+	}
+}
+
+func TestRequestQueueLeaveHandsOver(t *testing.T) {
+	q := newTestQueue(10)
+	hold, err := q.acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaving, _, _ := q.enqueue(ports.LLMQueueInfo{Group: "a"})
+	next, _, _ := q.enqueue(ports.LLMQueueInfo{Group: "b"})
+
+	// The first request is let in as it gives up, the slot must go to the next one
+	hold()
+	<-leaving.ready
+	q.leave(leaving)
+
+	select {
+	case <-next.ready:
+	default:
+		t.Fatal("expected the slot to be passed on")
+	}
+	q.release()
+	assertIdle(t, q)
+}
+
+func TestRequestQueueCancelRace(t *testing.T) {
+	q := NewRequestQueue("test", 2, &config.LLMQueueConfig{MaxQueued: 100, MaxWaitSeconds: 10},
+		logger.New(slog.LevelError, io.Discard))
+
+	// Callers give up at random points, often just as they are let in
+	var wg sync.WaitGroup
+	for i := 0; i < 200; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), time.Duration(i%5)*time.Millisecond)
+			defer cancel()
+			release, err := q.acquire(ctx)
+			if err != nil {
+				return
+			}
+			time.Sleep(time.Millisecond)
+			release()
+		}()
+	}
+	wg.Wait()
+	assertIdle(t, q)
+}
@@ -0,0 +1,380 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/vibin/chat-bot/config"
+	"github.com/vibin/chat-bot/internal/core/domain"
+	"github.com/vibin/chat-bot/internal/core/ports"
+	"github.com/vibin/chat-bot/internal/logger"
+	"github.com/vibin/chat-bot/internal/metrics"
+)
+
+const (
+	defaultRouterCooldown = 30 * time.Second
+	routerProbeTimeout    = 5 * time.Second
+)
+
+// Backend is one LLM adapter in a Router's chain
+type Backend struct {
+	Name   string
+	LLM    ports.LLMPort
+	Config config.LLMBackendConfig
+}
+
+// routerBackend is a backend and whether it is taking requests
+type routerBackend struct {
+	Backend
+	tools     bool // Whether the backend supports native tool calling
+	mutex     sync.Mutex
+	downUntil time.Time // Skipped until then after a failure
+	lastError string
+}
+
+// Router sends each LLM request to the first available backend that serves its task and length,
+// and fails over to the next one when a backend errors or times out
+type Router struct {
+	config   *config.LLMRoutingConfig
+	backends []*routerBackend
+	logger   logger.Logger
+}
+
+// NewRouter creates a router over backends, tried in the given order
+func NewRouter(config *config.LLMRoutingConfig, backends []Backend, log logger.Logger) *Router {
+	r := &Router{
+		config: config,
+		logger: log,
+	}
+	for _, backend := range backends {
+		r.backends = append(r.backends, &routerBackend{Backend: backend, tools: supportsTools(backend.LLM)})
+		metrics.LLMBackendUp.WithLabelValues(backend.Name).Set(1)
+	}
+	return r
+}
+
+// ForTask returns an LLMPort routing its requests as task
+// A task set on the request context with ports.WithLLMTask takes precedence
+func (r *Router) ForTask(task domain.LLMTask) ports.LLMPort {
+	return &routedLLM{router: r, task: task}
+}
+
+// Start probes the backends every HealthCheckSeconds until ctx is done, so a failed backend
+// is skipped before a request waits on it and is used again as soon as it answers
+func (r *Router) Start(ctx context.Context) {
+	interval := r.config.HealthCheckSeconds * time.Second
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for _, backend := range r.backends {
+				r.probe(ctx, backend)
+			}
+		}
+	}
+}
+
+// probe pings a backend that can be checked and records the outcome
+func (r *Router) probe(ctx context.Context, backend *routerBackend) error {
+	checker, ok := backend.LLM.(ports.HealthCheckerPort)
+	if !ok {
+		return nil
+	}
+
+	probeCtx, cancel := context.WithTimeout(ctx, routerProbeTimeout)
+	defer cancel()
+	if err := checker.Ping(probeCtx); err != nil {
+		r.markDown(backend, err)
+		return err
+	}
+	r.markUp(backend)
+	return nil
+}
+
+// candidates returns the backends serving a task and message length, available ones first
+// Backends cooling down are kept as a last resort so a request is not refused while one might have recovered
+func (r *Router) candidates(task domain.LLMTask, messages []domain.Message) []*routerBackend {
+	length := 0
+	if len(messages) > 0 {
+		length = utf8.RuneCountInString(messages[len(messages)-1].Content)
+	}
+
+	var available, down []*routerBackend
+	for _, backend := range r.backends {
+		if !backend.serves(task) || !backend.fits(length) {
+			continue
+		}
+		if backend.isDown() {
+			down = append(down, backend)
+		} else {
+			available = append(available, backend)
+		}
+	}
+	return append(available, down...)
+}
+
+// toolsFirst moves the backends with tool calling ahead of the others, keeping their order otherwise
+func toolsFirst(candidates []*routerBackend) []*routerBackend {
+	sorted := make([]*routerBackend, 0, len(candidates))
+	for _, backend := range candidates {
+		if backend.tools {
+			sorted = append(sorted, backend)
+		}
+	}
+	for _, backend := range candidates {
+		if !backend.tools {
+			sorted = append(sorted, backend)
+		}
+	}
+	return sorted
+}
+
+// route calls call on each candidate backend until one succeeds
+// retryable reports whether a failed attempt may be repeated on the next backend, nil always allows it
+func (r *Router) route(ctx context.Context, task domain.LLMTask, candidates []*routerBackend, operation string,
+	call func(ctx context.Context, backend *routerBackend) error, retryable func() bool) error {
+	if len(candidates) == 0 {
+		return fmt.Errorf("no LLM backend serves %s requests", task)
+	}
+
+	var errs []error
+	for i, backend := range candidates {
+		callCtx, cancel := backend.withTimeout(ctx)
+		start := time.Now()
+		err := call(callCtx, backend)
+		cancel()
+
+		if err == nil {
+			r.markUp(backend)
+			metrics.LLMRoutedRequests.WithLabelValues(backend.Name, string(task), metrics.StatusOK).Inc()
+			r.logger.Info("LLM request served", "backend", backend.Name, "task", task, "operation", operation,
+				"attempt", i+1, "duration", time.Since(start))
+			return nil
+		}
+
+		metrics.LLMRoutedRequests.WithLabelValues(backend.Name, string(task), metrics.StatusError).Inc()
+
+		// The caller gave up or timed out, which says nothing about the backend
+		if ctx.Err() != nil {
+			return err
+		}
+
+		// A backend that is only busy is still up, the request just spills over to the next one
+		if !ports.IsLLMBusy(err) {
+			r.markDown(backend, err)
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", backend.Name, err))
+		if retryable != nil && !retryable() {
+			return err
+		}
+		if i < len(candidates)-1 {
+			r.logger.Warn("LLM backend failed, trying the next one", "backend", backend.Name, "task", task,
+				"operation", operation, "error", err)
+		}
+	}
+	return fmt.Errorf("all LLM backends failed: %w", errors.Join(errs...))
+}
+
+// markDown skips a backend for the cooldown
+func (r *Router) markDown(backend *routerBackend, err error) {
+	cooldown := r.config.CooldownSeconds * time.Second
+	if cooldown <= 0 {
+		cooldown = defaultRouterCooldown
+	}
+
+	backend.mutex.Lock()
+	wasDown := time.Now().Before(backend.downUntil)
+	backend.downUntil = time.Now().Add(cooldown)
+	backend.lastError = err.Error()
+	backend.mutex.Unlock()
+
+	metrics.LLMBackendUp.WithLabelValues(backend.Name).Set(0)
+	if !wasDown {
+		r.logger.Warn("LLM backend is down", "backend", backend.Name, "cooldown", cooldown, "error", err)
+	}
+}
+
+// markUp takes a backend back into rotation
+func (r *Router) markUp(backend *routerBackend) {
+	backend.mutex.Lock()
+	wasDown := !backend.downUntil.IsZero()
+	backend.downUntil = time.Time{}
+	backend.lastError = ""
+	backend.mutex.Unlock()
+
+	metrics.LLMBackendUp.WithLabelValues(backend.Name).Set(1)
+	if wasDown {
+		r.logger.Info("LLM backend is back up", "backend", backend.Name)
+	}
+}
+
+// serves reports whether the backend takes requests for a task
+func (b *routerBackend) serves(task domain.LLMTask) bool {
+	if len(b.Config.Tasks) == 0 {
+		return true
+	}
+	for _, t := range b.Config.Tasks {
+		if domain.LLMTask(t) == task {
+			return true
+		}
+	}
+	return false
+}
+
+// fits reports whether the backend takes requests whose last message has length characters
+func (b *routerBackend) fits(length int) bool {
+	if length < b.Config.MinMessageChars {
+		return false
+	}
+	return b.Config.MaxMessageChars <= 0 || length <= b.Config.MaxMessageChars
+}
+
+// isDown reports whether the backend is cooling down after a failure
+func (b *routerBackend) isDown() bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return time.Now().Before(b.downUntil)
+}
+
+// withTimeout applies the backend's timeout to a request
+func (b *routerBackend) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if b.Config.TimeoutSeconds <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, b.Config.TimeoutSeconds*time.Second)
+}
+
+// routedLLM is the LLMPort of a Router for one task
+type routedLLM struct {
+	router *Router
+	task   domain.LLMTask
+}
+
+// taskFor returns the task of a request
+func (l *routedLLM) taskFor(ctx context.Context) domain.LLMTask {
+	if task := ports.LLMTaskFrom(ctx); task != "" {
+		return task
+	}
+	return l.task
+}
+
+// GenerateResponse generates a response on the first backend that answers
+func (l *routedLLM) GenerateResponse(ctx context.Context, messages []domain.Message) (string, error) {
+	var response string
+	task := l.taskFor(ctx)
+	err := l.router.route(ctx, task, l.router.candidates(task, messages), "generate", func(ctx context.Context, backend *routerBackend) error {
+		var err error
+		response, err = backend.LLM.GenerateResponse(ctx, messages)
+		return err
+	}, nil)
+	return response, err
+}
+
+// GenerateResponseStream streams a response from the first backend that answers
+// Once a backend has sent tokens its failure is returned, since the caller has already shown them
+func (l *routedLLM) GenerateResponseStream(ctx context.Context, messages []domain.Message, onToken func(token string)) (string, error) {
+	var response string
+	streamed := false
+	task := l.taskFor(ctx)
+	err := l.router.route(ctx, task, l.router.candidates(task, messages), "stream", func(ctx context.Context, backend *routerBackend) error {
+		var err error
+		response, err = backend.LLM.GenerateResponseStream(ctx, messages, func(token string) {
+			streamed = true
+			onToken(token)
+		})
+		return err
+	}, func() bool { return !streamed })
+	return response, err
+}
+
+// GenerateWithTools generates the next assistant message on the first backend that answers
+// Backends with tool calling are tried first, the others answer in plain text
+func (l *routedLLM) GenerateWithTools(ctx context.Context, messages []domain.Message, tools []ports.Tool) (domain.Message, error) {
+	var reply domain.Message
+	task := l.taskFor(ctx)
+	err := l.router.route(ctx, task, toolsFirst(l.router.candidates(task, messages)), "tools", func(ctx context.Context, backend *routerBackend) error {
+		if backend.tools {
+			var err error
+			reply, err = backend.LLM.(ports.ToolCallingLLMPort).GenerateWithTools(ctx, messages, tools)
+			return err
+		}
+		l.router.logger.Warn("LLM backend has no tool calling, answering without tools", "backend", backend.Name, "task", task)
+		response, err := backend.LLM.GenerateResponse(ctx, messages)
+		reply = domain.NewMessage("assistant", response)
+		return err
+	}, nil)
+	return reply, err
+}
+
+// SupportsTools reports whether any backend serving the task can call tools
+func (l *routedLLM) SupportsTools() bool {
+	for _, backend := range l.router.backends {
+		if backend.tools && backend.serves(l.task) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetModelInfo returns the model info of the backend the task would be sent to, and the state of every backend
+func (l *routedLLM) GetModelInfo(ctx context.Context) (map[string]interface{}, error) {
+	task := l.taskFor(ctx)
+
+	backends := make([]map[string]interface{}, 0, len(l.router.backends))
+	for _, backend := range l.router.backends {
+		backend.mutex.Lock()
+		backends = append(backends, map[string]interface{}{
+			"name":      backend.Name,
+			"up":        !time.Now().Before(backend.downUntil),
+			"lastError": backend.lastError,
+			"tasks":     backend.Config.Tasks,
+		})
+		backend.mutex.Unlock()
+	}
+
+	info := map[string]interface{}{"provider": "router", "task": task}
+	candidates := l.router.candidates(task, nil)
+	if len(candidates) > 0 {
+		// Copy the backend's map, which the backend may keep and hand out again
+		backendInfo, err := candidates[0].LLM.GetModelInfo(ctx)
+		if err == nil {
+			info = make(map[string]interface{}, len(backendInfo)+2)
+			for key, value := range backendInfo {
+				info[key] = value
+			}
+		}
+		info["backend"] = candidates[0].Name
+	}
+	info["backends"] = backends
+	return info, nil
+}
+
+// Ping succeeds when any backend serving the task answers
+func (l *routedLLM) Ping(ctx context.Context) error {
+	var errs []error
+	for _, backend := range l.router.backends {
+		if !backend.serves(l.task) {
+			continue
+		}
+		err := l.router.probe(ctx, backend)
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", backend.Name, err))
+	}
+	if len(errs) == 0 {
+		return fmt.Errorf("no LLM backend serves %s requests", l.task)
+	}
+	return errors.Join(errs...)
+}
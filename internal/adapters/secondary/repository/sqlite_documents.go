@@ -0,0 +1,196 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/vibin/chat-bot/internal/core/domain"
+	"github.com/vibin/chat-bot/internal/logger"
+)
+
+// SQLiteDocumentRepository implements the DocumentRepositoryPort interface with SQLite storage
+type SQLiteDocumentRepository struct {
+	db     *sql.DB
+	logger logger.Logger
+}
+
+// NewSQLiteDocumentRepository opens the document database at dbPath and applies any pending migrations
+func NewSQLiteDocumentRepository(dbPath string, log logger.Logger) (*SQLiteDocumentRepository, error) {
+	log.Info("Opening SQLite document repository", "path", dbPath)
+
+	db, err := openSQLite(dbPath, "documents", documentMigrations)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SQLiteDocumentRepository{
+		db:     db,
+		logger: log,
+	}, nil
+}
+
+// Close closes the database connection
+func (r *SQLiteDocumentRepository) Close() error {
+	return r.db.Close()
+}
+
+// SaveDocument stores a document with its chunks
+// A document with the same file name in the same conversation is replaced
+func (r *SQLiteDocumentRepository) SaveDocument(ctx context.Context, doc *domain.Document, chunks []domain.DocumentChunk) error {
+	r.logger.Info("Saving document", "document_id", doc.ID, "conversation_id", doc.ConversationID, "chunks", len(chunks))
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		DELETE FROM document_chunks WHERE document_id IN (
+			SELECT id FROM documents WHERE conversation_id = ? AND file_name = ?
+		)
+	`, doc.ConversationID, doc.FileName)
+	if err != nil {
+		return fmt.Errorf("failed to delete previous chunks: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM documents WHERE conversation_id = ? AND file_name = ?`, doc.ConversationID, doc.FileName); err != nil {
+		return fmt.Errorf("failed to delete previous document: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO documents (id, conversation_id, file_name, mime_type, pages, chunk_count, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, doc.ID, doc.ConversationID, doc.FileName, doc.MimeType, doc.Pages, len(chunks), formatTime(doc.CreatedAt))
+	if err != nil {
+		return fmt.Errorf("failed to save document: %w", err)
+	}
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO document_chunks (document_id, position, page, content, embedding)
+		VALUES (?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for i, chunk := range chunks {
+		var embedding []byte
+		if len(chunk.Embedding) > 0 {
+			embedding = encodeVector(chunk.Embedding)
+		}
+		if _, err := stmt.ExecContext(ctx, doc.ID, i, chunk.Page, chunk.Content, embedding); err != nil {
+			return fmt.Errorf("failed to save chunk: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	doc.ChunkCount = len(chunks)
+	return nil
+}
+
+// ListDocuments returns the documents of a conversation, newest first
+func (r *SQLiteDocumentRepository) ListDocuments(ctx context.Context, conversationID string) ([]*domain.Document, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, conversation_id, file_name, mime_type, pages, chunk_count, created_at
+		FROM documents WHERE conversation_id = ? ORDER BY created_at DESC
+	`, conversationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list documents: %w", err)
+	}
+	defer rows.Close()
+
+	documents := []*domain.Document{}
+	for rows.Next() {
+		var doc domain.Document
+		var createdAt string
+		if err := rows.Scan(&doc.ID, &doc.ConversationID, &doc.FileName, &doc.MimeType, &doc.Pages, &doc.ChunkCount, &createdAt); err != nil {
+			return nil, err
+		}
+		doc.CreatedAt = parseTime(createdAt)
+		documents = append(documents, &doc)
+	}
+
+	return documents, rows.Err()
+}
+
+// GetChunks returns all chunks of the documents in a conversation in document order
+func (r *SQLiteDocumentRepository) GetChunks(ctx context.Context, conversationID string) ([]domain.DocumentChunk, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT c.document_id, d.file_name, c.position, c.page, c.content, c.embedding
+		FROM document_chunks c JOIN documents d ON d.id = c.document_id
+		WHERE d.conversation_id = ?
+		ORDER BY d.created_at, c.document_id, c.position
+	`, conversationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load chunks: %w", err)
+	}
+	defer rows.Close()
+
+	chunks := []domain.DocumentChunk{}
+	for rows.Next() {
+		var chunk domain.DocumentChunk
+		var embedding []byte
+		if err := rows.Scan(&chunk.DocumentID, &chunk.FileName, &chunk.Position, &chunk.Page, &chunk.Content, &embedding); err != nil {
+			return nil, err
+		}
+		chunk.Embedding = decodeVector(embedding)
+		chunks = append(chunks, chunk)
+	}
+
+	return chunks, rows.Err()
+}
+
+// DeleteDocument deletes a document and its chunks
+func (r *SQLiteDocumentRepository) DeleteDocument(ctx context.Context, id string) error {
+	r.logger.Info("Deleting document", "document_id", id)
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM documents WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete document: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return errors.New("document not found")
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM document_chunks WHERE document_id = ?`, id); err != nil {
+		return fmt.Errorf("failed to delete chunks: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// encodeVector stores a vector as little-endian float32 values
+func encodeVector(vector []float32) []byte {
+	data := make([]byte, 4*len(vector))
+	for i, v := range vector {
+		binary.LittleEndian.PutUint32(data[i*4:], math.Float32bits(v))
+	}
+	return data
+}
+
+// decodeVector reads a vector written by encodeVector, returning nil for an empty value
+func decodeVector(data []byte) []float32 {
+	if len(data) == 0 || len(data)%4 != 0 {
+		return nil
+	}
+
+	vector := make([]float32, len(data)/4)
+	for i := range vector {
+		vector[i] = math.Float32frombits(binary.LittleEndian.Uint32(data[i*4:]))
+	}
+	return vector
+}
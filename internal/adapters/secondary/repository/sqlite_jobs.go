@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+	"github.com/vibin/chat-bot/internal/core/domain"
+	"github.com/vibin/chat-bot/internal/core/ports"
+	"github.com/vibin/chat-bot/internal/logger"
+)
+
+// SQLiteJobRepository implements the JobRepositoryPort interface with SQLite storage
+type SQLiteJobRepository struct {
+	db     *sql.DB
+	logger logger.Logger
+}
+
+// NewSQLiteJobRepository opens the scheduled job database at dbPath and applies any pending migrations
+func NewSQLiteJobRepository(dbPath string, log logger.Logger) (*SQLiteJobRepository, error) {
+	log.Info("Opening SQLite job repository", "path", dbPath)
+
+	db, err := openSQLite(dbPath, "jobs", jobMigrations)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SQLiteJobRepository{
+		db:     db,
+		logger: log,
+	}, nil
+}
+
+// Close closes the database connection
+func (r *SQLiteJobRepository) Close() error {
+	return r.db.Close()
+}
+
+// CreateJob stores a new job, returning ports.ErrJobExists when its ID is taken
+func (r *SQLiteJobRepository) CreateJob(ctx context.Context, job *domain.ScheduledJob) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO scheduled_jobs (id, chat_jid, message, cron, next_run, last_run, created_by, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, job.ID, job.ChatJID, job.Message, job.Cron, formatTime(job.NextRun), formatOptionalTime(job.LastRun), job.CreatedBy, formatTime(job.CreatedAt))
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey {
+		return ports.ErrJobExists
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create job: %w", err)
+	}
+	return nil
+}
+
+// UpdateJob saves the schedule of an existing job
+func (r *SQLiteJobRepository) UpdateJob(ctx context.Context, job *domain.ScheduledJob) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE scheduled_jobs SET next_run = ?, last_run = ? WHERE id = ?
+	`, formatTime(job.NextRun), formatOptionalTime(job.LastRun), job.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update job: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return errors.New("job not found")
+	}
+	return nil
+}
+
+// GetJob returns a job by ID
+func (r *SQLiteJobRepository) GetJob(ctx context.Context, id string) (*domain.ScheduledJob, error) {
+	row := r.db.QueryRowContext(ctx, `
+		SELECT id, chat_jid, message, cron, next_run, last_run, created_by, created_at
+		FROM scheduled_jobs WHERE id = ?
+	`, id)
+
+	job, err := scanJob(row)
+	if err == sql.ErrNoRows {
+		return nil, errors.New("job not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get job: %w", err)
+	}
+	return job, nil
+}
+
+// ListJobs returns the jobs of a chat by next run, or of all chats when chatJID is empty
+func (r *SQLiteJobRepository) ListJobs(ctx context.Context, chatJID string) ([]*domain.ScheduledJob, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, chat_jid, message, cron, next_run, last_run, created_by, created_at
+		FROM scheduled_jobs WHERE ? = '' OR chat_jid = ? ORDER BY next_run
+	`, chatJID, chatJID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+	return scanJobs(rows)
+}
+
+// DueJobs returns the jobs whose next run is at or before now
+func (r *SQLiteJobRepository) DueJobs(ctx context.Context, now time.Time) ([]*domain.ScheduledJob, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, chat_jid, message, cron, next_run, last_run, created_by, created_at
+		FROM scheduled_jobs WHERE next_run <= ? ORDER BY next_run
+	`, formatTime(now))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read due jobs: %w", err)
+	}
+	return scanJobs(rows)
+}
+
+// DeleteJob deletes a job
+func (r *SQLiteJobRepository) DeleteJob(ctx context.Context, id string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM scheduled_jobs WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete job: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return errors.New("job not found")
+	}
+	return nil
+}
+
+// scanJobs reads all jobs from rows and closes them
+func scanJobs(rows *sql.Rows) ([]*domain.ScheduledJob, error) {
+	defer rows.Close()
+
+	jobs := []*domain.ScheduledJob{}
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, rows.Err()
+}
+
+// scanJob reads a job from a row
+func scanJob(row interface{ Scan(dest ...any) error }) (*domain.ScheduledJob, error) {
+	var job domain.ScheduledJob
+	var nextRun, lastRun, createdAt string
+	if err := row.Scan(&job.ID, &job.ChatJID, &job.Message, &job.Cron, &nextRun, &lastRun, &job.CreatedBy, &createdAt); err != nil {
+		return nil, err
+	}
+	job.NextRun = parseTime(nextRun)
+	job.LastRun = parseTime(lastRun)
+	job.CreatedAt = parseTime(createdAt)
+	return &job, nil
+}
+
+// formatOptionalTime formats a time for storage, leaving the zero time empty
+func formatOptionalTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return formatTime(t)
+}
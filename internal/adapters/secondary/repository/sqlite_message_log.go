@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/vibin/chat-bot/internal/core/domain"
+	"github.com/vibin/chat-bot/internal/logger"
+)
+
+// SQLiteMessageLog implements the MessageLogPort interface with SQLite storage
+type SQLiteMessageLog struct {
+	db     *sql.DB
+	logger logger.Logger
+}
+
+// NewSQLiteMessageLog opens the message log database at dbPath and applies any pending migrations
+func NewSQLiteMessageLog(dbPath string, log logger.Logger) (*SQLiteMessageLog, error) {
+	log.Info("Opening SQLite message log", "path", dbPath)
+
+	db, err := openSQLite(dbPath, "message_log", messageLogMigrations)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SQLiteMessageLog{
+		db:     db,
+		logger: log,
+	}, nil
+}
+
+// Close closes the database connection
+func (l *SQLiteMessageLog) Close() error {
+	return l.db.Close()
+}
+
+// Append adds a message to the log
+func (l *SQLiteMessageLog) Append(ctx context.Context, msg domain.LoggedMessage) error {
+	_, err := l.db.ExecContext(ctx, `
+		INSERT INTO logged_messages (conversation_id, message_id, sender_id, sender_name, content, from_bot, timestamp)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, msg.ConversationID, msg.MessageID, msg.SenderID, msg.SenderName, msg.Content, msg.FromBot, formatTime(msg.Timestamp))
+	if err != nil {
+		return fmt.Errorf("failed to log message: %w", err)
+	}
+	return nil
+}
+
+// Since returns up to limit of the newest messages after a time, oldest first
+func (l *SQLiteMessageLog) Since(ctx context.Context, conversationID string, since time.Time, limit int) ([]domain.LoggedMessage, error) {
+	rows, err := l.db.QueryContext(ctx, `
+		SELECT conversation_id, message_id, sender_id, sender_name, content, from_bot, timestamp FROM (
+			SELECT * FROM logged_messages
+			WHERE conversation_id = ? AND timestamp > ?
+			ORDER BY timestamp DESC, id DESC
+			LIMIT ?
+		) ORDER BY timestamp, id
+	`, conversationID, formatTime(since), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read message log: %w", err)
+	}
+	defer rows.Close()
+
+	messages := []domain.LoggedMessage{}
+	for rows.Next() {
+		var msg domain.LoggedMessage
+		var timestamp string
+		if err := rows.Scan(&msg.ConversationID, &msg.MessageID, &msg.SenderID, &msg.SenderName, &msg.Content, &msg.FromBot, &timestamp); err != nil {
+			return nil, err
+		}
+		msg.Timestamp = parseTime(timestamp)
+		messages = append(messages, msg)
+	}
+
+	return messages, rows.Err()
+}
+
+// LastFrom returns the time of the newest message by a sender before a time, or the zero time
+func (l *SQLiteMessageLog) LastFrom(ctx context.Context, conversationID, senderID string, before time.Time) (time.Time, error) {
+	var timestamp sql.NullString
+	err := l.db.QueryRowContext(ctx, `
+		SELECT MAX(timestamp) FROM logged_messages
+		WHERE conversation_id = ? AND sender_id = ? AND timestamp < ?
+	`, conversationID, senderID, formatTime(before)).Scan(&timestamp)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to read message log: %w", err)
+	}
+	if !timestamp.Valid {
+		return time.Time{}, nil
+	}
+	return parseTime(timestamp.String), nil
+}
+
+// Prune deletes messages older than maxAge and all but the newest maxPerConversation of each conversation
+// A limit of zero or less disables that rule
+func (l *SQLiteMessageLog) Prune(ctx context.Context, maxAge time.Duration, maxPerConversation int) (int64, error) {
+	var deleted int64
+
+	if maxAge > 0 {
+		result, err := l.db.ExecContext(ctx, `DELETE FROM logged_messages WHERE timestamp < ?`, formatTime(time.Now().Add(-maxAge)))
+		if err != nil {
+			return deleted, fmt.Errorf("failed to prune old messages: %w", err)
+		}
+		affected, _ := result.RowsAffected()
+		deleted += affected
+	}
+
+	if maxPerConversation > 0 {
+		result, err := l.db.ExecContext(ctx, `
+			DELETE FROM logged_messages WHERE id IN (
+				SELECT id FROM (
+					SELECT id, ROW_NUMBER() OVER (PARTITION BY conversation_id ORDER BY timestamp DESC, id DESC) AS rank
+					FROM logged_messages
+				) WHERE rank > ?
+			)
+		`, maxPerConversation)
+		if err != nil {
+			return deleted, fmt.Errorf("failed to prune excess messages: %w", err)
+		}
+		affected, _ := result.RowsAffected()
+		deleted += affected
+	}
+
+	return deleted, nil
+}
@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// chatMigrations holds the schema migrations for the chat database
+// Migrations are applied in order and the schema version is the index plus one,
+// so existing entries must never be edited, only appended to
+var chatMigrations = []string{
+	// 1: chats and their messages
+	`
+	CREATE TABLE chats (
+		id TEXT PRIMARY KEY,
+		title TEXT NOT NULL,
+		created_at TEXT NOT NULL,
+		updated_at TEXT NOT NULL
+	);
+	CREATE TABLE messages (
+		chat_id TEXT NOT NULL,
+		position INTEGER NOT NULL,
+		id TEXT NOT NULL,
+		role TEXT NOT NULL,
+		content TEXT NOT NULL,
+		type TEXT NOT NULL,
+		images TEXT,
+		created_at TEXT NOT NULL,
+		PRIMARY KEY (chat_id, position)
+	);
+	CREATE INDEX idx_chats_updated_at ON chats(updated_at);
+	`,
+	// 2: rolling summary of older messages
+	`
+	ALTER TABLE chats ADD COLUMN summary TEXT NOT NULL DEFAULT '';
+	ALTER TABLE chats ADD COLUMN summarized_count INTEGER NOT NULL DEFAULT 0;
+	`,
+	// 3: tool calls and tool results
+	`
+	ALTER TABLE messages ADD COLUMN tool_calls TEXT;
+	ALTER TABLE messages ADD COLUMN tool_name TEXT NOT NULL DEFAULT '';
+	`,
+}
+
+// documentMigrations holds the schema migrations for the document database
+// The same append-only rule as for chatMigrations applies
+var documentMigrations = []string{
+	// 1: documents and their searchable chunks
+	`
+	CREATE TABLE documents (
+		id TEXT PRIMARY KEY,
+		conversation_id TEXT NOT NULL,
+		file_name TEXT NOT NULL,
+		mime_type TEXT NOT NULL,
+		pages INTEGER NOT NULL,
+		chunk_count INTEGER NOT NULL,
+		created_at TEXT NOT NULL
+	);
+	CREATE TABLE document_chunks (
+		document_id TEXT NOT NULL,
+		position INTEGER NOT NULL,
+		page INTEGER NOT NULL,
+		content TEXT NOT NULL,
+		embedding BLOB,
+		PRIMARY KEY (document_id, position)
+	);
+	CREATE INDEX idx_documents_conversation ON documents(conversation_id, file_name);
+	`,
+}
+
+// messageLogMigrations holds the schema migrations for the group message log
+// The same append-only rule as for chatMigrations applies
+var messageLogMigrations = []string{
+	// 1: logged group messages
+	`
+	CREATE TABLE logged_messages (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		conversation_id TEXT NOT NULL,
+		message_id TEXT NOT NULL,
+		sender_id TEXT NOT NULL,
+		sender_name TEXT NOT NULL,
+		content TEXT NOT NULL,
+		from_bot INTEGER NOT NULL,
+		timestamp TEXT NOT NULL
+	);
+	CREATE INDEX idx_logged_messages_conversation ON logged_messages(conversation_id, timestamp);
+	`,
+}
+
+// jobMigrations holds the schema migrations for the scheduled job database
+// The same append-only rule as for chatMigrations applies
+var jobMigrations = []string{
+	// 1: scheduled jobs
+	`
+	CREATE TABLE scheduled_jobs (
+		id TEXT PRIMARY KEY,
+		chat_jid TEXT NOT NULL,
+		message TEXT NOT NULL,
+		cron TEXT NOT NULL,
+		next_run TEXT NOT NULL,
+		last_run TEXT NOT NULL,
+		created_by TEXT NOT NULL,
+		created_at TEXT NOT NULL
+	);
+	CREATE INDEX idx_scheduled_jobs_next_run ON scheduled_jobs(next_run);
+	CREATE INDEX idx_scheduled_jobs_chat ON scheduled_jobs(chat_jid);
+	`,
+}
+
+// migrate brings the named migration set up to date, applying each pending migration in its own transaction
+// Versions are recorded per set, so stores sharing a database file don't skip each other's migrations
+func migrate(db *sql.DB, name string, migrations []string) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			name TEXT NOT NULL,
+			version INTEGER NOT NULL,
+			applied_at TEXT NOT NULL,
+			PRIMARY KEY (name, version)
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+
+	var current int
+	if err := db.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_migrations WHERE name = ?`, name).Scan(&current); err != nil {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+
+	for i := current; i < len(migrations); i++ {
+		version := i + 1
+
+		tx, err := db.Begin()
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(migrations[i]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to apply %s migration %d: %w", name, version, err)
+		}
+
+		if _, err := tx.Exec(`INSERT INTO schema_migrations (name, version, applied_at) VALUES (?, ?, ?)`,
+			name, version, time.Now().Format(time.RFC3339)); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to record %s migration %d: %w", name, version, err)
+		}
+
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("failed to commit %s migration %d: %w", name, version, err)
+		}
+	}
+
+	return nil
+}
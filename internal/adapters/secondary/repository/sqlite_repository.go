@@ -0,0 +1,314 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/vibin/chat-bot/internal/core/domain"
+	"github.com/vibin/chat-bot/internal/logger"
+)
+
+// timeLayout is a fixed-width RFC 3339 layout so stored timestamps sort correctly as text
+const timeLayout = "2006-01-02T15:04:05.000000000Z07:00"
+
+// SQLiteRepository implements the ChatRepositoryPort interface with SQLite storage
+type SQLiteRepository struct {
+	db     *sql.DB
+	logger logger.Logger
+}
+
+// NewSQLiteRepository opens the chat database at dbPath and applies any pending migrations
+func NewSQLiteRepository(dbPath string, log logger.Logger) (*SQLiteRepository, error) {
+	log.Info("Opening SQLite chat repository", "path", dbPath)
+
+	db, err := openSQLite(dbPath, "chats", chatMigrations)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SQLiteRepository{
+		db:     db,
+		logger: log,
+	}, nil
+}
+
+// openSQLite opens a SQLite database and applies any pending migrations of the named set
+func openSQLite(dbPath, name string, migrations []string) (*sql.DB, error) {
+	dbURI := fmt.Sprintf("file:%s?_journal=WAL&_synchronous=NORMAL&_busy_timeout=5000", dbPath)
+	db, err := sql.Open("sqlite3", dbURI)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open SQLite database: %w", err)
+	}
+
+	// A single connection serializes writes and avoids "database is locked" errors
+	db.SetMaxOpenConns(1)
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to ping SQLite database: %w", err)
+	}
+
+	if err := migrate(db, name, migrations); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// Close closes the database connection
+func (r *SQLiteRepository) Close() error {
+	return r.db.Close()
+}
+
+// SaveChat saves a chat and its messages
+func (r *SQLiteRepository) SaveChat(ctx context.Context, chat *domain.Chat) error {
+	r.logger.Info("Saving chat", "chat_id", chat.ID)
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO chats (id, title, summary, summarized_count, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET
+			title = excluded.title,
+			summary = excluded.summary,
+			summarized_count = excluded.summarized_count,
+			updated_at = excluded.updated_at
+	`, chat.ID, chat.Title, chat.Summary, chat.SummarizedCount, formatTime(chat.CreatedAt), formatTime(chat.UpdatedAt))
+	if err != nil {
+		return fmt.Errorf("failed to save chat: %w", err)
+	}
+
+	start, err := r.storedPrefix(ctx, tx, chat)
+	if err != nil {
+		return err
+	}
+
+	// Anything after the matching prefix is rewritten
+	if _, err := tx.ExecContext(ctx, `DELETE FROM messages WHERE chat_id = ? AND position >= ?`, chat.ID, start); err != nil {
+		return fmt.Errorf("failed to delete messages: %w", err)
+	}
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO messages (chat_id, position, id, role, content, type, images, tool_calls, tool_name, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for i := start; i < len(chat.Messages); i++ {
+		msg := chat.Messages[i]
+
+		images, err := encodeJSON(msg.Images, len(msg.Images))
+		if err != nil {
+			return fmt.Errorf("failed to encode message images: %w", err)
+		}
+		toolCalls, err := encodeJSON(msg.ToolCalls, len(msg.ToolCalls))
+		if err != nil {
+			return fmt.Errorf("failed to encode tool calls: %w", err)
+		}
+
+		_, err = stmt.ExecContext(ctx, chat.ID, i, msg.ID, msg.Role, msg.Content, string(msg.Type), images, toolCalls, msg.ToolName, formatTime(msg.CreatedAt))
+		if err != nil {
+			return fmt.Errorf("failed to save message: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// storedPrefix returns how many leading messages of the chat are already stored unchanged
+// Messages are append-only in practice, so usually only the new tail has to be written
+func (r *SQLiteRepository) storedPrefix(ctx context.Context, tx *sql.Tx, chat *domain.Chat) (int, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT id FROM messages WHERE chat_id = ? ORDER BY position`, chat.ID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read messages: %w", err)
+	}
+	defer rows.Close()
+
+	// Every stored ID is compared, so a history rewritten anywhere is written again from the first change
+	prefix := 0
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return 0, err
+		}
+		if prefix >= len(chat.Messages) || chat.Messages[prefix].ID != id {
+			break
+		}
+		prefix++
+	}
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	return prefix, nil
+}
+
+// GetChat retrieves a chat by ID
+func (r *SQLiteRepository) GetChat(ctx context.Context, id string) (*domain.Chat, error) {
+	r.logger.Info("Getting chat", "chat_id", id)
+
+	var chat domain.Chat
+	var createdAt, updatedAt string
+	err := r.db.QueryRowContext(ctx, `SELECT id, title, summary, summarized_count, created_at, updated_at FROM chats WHERE id = ?`, id).
+		Scan(&chat.ID, &chat.Title, &chat.Summary, &chat.SummarizedCount, &createdAt, &updatedAt)
+	if err == sql.ErrNoRows {
+		r.logger.Warn("Chat not found", "chat_id", id)
+		return nil, errors.New("chat not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chat: %w", err)
+	}
+	chat.CreatedAt = parseTime(createdAt)
+	chat.UpdatedAt = parseTime(updatedAt)
+
+	messages, err := r.loadMessages(ctx, `WHERE chat_id = ?`, id)
+	if err != nil {
+		return nil, err
+	}
+	chat.Messages = messages[id]
+	if chat.Messages == nil {
+		chat.Messages = []domain.Message{}
+	}
+
+	return &chat, nil
+}
+
+// ListChats returns all chats, most recently updated first
+func (r *SQLiteRepository) ListChats(ctx context.Context) ([]*domain.Chat, error) {
+	r.logger.Info("Listing all chats")
+
+	rows, err := r.db.QueryContext(ctx, `SELECT id, title, summary, summarized_count, created_at, updated_at FROM chats ORDER BY updated_at DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list chats: %w", err)
+	}
+	defer rows.Close()
+
+	chats := []*domain.Chat{}
+	for rows.Next() {
+		var chat domain.Chat
+		var createdAt, updatedAt string
+		if err := rows.Scan(&chat.ID, &chat.Title, &chat.Summary, &chat.SummarizedCount, &createdAt, &updatedAt); err != nil {
+			return nil, err
+		}
+		chat.CreatedAt = parseTime(createdAt)
+		chat.UpdatedAt = parseTime(updatedAt)
+		chats = append(chats, &chat)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	messages, err := r.loadMessages(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	for _, chat := range chats {
+		chat.Messages = messages[chat.ID]
+		if chat.Messages == nil {
+			chat.Messages = []domain.Message{}
+		}
+	}
+
+	return chats, nil
+}
+
+// loadMessages loads messages matching the where clause, grouped by chat ID in order
+func (r *SQLiteRepository) loadMessages(ctx context.Context, where string, args ...interface{}) (map[string][]domain.Message, error) {
+	query := `SELECT chat_id, id, role, content, type, images, tool_calls, tool_name, created_at FROM messages ` + where + ` ORDER BY chat_id, position`
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load messages: %w", err)
+	}
+	defer rows.Close()
+
+	result := make(map[string][]domain.Message)
+	for rows.Next() {
+		var chatID, msgType, createdAt string
+		var images, toolCalls sql.NullString
+		var msg domain.Message
+		if err := rows.Scan(&chatID, &msg.ID, &msg.Role, &msg.Content, &msgType, &images, &toolCalls, &msg.ToolName, &createdAt); err != nil {
+			return nil, err
+		}
+
+		msg.Type = domain.MessageType(msgType)
+		msg.CreatedAt = parseTime(createdAt)
+		if images.Valid {
+			if err := json.Unmarshal([]byte(images.String), &msg.Images); err != nil {
+				r.logger.Warn("Failed to decode message images", "chat_id", chatID, "message_id", msg.ID, "error", err)
+			}
+		}
+		if toolCalls.Valid {
+			if err := json.Unmarshal([]byte(toolCalls.String), &msg.ToolCalls); err != nil {
+				r.logger.Warn("Failed to decode tool calls", "chat_id", chatID, "message_id", msg.ID, "error", err)
+			}
+		}
+
+		result[chatID] = append(result[chatID], msg)
+	}
+
+	return result, rows.Err()
+}
+
+// DeleteChat deletes a chat and its messages by ID
+func (r *SQLiteRepository) DeleteChat(ctx context.Context, id string) error {
+	r.logger.Info("Deleting chat", "chat_id", id)
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM chats WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete chat: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		r.logger.Warn("Chat not found for deletion", "chat_id", id)
+		return errors.New("chat not found")
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM messages WHERE chat_id = ?`, id); err != nil {
+		return fmt.Errorf("failed to delete messages: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// encodeJSON encodes a non-empty list as JSON, storing NULL when it has no elements
+func encodeJSON(value interface{}, length int) (sql.NullString, error) {
+	if length == 0 {
+		return sql.NullString{}, nil
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return sql.NullString{}, err
+	}
+	return sql.NullString{String: string(data), Valid: true}, nil
+}
+
+// formatTime formats a timestamp for storage
+func formatTime(t time.Time) string {
+	return t.UTC().Format(timeLayout)
+}
+
+// parseTime parses a stored timestamp, returning the zero time if it is malformed
+func parseTime(value string) time.Time {
+	t, _ := time.Parse(timeLayout, value)
+	return t
+}
@@ -0,0 +1,174 @@
+package speech
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/vibin/chat-bot/config"
+	"github.com/vibin/chat-bot/internal/logger"
+)
+
+// WhisperAdapter implements the SpeechToTextPort interface for local whisper servers
+// It speaks either the whisper.cpp server /inference API or the OpenAI
+// /v1/audio/transcriptions API used by faster-whisper-server
+type WhisperAdapter struct {
+	httpClient *http.Client
+	config     *config.SpeechConfig
+	logger     logger.Logger
+}
+
+// whisperResponse is the JSON response of both transcription APIs
+type whisperResponse struct {
+	Text  string      `json:"text"`
+	Error interface{} `json:"error,omitempty"`
+}
+
+// NewWhisperAdapter creates a new WhisperAdapter
+func NewWhisperAdapter(config *config.SpeechConfig, log logger.Logger) (*WhisperAdapter, error) {
+	log.Info("Initializing whisper adapter", "api", config.API, "endpoint", config.Endpoint)
+
+	if config.Endpoint == "" {
+		return nil, fmt.Errorf("speech endpoint is not configured")
+	}
+	switch config.API {
+	case "whispercpp", "openai", "":
+	default:
+		return nil, fmt.Errorf("unknown speech api: %s", config.API)
+	}
+
+	timeout := config.TimeoutSeconds * time.Second
+	if timeout <= 0 {
+		timeout = 120 * time.Second
+	}
+
+	return &WhisperAdapter{
+		httpClient: &http.Client{Timeout: timeout},
+		config:     config,
+		logger:     log,
+	}, nil
+}
+
+// Transcribe sends an audio file to the whisper server and returns the transcript
+func (a *WhisperAdapter) Transcribe(ctx context.Context, audio []byte, mimeType string) (string, error) {
+	if len(audio) == 0 {
+		return "", fmt.Errorf("audio is empty")
+	}
+
+	body, contentType, err := a.buildForm(audio, mimeType)
+	if err != nil {
+		return "", fmt.Errorf("failed to build transcription request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", a.url(), body)
+	if err != nil {
+		return "", fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", contentType)
+
+	start := time.Now()
+	resp, err := a.httpClient.Do(httpReq)
+	if err != nil {
+		a.logger.Error("Failed to send transcription request", "error", err)
+		return "", fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		a.logger.Error("Received error response", "status", resp.Status, "body", string(respBody))
+		return "", fmt.Errorf("received error response: %s", resp.Status)
+	}
+
+	var transcription whisperResponse
+	if err := json.Unmarshal(respBody, &transcription); err != nil {
+		return "", fmt.Errorf("failed to parse response: %w", err)
+	}
+	if transcription.Error != nil {
+		return "", fmt.Errorf("whisper error: %v", transcription.Error)
+	}
+
+	text := strings.TrimSpace(transcription.Text)
+	a.logger.Info("Transcribed audio",
+		"size_bytes", len(audio),
+		"characters", len(text),
+		"duration", time.Since(start))
+
+	return text, nil
+}
+
+// url returns the transcription endpoint for the configured API
+func (a *WhisperAdapter) url() string {
+	base := strings.TrimSuffix(a.config.Endpoint, "/")
+	if a.config.API == "openai" {
+		return base + "/v1/audio/transcriptions"
+	}
+	return base + "/inference"
+}
+
+// buildForm builds the multipart form with the audio file and the API's options
+func (a *WhisperAdapter) buildForm(audio []byte, mimeType string) (io.Reader, string, error) {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+
+	part, err := writer.CreateFormFile("file", "audio"+audioExtension(mimeType))
+	if err != nil {
+		return nil, "", err
+	}
+	if _, err := part.Write(audio); err != nil {
+		return nil, "", err
+	}
+
+	fields := map[string]string{"response_format": "json"}
+	if a.config.Language != "" {
+		fields["language"] = a.config.Language
+	}
+	if a.config.API == "openai" {
+		if a.config.Model != "" {
+			fields["model"] = a.config.Model
+		}
+	} else {
+		fields["temperature"] = "0.0"
+	}
+
+	for name, value := range fields {
+		if err := writer.WriteField(name, value); err != nil {
+			return nil, "", err
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, "", err
+	}
+
+	return &buf, writer.FormDataContentType(), nil
+}
+
+// audioExtension returns a file extension so the server can pick a decoder
+func audioExtension(mimeType string) string {
+	mimeType = strings.ToLower(strings.TrimSpace(strings.Split(mimeType, ";")[0]))
+	switch mimeType {
+	case "audio/ogg", "audio/opus":
+		return ".ogg"
+	case "audio/mpeg", "audio/mp3":
+		return ".mp3"
+	case "audio/mp4", "audio/m4a", "audio/aac":
+		return ".m4a"
+	case "audio/wav", "audio/x-wav", "audio/wave":
+		return ".wav"
+	case "audio/webm":
+		return ".webm"
+	default:
+		return ".ogg"
+	}
+}
@@ -0,0 +1,74 @@
+package tools
+
+import (
+	"context"
+	"encoding/base64"
+
+	"github.com/vibin/chat-bot/internal/adapters/secondary/imagegen"
+	"github.com/vibin/chat-bot/internal/core/ports"
+)
+
+// imageSizes maps the orientation argument to the generator's image sizes
+var imageSizes = map[string]string{
+	"landscape": "512x256",
+	"square":    "512x512",
+	"portrait":  "256x512",
+}
+
+// ImageGenerationTool lets the LLM create an image from a text prompt
+// The image is attached to the tool result and delivered to the user
+type ImageGenerationTool struct {
+	generator *imagegen.ImageGenerator
+}
+
+// NewImageGenerationTool creates a new ImageGenerationTool
+func NewImageGenerationTool(generator *imagegen.ImageGenerator) *ImageGenerationTool {
+	return &ImageGenerationTool{generator: generator}
+}
+
+// Name returns the tool name
+func (t *ImageGenerationTool) Name() string {
+	return "generate_image"
+}
+
+// Description returns the tool description
+func (t *ImageGenerationTool) Description() string {
+	return "Create an image from a detailed text description when the user asks for a picture, drawing or image."
+}
+
+// Parameters returns the JSON schema of the tool arguments
+func (t *ImageGenerationTool) Parameters() map[string]interface{} {
+	schema := objectSchema(map[string]string{
+		"prompt":      "A detailed English description of the image",
+		"orientation": "The image orientation",
+	}, "prompt")
+
+	properties := schema["properties"].(map[string]interface{})
+	properties["orientation"].(map[string]interface{})["enum"] = []string{"landscape", "square", "portrait"}
+
+	return schema
+}
+
+// Execute generates the image
+func (t *ImageGenerationTool) Execute(ctx context.Context, arguments map[string]interface{}) (ports.ToolResult, error) {
+	prompt, err := stringArg(arguments, "prompt")
+	if err != nil {
+		return ports.ToolResult{}, err
+	}
+
+	orientation, _ := arguments["orientation"].(string)
+	size, ok := imageSizes[orientation]
+	if !ok {
+		size = imageSizes["landscape"]
+	}
+
+	imageData, err := t.generator.GenerateImage(ctx, prompt, size)
+	if err != nil {
+		return ports.ToolResult{}, err
+	}
+
+	return ports.ToolResult{
+		Content: "The image was generated and will be sent to the user. Describe it briefly in your answer.",
+		Images:  []string{base64.StdEncoding.EncodeToString(imageData)},
+	}, nil
+}
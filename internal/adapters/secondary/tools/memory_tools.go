@@ -0,0 +1,122 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/vibin/chat-bot/internal/core/ports"
+)
+
+// defaultMemoryUser is used for memories saved outside a WhatsApp conversation
+const defaultMemoryUser = "web"
+
+// MemoryStore is the persistent memory storage used by the memory tools
+type MemoryStore interface {
+	AddMemory(userID, conversationID, content string) error
+	GetMemoriesAsStrings(userID, conversationID string) ([]string, error)
+}
+
+// memoryScope returns the user and conversation a memory tool call applies to
+func memoryScope(ctx context.Context) (string, string, error) {
+	scope := ports.ToolScopeFrom(ctx)
+	if scope.ChatID == "" {
+		return "", "", fmt.Errorf("memory tools need a conversation")
+	}
+
+	userID := scope.UserID
+	if userID == "" {
+		userID = defaultMemoryUser
+	}
+
+	return userID, scope.ChatID, nil
+}
+
+// SaveMemoryTool lets the LLM remember a fact about the current user
+type SaveMemoryTool struct {
+	store MemoryStore
+}
+
+// NewSaveMemoryTool creates a new SaveMemoryTool
+func NewSaveMemoryTool(store MemoryStore) *SaveMemoryTool {
+	return &SaveMemoryTool{store: store}
+}
+
+// Name returns the tool name
+func (t *SaveMemoryTool) Name() string {
+	return "save_memory"
+}
+
+// Description returns the tool description
+func (t *SaveMemoryTool) Description() string {
+	return "Remember a lasting fact or preference about the user, for example their name, birthday or favourite food."
+}
+
+// Parameters returns the JSON schema of the tool arguments
+func (t *SaveMemoryTool) Parameters() map[string]interface{} {
+	return objectSchema(map[string]string{
+		"content": "The fact to remember, written as a short sentence",
+	}, "content")
+}
+
+// Execute stores the memory for the current user and conversation
+func (t *SaveMemoryTool) Execute(ctx context.Context, arguments map[string]interface{}) (ports.ToolResult, error) {
+	content, err := stringArg(arguments, "content")
+	if err != nil {
+		return ports.ToolResult{}, err
+	}
+
+	userID, conversationID, err := memoryScope(ctx)
+	if err != nil {
+		return ports.ToolResult{}, err
+	}
+
+	if err := t.store.AddMemory(userID, conversationID, content); err != nil {
+		return ports.ToolResult{}, err
+	}
+
+	return ports.ToolResult{Content: "Saved."}, nil
+}
+
+// RecallMemoryTool lets the LLM look up what it remembers about the current user
+type RecallMemoryTool struct {
+	store MemoryStore
+}
+
+// NewRecallMemoryTool creates a new RecallMemoryTool
+func NewRecallMemoryTool(store MemoryStore) *RecallMemoryTool {
+	return &RecallMemoryTool{store: store}
+}
+
+// Name returns the tool name
+func (t *RecallMemoryTool) Name() string {
+	return "recall_memories"
+}
+
+// Description returns the tool description
+func (t *RecallMemoryTool) Description() string {
+	return "Look up the facts and preferences remembered about the user in this conversation."
+}
+
+// Parameters returns the JSON schema of the tool arguments
+func (t *RecallMemoryTool) Parameters() map[string]interface{} {
+	return objectSchema(map[string]string{})
+}
+
+// Execute returns the stored memories for the current user and conversation
+func (t *RecallMemoryTool) Execute(ctx context.Context, arguments map[string]interface{}) (ports.ToolResult, error) {
+	userID, conversationID, err := memoryScope(ctx)
+	if err != nil {
+		return ports.ToolResult{}, err
+	}
+
+	memories, err := t.store.GetMemoriesAsStrings(userID, conversationID)
+	if err != nil {
+		return ports.ToolResult{}, err
+	}
+	if len(memories) == 0 {
+		return ports.ToolResult{Content: "Nothing is remembered about this user yet."}, nil
+	}
+
+	return ports.ToolResult{Content: "- " + strings.Join(memories, "\n- ")}, nil
+}
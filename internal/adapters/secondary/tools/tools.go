@@ -0,0 +1,37 @@
+package tools
+
+import (
+	"fmt"
+	"strings"
+)
+
+// stringArg returns a required, non-empty string argument
+func stringArg(arguments map[string]interface{}, name string) (string, error) {
+	value, ok := arguments[name].(string)
+	if !ok || strings.TrimSpace(value) == "" {
+		return "", fmt.Errorf("missing required argument %q", name)
+	}
+	return strings.TrimSpace(value), nil
+}
+
+// objectSchema builds a JSON schema for an object with string properties
+// Each property maps to its description, required lists the mandatory ones
+func objectSchema(properties map[string]string, required ...string) map[string]interface{} {
+	props := make(map[string]interface{}, len(properties))
+	for name, description := range properties {
+		props[name] = map[string]interface{}{
+			"type":        "string",
+			"description": description,
+		}
+	}
+
+	if required == nil {
+		required = []string{}
+	}
+
+	return map[string]interface{}{
+		"type":       "object",
+		"properties": props,
+		"required":   required,
+	}
+}
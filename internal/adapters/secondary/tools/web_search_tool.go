@@ -0,0 +1,66 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/vibin/chat-bot/internal/core/ports"
+)
+
+// maxSearchResults limits how many search results are returned to the LLM
+const maxSearchResults = 5
+
+// WebSearchTool lets the LLM search the web for current information
+type WebSearchTool struct {
+	search ports.WebSearchPort
+}
+
+// NewWebSearchTool creates a new WebSearchTool
+func NewWebSearchTool(search ports.WebSearchPort) *WebSearchTool {
+	return &WebSearchTool{search: search}
+}
+
+// Name returns the tool name
+func (t *WebSearchTool) Name() string {
+	return "web_search"
+}
+
+// Description returns the tool description
+func (t *WebSearchTool) Description() string {
+	return "Search the web for current information such as news, weather, scores, prices or recent events."
+}
+
+// Parameters returns the JSON schema of the tool arguments
+func (t *WebSearchTool) Parameters() map[string]interface{} {
+	return objectSchema(map[string]string{
+		"query": "The search query",
+	}, "query")
+}
+
+// Execute runs the search and returns the top results as text
+func (t *WebSearchTool) Execute(ctx context.Context, arguments map[string]interface{}) (ports.ToolResult, error) {
+	query, err := stringArg(arguments, "query")
+	if err != nil {
+		return ports.ToolResult{}, err
+	}
+
+	results, err := t.search.Search(ctx, query)
+	if err != nil {
+		return ports.ToolResult{}, err
+	}
+	if len(results) == 0 {
+		return ports.ToolResult{Content: "No results found."}, nil
+	}
+
+	if len(results) > maxSearchResults {
+		results = results[:maxSearchResults]
+	}
+
+	var sb strings.Builder
+	for i, result := range results {
+		sb.WriteString(fmt.Sprintf("[%d] %s\nLink: %s\nSnippet: %s\n\n", i+1, result.Title, result.Link, result.Snippet))
+	}
+
+	return ports.ToolResult{Content: strings.TrimSpace(sb.String())}, nil
+}
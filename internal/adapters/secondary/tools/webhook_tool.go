@@ -0,0 +1,65 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/vibin/chat-bot/config"
+	"github.com/vibin/chat-bot/internal/core/ports"
+)
+
+// maxWebhookResponse limits how much of a webhook response is returned to the LLM
+const maxWebhookResponse = 4000
+
+// WebhookTool forwards a question to a chat webhook service, such as an n8n workflow
+type WebhookTool struct {
+	service config.WebhookServiceConfig
+	webhook ports.WebhookPort
+}
+
+// NewWebhookTool creates a new WebhookTool
+func NewWebhookTool(service config.WebhookServiceConfig, webhook ports.WebhookPort) *WebhookTool {
+	return &WebhookTool{
+		service: service,
+		webhook: webhook,
+	}
+}
+
+// Name returns the tool name
+func (t *WebhookTool) Name() string {
+	return t.service.Name + "_service"
+}
+
+// Description returns the tool description
+func (t *WebhookTool) Description() string {
+	return t.service.Description
+}
+
+// Parameters returns the JSON schema of the tool arguments
+func (t *WebhookTool) Parameters() map[string]interface{} {
+	return objectSchema(map[string]string{
+		"message": "The question or request to send to the service",
+	}, "message")
+}
+
+// Execute sends the message to the service and returns its reply
+func (t *WebhookTool) Execute(ctx context.Context, arguments map[string]interface{}) (ports.ToolResult, error) {
+	message, err := stringArg(arguments, "message")
+	if err != nil {
+		return ports.ToolResult{}, err
+	}
+
+	input := ports.WebhookInput{
+		ChatInput: message,
+		Sender:    ports.ToolScopeFrom(ctx).UserID,
+	}
+
+	reply, err := t.webhook.Call(ctx, t.service, input)
+	if err != nil {
+		return ports.ToolResult{}, err
+	}
+
+	if len(reply) > maxWebhookResponse {
+		reply = reply[:maxWebhookResponse]
+	}
+	return ports.ToolResult{Content: reply}, nil
+}
@@ -0,0 +1,78 @@
+package webhook
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	defaultFailureThreshold = 5
+	defaultCooldown         = 60 * time.Second
+)
+
+// circuitBreaker stops calls to a service after repeated failures
+// Once the cooldown has passed a single trial call is let through, and its outcome
+// closes the breaker again or restarts the cooldown
+type circuitBreaker struct {
+	mutex     sync.Mutex
+	failures  int
+	openUntil time.Time
+	trial     bool // A trial call is in flight
+}
+
+// allow reports whether a call may be made now
+func (b *circuitBreaker) allow(threshold int, now time.Time) bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.failures < threshold {
+		return true
+	}
+	if now.Before(b.openUntil) || b.trial {
+		return false
+	}
+
+	b.trial = true
+	return true
+}
+
+// isOpen reports whether calls are currently refused
+func (b *circuitBreaker) isOpen(threshold int, now time.Time) bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return b.failures >= threshold && now.Before(b.openUntil)
+}
+
+// success closes the breaker
+func (b *circuitBreaker) success() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.failures = 0
+	b.trial = false
+}
+
+// abandon ends a call without an outcome, such as one the caller cancelled
+// A trial call is let through again, the failure count is left as it was
+func (b *circuitBreaker) abandon() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.trial = false
+}
+
+// failure records a failed call and reports whether the breaker is now open
+func (b *circuitBreaker) failure(threshold int, cooldown time.Duration, now time.Time) bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.failures++
+	b.trial = false
+	if b.failures < threshold {
+		return false
+	}
+
+	b.openUntil = now.Add(cooldown)
+	return true
+}
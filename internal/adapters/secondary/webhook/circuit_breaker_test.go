@@ -0,0 +1,137 @@
+package webhook
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	const (
+		threshold = 2
+		cooldown  = time.Minute
+	)
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	// Each step acts on the breaker at an offset from start and checks whether calls are allowed afterwards
+	type step struct {
+		action string // "fail", "succeed", "abandon" or "allow"
+		at     time.Duration
+		allow  bool // For "allow", whether the call is let through
+		open   bool // Whether isOpen reports the breaker open after the step
+	}
+
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name: "stays closed below the threshold",
+			steps: []step{
+				{action: "fail", at: 0, open: false},
+				{action: "allow", at: 0, allow: true, open: false},
+			},
+		},
+		{
+			name: "opens at the threshold",
+			steps: []step{
+				{action: "fail", at: 0},
+				{action: "fail", at: 0, open: true},
+				{action: "allow", at: time.Second, allow: false, open: true},
+			},
+		},
+		{
+			name: "a success resets the failure count",
+			steps: []step{
+				{action: "fail", at: 0},
+				{action: "succeed", at: 0},
+				{action: "fail", at: 0, open: false},
+				{action: "allow", at: 0, allow: true},
+			},
+		},
+		{
+			name: "lets a single trial call through after the cooldown",
+			steps: []step{
+				{action: "fail", at: 0},
+				{action: "fail", at: 0, open: true},
+				{action: "allow", at: cooldown, allow: true, open: false},
+				{action: "allow", at: cooldown, allow: false, open: false},
+			},
+		},
+		{
+			name: "a successful trial closes the breaker",
+			steps: []step{
+				{action: "fail", at: 0},
+				{action: "fail", at: 0, open: true},
+				{action: "allow", at: cooldown, allow: true},
+				{action: "succeed", at: cooldown},
+				{action: "allow", at: cooldown, allow: true},
+				{action: "allow", at: cooldown, allow: true, open: false},
+			},
+		},
+		{
+			name: "a failed trial restarts the cooldown",
+			steps: []step{
+				{action: "fail", at: 0},
+				{action: "fail", at: 0, open: true},
+				{action: "allow", at: cooldown, allow: true},
+				{action: "fail", at: cooldown, open: true},
+				{action: "allow", at: cooldown + cooldown/2, allow: false, open: true},
+				{action: "allow", at: 2 * cooldown, allow: true},
+			},
+		},
+		{
+			name: "an abandoned call keeps the failure count",
+			steps: []step{
+				{action: "fail", at: 0},
+				{action: "abandon", at: 0},
+				{action: "fail", at: 0, open: true},
+			},
+		},
+		{
+			name: "an abandoned trial lets another trial through",
+			steps: []step{
+				{action: "fail", at: 0},
+				{action: "fail", at: 0, open: true},
+				{action: "allow", at: cooldown, allow: true},
+				{action: "abandon", at: cooldown},
+				{action: "allow", at: cooldown, allow: true},
+				{action: "allow", at: cooldown, allow: false},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b circuitBreaker
+			for i, s := range tt.steps {
+				now := start.Add(s.at)
+				switch s.action {
+				case "fail":
+					b.failure(threshold, cooldown, now)
+				case "succeed":
+					b.success()
+				case "abandon":
+					b.abandon()
+				case "allow":
+					if got := b.allow(threshold, now); got != s.allow {
+						t.Fatalf("step %d: expected allow %v, got %v", i, s.allow, got)
+					}
+				}
+				if got := b.isOpen(threshold, now); got != s.open {
+					t.Fatalf("step %d: expected open %v, got %v", i, s.open, got)
+				}
+			}
+		})
+	}
+}
+
+func TestCircuitBreakerReportsOpening(t *testing.T) {
+	var b circuitBreaker
+	now := time.Now()
+	if b.failure(2, time.Minute, now) {
+		t.Fatal("expected the first failure not to open the breaker")
+	}
+	if !b.failure(2, time.Minute, now) {
+		t.Fatal("expected the failure reaching the threshold to open the breaker")
+	}
+}
@@ -0,0 +1,433 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"text/template"
+	"time"
+
+	"github.com/vibin/chat-bot/config"
+	"github.com/vibin/chat-bot/internal/core/ports"
+	"github.com/vibin/chat-bot/internal/logger"
+	"github.com/vibin/chat-bot/internal/metrics"
+)
+
+const (
+	defaultTimeout        = 30 * time.Second
+	defaultMaxAttempts    = 1 // Services are only retried when they configure it, since a call may have side effects
+	defaultInitialBackoff = time.Second
+	defaultMaxBackoff     = 10 * time.Second
+
+	defaultSignatureHeader = "X-Signature"
+	defaultTimestampHeader = "X-Timestamp"
+
+	// defaultBodyTemplate is the n8n chat trigger payload
+	defaultBodyTemplate = `{"action": "sendMessage", "chatInput": {{json .ChatInput}}}`
+
+	// maxResponseSize limits how much of a response body is read
+	maxResponseSize = 1 << 20
+)
+
+// templateFuncs are the functions available to body templates
+var templateFuncs = template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+}
+
+// WebhookAdapter implements the WebhookPort interface for JSON webhooks such as n8n workflows
+// Requests are authenticated and retried per service, and each service has its own circuit breaker
+type WebhookAdapter struct {
+	logger   logger.Logger
+	mutex    sync.Mutex
+	breakers map[string]*circuitBreaker // By service name
+}
+
+// statusError is a response with a non-2xx status code
+type statusError struct {
+	service string
+	status  int
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("service %s returned status %d", e.service, e.status)
+}
+
+// permanentError is a failure that retrying cannot fix, such as an invalid request
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// sentError is a failure after the request was sent, such as a timeout waiting for the response
+// The service may have run it, so it is not sent again
+type sentError struct {
+	err error
+}
+
+func (e *sentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *sentError) Unwrap() error {
+	return e.err
+}
+
+// NewWebhookAdapter creates a new WebhookAdapter
+func NewWebhookAdapter(log logger.Logger) *WebhookAdapter {
+	return &WebhookAdapter{
+		logger:   log,
+		breakers: make(map[string]*circuitBreaker),
+	}
+}
+
+// Call renders the service's body template, sends it and extracts the reply from the response
+// It returns an error wrapping ports.ErrServiceDown without calling the service while its breaker is open
+func (a *WebhookAdapter) Call(ctx context.Context, service config.WebhookServiceConfig, input ports.WebhookInput) (string, error) {
+	start := time.Now()
+	reply, err := a.call(ctx, service, input)
+	if errors.Is(err, ports.ErrServiceDown) {
+		metrics.WebhookRequests.WithLabelValues(service.Name, "circuit_open").Inc()
+	} else {
+		metrics.ObserveWebhook(service.Name, metrics.Status(err), start)
+	}
+	return reply, err
+}
+
+// call does the work of Call
+func (a *WebhookAdapter) call(ctx context.Context, service config.WebhookServiceConfig, input ports.WebhookInput) (string, error) {
+	if service.URL == "" {
+		return "", fmt.Errorf("service %s has no url", service.Name)
+	}
+
+	body, err := renderBody(service, input)
+	if err != nil {
+		return "", err
+	}
+
+	threshold, cooldown := breakerSettings(service)
+	breaker := a.breaker(service.Name)
+	if !breaker.allow(threshold, time.Now()) {
+		return "", fmt.Errorf("service %s: %w", service.Name, ports.ErrServiceDown)
+	}
+
+	responseBody, err := a.sendWithRetry(ctx, service, body)
+	if err != nil {
+		if ctx.Err() != nil {
+			// The caller gave up or timed out, which says nothing about the service
+			breaker.abandon()
+			return "", err
+		}
+		if !isServiceFailure(err) {
+			// The service answered, so it is up
+			breaker.success()
+			return "", err
+		}
+		if breaker.failure(threshold, cooldown, time.Now()) {
+			a.logger.Warn("Webhook service is down, pausing calls", "service", service.Name, "cooldown", cooldown)
+		}
+		return "", err
+	}
+	breaker.success()
+
+	reply, err := extractReply(responseBody, service.ResponsePath)
+	if err != nil {
+		a.logger.Error("Failed to read webhook reply", "service", service.Name, "error", err, "body", string(responseBody))
+		return "", err
+	}
+
+	return reply, nil
+}
+
+// Ping checks that a service's URL answers, without triggering the service
+// Any HTTP response counts, since services usually only accept POST
+func (a *WebhookAdapter) Ping(ctx context.Context, service config.WebhookServiceConfig) error {
+	threshold, _ := breakerSettings(service)
+	if a.breaker(service.Name).isOpen(threshold, time.Now()) {
+		return fmt.Errorf("circuit breaker is open: %w", ports.ErrServiceDown)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, service.URL, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// breakerSettings returns the failure threshold and cooldown of a service's circuit breaker
+func breakerSettings(service config.WebhookServiceConfig) (int, time.Duration) {
+	threshold := service.CircuitBreaker.FailureThreshold
+	if threshold <= 0 {
+		threshold = defaultFailureThreshold
+	}
+	cooldown := service.CircuitBreaker.CooldownSeconds * time.Second
+	if cooldown <= 0 {
+		cooldown = defaultCooldown
+	}
+	return threshold, cooldown
+}
+
+// breaker returns the circuit breaker of a service
+func (a *WebhookAdapter) breaker(name string) *circuitBreaker {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	breaker, ok := a.breakers[name]
+	if !ok {
+		breaker = &circuitBreaker{}
+		a.breakers[name] = breaker
+	}
+	return breaker
+}
+
+// sendWithRetry sends the request, retrying failures that may be temporary with exponential backoff
+func (a *WebhookAdapter) sendWithRetry(ctx context.Context, service config.WebhookServiceConfig, body []byte) ([]byte, error) {
+	attempts := service.Retry.MaxAttempts
+	if attempts <= 0 {
+		attempts = defaultMaxAttempts
+	}
+	backoff := service.Retry.InitialBackoffSeconds * time.Second
+	if backoff <= 0 {
+		backoff = defaultInitialBackoff
+	}
+	maxBackoff := service.Retry.MaxBackoffSeconds * time.Second
+	if maxBackoff <= 0 {
+		maxBackoff = defaultMaxBackoff
+	}
+
+	for attempt := 1; ; attempt++ {
+		responseBody, err := a.send(ctx, service, body)
+		if err == nil {
+			return responseBody, nil
+		}
+		if attempt >= attempts || !isRetryable(err) || ctx.Err() != nil {
+			return nil, err
+		}
+
+		a.logger.Warn("Webhook service request failed, retrying", "service", service.Name, "attempt", attempt, "backoff", backoff, "error", err)
+		select {
+		case <-ctx.Done():
+			return nil, err
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+// send makes one authenticated request and returns the response body
+func (a *WebhookAdapter) send(ctx context.Context, service config.WebhookServiceConfig, body []byte) ([]byte, error) {
+	timeout := service.TimeoutSeconds * time.Second
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	method := strings.ToUpper(service.Method)
+	if method == "" {
+		method = http.MethodPost
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, service.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, &permanentError{fmt.Errorf("failed to create request: %w", err)}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range service.Headers {
+		req.Header.Set(name, value)
+	}
+	if err := authenticate(req, service.Auth, body, time.Now()); err != nil {
+		return nil, &permanentError{fmt.Errorf("service %s: %w", service.Name, err)}
+	}
+
+	// Record whether the request reached the wire, failures after that are not retried
+	var sent atomic.Bool
+	req = req.WithContext(httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		WroteRequest: func(httptrace.WroteRequestInfo) { sent.Store(true) },
+	}))
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		err = fmt.Errorf("failed to call service %s: %w", service.Name, err)
+		if sent.Load() {
+			return nil, &sentError{err}
+		}
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	responseBody, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return nil, &sentError{fmt.Errorf("failed to read response: %w", err)}
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		a.logger.Error("Webhook service returned error", "service", service.Name, "status", resp.StatusCode, "body", string(responseBody))
+		return nil, &statusError{service: service.Name, status: resp.StatusCode}
+	}
+
+	return responseBody, nil
+}
+
+// isRetryable reports whether a failed request may be sent again
+// Connection errors before the request was sent, 429 and 5xx responses are retried
+func isRetryable(err error) bool {
+	var sent *sentError
+	if errors.As(err, &sent) {
+		return false
+	}
+	return isServiceFailure(err)
+}
+
+// isServiceFailure reports whether an error counts against the service's circuit breaker
+// Connection errors, timeouts, 429 and 5xx responses do, answers the service chose to give don't
+func isServiceFailure(err error) bool {
+	var permanent *permanentError
+	if errors.As(err, &permanent) {
+		return false
+	}
+
+	var status *statusError
+	if errors.As(err, &status) {
+		return status.status == http.StatusTooManyRequests || status.status >= 500
+	}
+
+	return true
+}
+
+// authenticate adds the service's credentials to a request
+// HMAC signatures cover the timestamp header, a dot and the body: sha256=hex(HMAC(secret, timestamp + "." + body))
+func authenticate(req *http.Request, auth config.WebhookAuthConfig, body []byte, now time.Time) error {
+	switch strings.ToLower(auth.Type) {
+	case "":
+	case "bearer":
+		req.Header.Set("Authorization", "Bearer "+auth.Token)
+	case "basic":
+		req.SetBasicAuth(auth.Username, auth.Password)
+	case "hmac":
+		if auth.Secret == "" {
+			return fmt.Errorf("hmac auth needs a secret")
+		}
+		signatureHeader := auth.SignatureHeader
+		if signatureHeader == "" {
+			signatureHeader = defaultSignatureHeader
+		}
+		timestampHeader := auth.TimestampHeader
+		if timestampHeader == "" {
+			timestampHeader = defaultTimestampHeader
+		}
+
+		timestamp := strconv.FormatInt(now.Unix(), 10)
+		mac := hmac.New(sha256.New, []byte(auth.Secret))
+		mac.Write([]byte(timestamp + "."))
+		mac.Write(body)
+
+		req.Header.Set(timestampHeader, timestamp)
+		req.Header.Set(signatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	default:
+		return fmt.Errorf("unknown auth type %q", auth.Type)
+	}
+	return nil
+}
+
+// renderBody renders the request body from the service's template
+func renderBody(service config.WebhookServiceConfig, input ports.WebhookInput) ([]byte, error) {
+	text := service.BodyTemplate
+	if text == "" {
+		text = defaultBodyTemplate
+	}
+
+	tmpl, err := template.New(service.Name).Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid body template for service %s: %w", service.Name, err)
+	}
+
+	if input.Memories == nil {
+		input.Memories = []string{}
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, input); err != nil {
+		return nil, fmt.Errorf("failed to render body for service %s: %w", service.Name, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// extractReply returns the value at a dot path such as "0.text" in a JSON response
+// Without a path the whole body is the reply
+func extractReply(body []byte, path string) (string, error) {
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		if path == "" {
+			return strings.TrimSpace(string(body)), nil
+		}
+		return "", fmt.Errorf("response is not JSON: %w", err)
+	}
+
+	if path != "" {
+		for _, key := range strings.Split(path, ".") {
+			switch current := value.(type) {
+			case map[string]interface{}:
+				next, ok := current[key]
+				if !ok {
+					return "", fmt.Errorf("response has no %q", path)
+				}
+				value = next
+			case []interface{}:
+				index, err := strconv.Atoi(key)
+				if err != nil || index < 0 || index >= len(current) {
+					return "", fmt.Errorf("response has no %q", path)
+				}
+				value = current[index]
+			default:
+				return "", fmt.Errorf("response has no %q", path)
+			}
+		}
+	}
+
+	switch reply := value.(type) {
+	case string:
+		if strings.TrimSpace(reply) == "" {
+			return "", fmt.Errorf("response has an empty reply")
+		}
+		return reply, nil
+	case nil:
+		return "", fmt.Errorf("response has an empty reply")
+	default:
+		data, err := json.Marshal(reply)
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+	}
+}
@@ -0,0 +1,92 @@
+package webhook
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vibin/chat-bot/config"
+)
+
+func TestAuthenticate(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	body := []byte(`{"action": "sendMessage", "chatInput": "hi"}`)
+
+	tests := []struct {
+		name    string
+		auth    config.WebhookAuthConfig
+		headers map[string]string
+		err     bool
+	}{
+		{
+			name:    "none",
+			auth:    config.WebhookAuthConfig{},
+			headers: map[string]string{"Authorization": ""},
+		},
+		{
+			name:    "bearer",
+			auth:    config.WebhookAuthConfig{Type: "bearer", Token: "abc"},
+			headers: map[string]string{"Authorization": "Bearer abc"},
+		},
+		{
+			name:    "basic",
+			auth:    config.WebhookAuthConfig{Type: "Basic", Username: "bot", Password: "secret"},
+			headers: map[string]string{"Authorization": "Basic Ym90OnNlY3JldA=="},
+		},
+		{
+			// sha256=hex(HMAC-SHA256("key", "1700000000." + body)), the format services verify against
+			name: "hmac with default headers",
+			auth: config.WebhookAuthConfig{Type: "hmac", Secret: "key"},
+			headers: map[string]string{
+				"X-Timestamp": "1700000000",
+				"X-Signature": "sha256=09d0bfaf297b84c609643733930c4c44eebb49d1ea47fd10a78f80a8ae9c8c52",
+			},
+		},
+		{
+			name: "hmac with custom headers",
+			auth: config.WebhookAuthConfig{Type: "hmac", Secret: "key", SignatureHeader: "X-Hub-Signature-256", TimestampHeader: "X-Hub-Timestamp"},
+			headers: map[string]string{
+				"X-Hub-Timestamp":     "1700000000",
+				"X-Hub-Signature-256": "sha256=09d0bfaf297b84c609643733930c4c44eebb49d1ea47fd10a78f80a8ae9c8c52",
+				"X-Signature":         "",
+			},
+		},
+		{
+			name: "hmac without a secret",
+			auth: config.WebhookAuthConfig{Type: "hmac"},
+			err:  true,
+		},
+		{
+			name: "unknown type",
+			auth: config.WebhookAuthConfig{Type: "digest"},
+			err:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodPost, "http://example.com/hook", strings.NewReader(string(body)))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = authenticate(req, tt.auth, body, now)
+			if tt.err {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for name, value := range tt.headers {
+				if got := req.Header.Get(name); got != value {
+					t.Errorf("expected %s %q, got %q", name, value, got)
+				}
+			}
+		})
+	}
+}
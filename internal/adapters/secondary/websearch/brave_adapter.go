@@ -15,6 +15,7 @@ import (
 	"github.com/vibin/chat-bot/internal/core/domain"
 	"github.com/vibin/chat-bot/internal/core/ports"
 	"github.com/vibin/chat-bot/internal/logger"
+	"github.com/vibin/chat-bot/internal/metrics"
 )
 
 const (
@@ -68,9 +69,15 @@ func (a *BraveAdapter) Search(ctx context.Context, query string) ([]ports.Search
 
 	if a.config.BraveAPIKey == "" {
 		a.logger.Error("Brave API key is not configured")
+		metrics.WebSearchRequests.WithLabelValues("brave", "not_configured").Inc()
 		return getMockResults(), nil
 	}
 
+	// Failures fall back to mock results, so the status is only ok once real results are parsed
+	start := time.Now()
+	status := metrics.StatusError
+	defer func() { metrics.ObserveWebSearch("brave", status, start) }()
+
 	// Build the search URL with query parameters
 	searchURL, err := url.Parse(braveSearchBaseURL)
 	if err != nil {
@@ -202,6 +209,7 @@ func (a *BraveAdapter) Search(ctx context.Context, query string) ([]ports.Search
 	}
 
 	a.logger.Info("Brave web search completed", "results_count", len(results))
+	status = metrics.StatusOK
 	return results, nil
 }
 
@@ -228,7 +236,7 @@ func (a *BraveAdapter) FormatSearchQuery(ctx context.Context, userQuery string)
 	)
 
 	// Use the secondary LLM to format the query
-	formattedQuery, err := a.llm.GenerateResponse(ctx, []domain.Message{{Role: "user", Content: prompt}})
+	formattedQuery, err := a.llm.GenerateResponse(ports.WithLLMTask(ctx, domain.LLMTaskSearchQuery), []domain.Message{{Role: "user", Content: prompt}})
 	if err != nil {
 		a.logger.Error("Failed to format search query", "error", err)
 		return userQuery, err // Fall back to original query on error
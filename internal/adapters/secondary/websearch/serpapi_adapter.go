@@ -11,6 +11,7 @@ import (
 	"github.com/vibin/chat-bot/internal/core/domain"
 	"github.com/vibin/chat-bot/internal/core/ports"
 	"github.com/vibin/chat-bot/internal/logger"
+	"github.com/vibin/chat-bot/internal/metrics"
 )
 
 // SerpAPIAdapter implements the WebSearchPort interface using SerpAPI
@@ -34,6 +35,7 @@ func (a *SerpAPIAdapter) Search(ctx context.Context, query string) ([]ports.Sear
 	a.logger.Info("Performing web search", "query", query)
 
 	if a.config.SerpAPIKey == "" {
+		metrics.WebSearchRequests.WithLabelValues("serpapi", "not_configured").Inc()
 		return nil, fmt.Errorf("SerpAPI key is not configured")
 	}
 
@@ -55,7 +57,9 @@ func (a *SerpAPIAdapter) Search(ctx context.Context, query string) ([]ports.Sear
 
 	// Execute search
 	a.logger.Info("Executing SerpAPI search")
+	start := time.Now()
 	data, err := client.GetJSON()
+	metrics.ObserveWebSearch("serpapi", metrics.Status(err), start)
 	if err != nil {
 		a.logger.Error("SerpAPI search failed", "error", err, "error_type", fmt.Sprintf("%T", err))
 		
@@ -115,7 +119,7 @@ func (a *SerpAPIAdapter) FormatSearchQuery(ctx context.Context, userQuery string
 	)
 
 	// Use the secondary LLM to format the query
-	formattedQuery, err := a.llm.GenerateResponse(ctx, []domain.Message{{Role: "user", Content: prompt}})
+	formattedQuery, err := a.llm.GenerateResponse(ports.WithLLMTask(ctx, domain.LLMTaskSearchQuery), []domain.Message{{Role: "user", Content: prompt}})
 	if err != nil {
 		a.logger.Error("Failed to format search query", "error", err)
 		return userQuery, err // Fall back to original query on error
@@ -0,0 +1,45 @@
+package domain
+
+import "time"
+
+// Role is what an admin user or API token may do
+type Role string
+
+const (
+	RoleViewer       Role = "viewer"        // Read-only access to the admin pages and API
+	RoleMemoryEditor Role = "memory-editor" // Viewer, plus editing memories
+	RoleAdmin        Role = "admin"         // Everything, including sending messages and changing settings
+)
+
+// roleRanks orders the roles, each role includes the ones below it
+var roleRanks = map[Role]int{
+	RoleViewer:       1,
+	RoleMemoryEditor: 2,
+	RoleAdmin:        3,
+}
+
+// Valid reports whether the role is known
+func (r Role) Valid() bool {
+	_, ok := roleRanks[r]
+	return ok
+}
+
+// Includes reports whether the role grants everything the required role does
+func (r Role) Includes(required Role) bool {
+	return r.Valid() && roleRanks[r] >= roleRanks[required]
+}
+
+// Identity is who made an admin request
+type Identity struct {
+	Name    string `json:"name"`
+	Role    Role   `json:"role"`
+	IsToken bool   `json:"is_token"` // Authenticated with an API token rather than a login
+}
+
+// Session is a logged-in admin user
+type Session struct {
+	ID        string
+	Identity  Identity
+	CSRFToken string // Must accompany mutating requests made with the session cookie
+	ExpiresAt time.Time
+}
@@ -11,16 +11,26 @@ type Message struct {
 	Content   string     `json:"content"`
 	Type      MessageType `json:"type"`
 	Images    []string   `json:"images,omitempty"`
+	ToolCalls []ToolCall `json:"tool_calls,omitempty"` // Tools the assistant asked to run
+	ToolName  string     `json:"tool_name,omitempty"`  // Tool that produced a tool result message
 	CreatedAt time.Time  `json:"created_at"`
 }
 
+// ToolCall is a request from the LLM to run a tool with the given arguments
+type ToolCall struct {
+	Name      string                 `json:"name"`
+	Arguments map[string]interface{} `json:"arguments"`
+}
+
 // Chat represents a conversation between a user and the LLM
 type Chat struct {
-	ID        string    `json:"id"`
-	Title     string    `json:"title"`
-	Messages  []Message `json:"messages"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID              string    `json:"id"`
+	Title           string    `json:"title"`
+	Messages        []Message `json:"messages"`
+	Summary         string    `json:"summary,omitempty"`          // Rolling summary of older messages no longer sent verbatim
+	SummarizedCount int       `json:"summarized_count,omitempty"` // Number of leading messages covered by Summary
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
 }
 
 // NewMessage creates a new message
@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"time"
+)
+
+// Document is a file shared in a conversation whose text can be searched
+type Document struct {
+	ID             string    `json:"id"`
+	ConversationID string    `json:"conversation_id"`
+	FileName       string    `json:"file_name"`
+	MimeType       string    `json:"mime_type"`
+	Pages          int       `json:"pages"` // 0 for files without pages, such as plain text
+	ChunkCount     int       `json:"chunk_count"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// DocumentPage is the extracted text of a single page
+type DocumentPage struct {
+	Number int // 1-based, 0 for files without pages
+	Text   string
+}
+
+// DocumentChunk is a searchable piece of a document's text
+type DocumentChunk struct {
+	DocumentID string    `json:"document_id"`
+	FileName   string    `json:"file_name"`
+	Position   int       `json:"position"` // Order of the chunk within the document
+	Page       int       `json:"page"`     // Page the chunk was taken from, 0 if unknown
+	Content    string    `json:"content"`
+	Embedding  []float32 `json:"-"` // Normalized embedding, nil when embeddings are disabled
+}
+
+// NewDocument creates a new document for a conversation
+func NewDocument(conversationID, fileName, mimeType string) *Document {
+	return &Document{
+		ID:             generateID(),
+		ConversationID: conversationID,
+		FileName:       fileName,
+		MimeType:       mimeType,
+		CreatedAt:      time.Now(),
+	}
+}
@@ -0,0 +1,21 @@
+package domain
+
+import "math"
+
+// NormalizeVector returns the vector scaled to unit length so cosine similarity is a dot product
+func NormalizeVector(vector []float32) []float32 {
+	var sum float64
+	for _, v := range vector {
+		sum += float64(v) * float64(v)
+	}
+	if sum == 0 {
+		return vector
+	}
+
+	norm := float32(math.Sqrt(sum))
+	normalized := make([]float32, len(vector))
+	for i, v := range vector {
+		normalized[i] = v / norm
+	}
+	return normalized
+}
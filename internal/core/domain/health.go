@@ -0,0 +1,27 @@
+package domain
+
+import "time"
+
+// Health statuses of a component or the whole service
+const (
+	HealthUp       = "up"
+	HealthDegraded = "degraded" // Only non-critical components are down
+	HealthDown     = "down"
+)
+
+// ComponentHealth is the result of probing one dependency
+type ComponentHealth struct {
+	Name      string `json:"name"`
+	Status    string `json:"status"`
+	Critical  bool   `json:"critical"` // The service is not ready while a critical component is down
+	Error     string `json:"error,omitempty"`
+	LatencyMS int64  `json:"latency_ms"`
+}
+
+// HealthReport is the result of probing every configured dependency
+type HealthReport struct {
+	Status     string            `json:"status"`
+	Ready      bool              `json:"ready"`
+	CheckedAt  time.Time         `json:"checked_at"`
+	Components []ComponentHealth `json:"components"`
+}
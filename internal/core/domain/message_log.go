@@ -0,0 +1,16 @@
+package domain
+
+import (
+	"time"
+)
+
+// LoggedMessage is a message seen in a group, kept for digests
+type LoggedMessage struct {
+	ConversationID string    `json:"conversation_id"`
+	MessageID      string    `json:"message_id"`
+	SenderID       string    `json:"sender_id"`
+	SenderName     string    `json:"sender_name"`
+	Content        string    `json:"content"`
+	FromBot        bool      `json:"from_bot"`
+	Timestamp      time.Time `json:"timestamp"`
+}
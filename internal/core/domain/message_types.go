@@ -9,4 +9,10 @@ const (
 	
 	// MessageTypeImageAnalysis is for messages containing images for analysis
 	MessageTypeImageAnalysis MessageType = "image_analysis"
+	
+	// MessageTypeToolCall is for assistant messages that request tool calls
+	MessageTypeToolCall MessageType = "tool_call"
+	
+	// MessageTypeToolResult is for messages carrying the result of a tool call
+	MessageTypeToolResult MessageType = "tool_result"
 )
@@ -0,0 +1,58 @@
+package domain
+
+import "time"
+
+// ModelRole is what the bot uses a model for
+type ModelRole string
+
+const (
+	ModelRoleMain      ModelRole = "main"      // Chat replies
+	ModelRoleSecondary ModelRole = "secondary" // Search queries, summaries and other helper tasks
+	ModelRoleImage     ModelRole = "image"     // Image analysis
+)
+
+// LLMTask is the kind of work an LLM request does, so it can be routed to a suitable backend
+type LLMTask string
+
+const (
+	LLMTaskChat        LLMTask = "chat"         // Chat replies
+	LLMTaskSearchQuery LLMTask = "search_query" // Turning a question into a web search query
+	LLMTaskHelper      LLMTask = "helper"       // Summaries, memory extraction, reminders and other secondary LLM work
+	LLMTaskImage       LLMTask = "image"        // Image analysis
+)
+
+// ModelDetails describes a model installed on the LLM server
+type ModelDetails struct {
+	Name          string      `json:"name"`
+	Size          int64       `json:"size"`
+	ModifiedAt    time.Time   `json:"modified_at"`
+	Family        string      `json:"family,omitempty"`
+	Families      []string    `json:"families,omitempty"`
+	ParameterSize string      `json:"parameter_size,omitempty"` // Such as "8.0B"
+	Quantization  string      `json:"quantization,omitempty"`   // Such as "Q4_K_M"
+	ContextLength int         `json:"context_length,omitempty"` // Only known once the model is shown
+	Capabilities  []string    `json:"capabilities,omitempty"`   // Such as "completion", "vision" and "tools", only known once the model is shown
+	Loaded        bool        `json:"loaded"`                   // Currently in memory on the server
+	Roles         []ModelRole `json:"roles,omitempty"`          // What the bot uses the model for
+}
+
+// HasCapability reports whether the model has a capability such as "vision" or "tools"
+func (m ModelDetails) HasCapability(capability string) bool {
+	for _, c := range m.Capabilities {
+		if c == capability {
+			return true
+		}
+	}
+	return false
+}
+
+// ModelPull is the progress of downloading a model to the LLM server
+type ModelPull struct {
+	Name      string    `json:"name"`
+	Status    string    `json:"status"` // The server's latest status line
+	Completed int64     `json:"completed"`
+	Total     int64     `json:"total"`
+	Done      bool      `json:"done"`
+	Error     string    `json:"error,omitempty"`
+	StartedAt time.Time `json:"started_at"`
+}
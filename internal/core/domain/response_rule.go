@@ -0,0 +1,86 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+// Match types of a ResponseRule
+const (
+	RuleMatchKeyword = "keyword" // Any of the keywords appears as whole words
+	RuleMatchRegex   = "regex"   // The pattern matches the message
+	RuleMatchIntent  = "intent"  // An LLM decides the message has the described intent
+)
+
+// ResponseRule answers matching messages with a canned response instead of the chat model
+type ResponseRule struct {
+	ID          string   `json:"id"`
+	Description string   `json:"description,omitempty"`
+	Priority    int      `json:"priority"` // Higher priority rules are checked first
+	Match       string   `json:"match"`    // "keyword", "regex" or "intent"
+	Keywords    []string `json:"keywords,omitempty"`
+	Pattern     string   `json:"pattern,omitempty"`
+	Intent      string   `json:"intent,omitempty"`   // Description of the intent for the LLM
+	Groups      []string `json:"groups,omitempty"`   // Group JIDs the rule applies to, empty for all
+	Personas    []string `json:"personas,omitempty"` // Persona keys the rule applies to, empty for all
+	Response    string   `json:"response"`           // Go template with .Persona, .Sender, .Group and .Message
+	Disabled    bool     `json:"disabled,omitempty"`
+}
+
+// Validate checks that a rule is complete and its pattern and response template compile
+func (r *ResponseRule) Validate() error {
+	if r.ID == "" {
+		return fmt.Errorf("rule id is required")
+	}
+
+	switch r.Match {
+	case RuleMatchKeyword:
+		if len(r.Keywords) == 0 {
+			return fmt.Errorf("rule %s: keyword rules need keywords", r.ID)
+		}
+		for _, keyword := range r.Keywords {
+			if strings.TrimSpace(keyword) == "" {
+				return fmt.Errorf("rule %s: keywords cannot be blank", r.ID)
+			}
+		}
+	case RuleMatchRegex:
+		if _, err := regexp.Compile(r.Pattern); err != nil || r.Pattern == "" {
+			return fmt.Errorf("rule %s: invalid pattern %q", r.ID, r.Pattern)
+		}
+	case RuleMatchIntent:
+		if r.Intent == "" {
+			return fmt.Errorf("rule %s: intent rules need an intent", r.ID)
+		}
+	default:
+		return fmt.Errorf("rule %s: unknown match type %q", r.ID, r.Match)
+	}
+
+	if r.Response == "" {
+		return fmt.Errorf("rule %s: response is required", r.ID)
+	}
+	if _, err := template.New(r.ID).Parse(r.Response); err != nil {
+		return fmt.Errorf("rule %s: invalid response template: %w", r.ID, err)
+	}
+
+	return nil
+}
+
+// AppliesTo reports whether the rule is scoped to a group and persona
+func (r *ResponseRule) AppliesTo(groupJID, personaKey string) bool {
+	return inScope(r.Groups, groupJID) && inScope(r.Personas, personaKey)
+}
+
+// inScope reports whether value is in scope, an empty scope includes everything
+func inScope(scope []string, value string) bool {
+	if len(scope) == 0 {
+		return true
+	}
+	for _, item := range scope {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
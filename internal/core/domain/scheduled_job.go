@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// ScheduledJob is a message the bot posts to a chat once or on a recurring schedule
+type ScheduledJob struct {
+	ID        string    `json:"id"`
+	ChatJID   string    `json:"chat_jid"` // WhatsApp group the message is posted to
+	Message   string    `json:"message"`
+	Cron      string    `json:"cron"` // Five-field cron expression, empty for one-shot jobs
+	NextRun   time.Time `json:"next_run"`
+	LastRun   time.Time `json:"last_run"` // Zero until the job has run
+	CreatedBy string    `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NewScheduledJob creates a new job for a chat
+// IDs are short and lowercase so they can be typed in a cancel command
+func NewScheduledJob(chatJID, message, cron string, nextRun time.Time, createdBy string) *ScheduledJob {
+	return &ScheduledJob{
+		ID:        strings.ToLower(randString(6)),
+		ChatJID:   chatJID,
+		Message:   message,
+		Cron:      cron,
+		NextRun:   nextRun,
+		CreatedBy: createdBy,
+		CreatedAt: time.Now(),
+	}
+}
+
+// Recurring reports whether the job runs on a cron schedule
+func (j *ScheduledJob) Recurring() bool {
+	return j.Cron != ""
+}
@@ -0,0 +1,31 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/vibin/chat-bot/internal/core/domain"
+)
+
+// DocumentRepositoryPort defines the interface for storing documents and their chunks
+type DocumentRepositoryPort interface {
+	// SaveDocument stores a document with its chunks, replacing a previous version
+	SaveDocument(ctx context.Context, doc *domain.Document, chunks []domain.DocumentChunk) error
+	
+	// ListDocuments returns the documents of a conversation, newest first
+	ListDocuments(ctx context.Context, conversationID string) ([]*domain.Document, error)
+	
+	// GetChunks returns all chunks of the documents in a conversation
+	GetChunks(ctx context.Context, conversationID string) ([]domain.DocumentChunk, error)
+	
+	// DeleteDocument deletes a document and its chunks
+	DeleteDocument(ctx context.Context, id string) error
+}
+
+// DocumentExtractorPort defines the interface for extracting text from files
+type DocumentExtractorPort interface {
+	// Supports reports whether files of this type can be extracted
+	Supports(mimeType, fileName string) bool
+	
+	// Extract returns the text of a file page by page
+	Extract(data []byte, mimeType, fileName string) ([]domain.DocumentPage, error)
+}
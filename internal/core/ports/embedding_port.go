@@ -0,0 +1,12 @@
+package ports
+
+import "context"
+
+// EmbeddingPort defines the interface for turning text into embedding vectors
+type EmbeddingPort interface {
+	// Embed returns the embedding vector of a text
+	Embed(ctx context.Context, text string) ([]float32, error)
+	
+	// ModelName returns the embedding model, vectors of different models are not comparable
+	ModelName() string
+}
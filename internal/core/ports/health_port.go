@@ -0,0 +1,9 @@
+package ports
+
+import "context"
+
+// HealthCheckerPort is implemented by adapters that can check whether their backend is reachable
+type HealthCheckerPort interface {
+	// Ping returns an error when the backend cannot be reached
+	Ping(ctx context.Context) error
+}
@@ -2,6 +2,7 @@ package ports
 
 import (
 	"context"
+	"errors"
 
 	"github.com/vibin/chat-bot/internal/core/domain"
 )
@@ -11,6 +12,77 @@ type LLMPort interface {
 	// GenerateResponse generates a response from the LLM for a given chat history
 	GenerateResponse(ctx context.Context, messages []domain.Message) (string, error)
 	
+	// GenerateResponseStream generates a response and calls onToken with each token delta as it arrives.
+	// It returns the complete response once the stream has finished.
+	GenerateResponseStream(ctx context.Context, messages []domain.Message, onToken func(token string)) (string, error)
+	
 	// GetModelInfo returns information about the current LLM model
 	GetModelInfo(ctx context.Context) (map[string]interface{}, error)
 }
+
+// systemPromptKey is the context key for a system prompt override
+type systemPromptKey struct{}
+
+// WithSystemPrompt returns a context whose LLM calls use prompt instead of the adapter's default system prompt
+// An empty prompt restores the default
+func WithSystemPrompt(ctx context.Context, prompt string) context.Context {
+	return context.WithValue(ctx, systemPromptKey{}, prompt)
+}
+
+// SystemPromptFrom returns the system prompt override stored in the context, or an empty string
+func SystemPromptFrom(ctx context.Context) string {
+	prompt, _ := ctx.Value(systemPromptKey{}).(string)
+	return prompt
+}
+
+// llmTaskKey is the context key for the task of an LLM request
+type llmTaskKey struct{}
+
+// WithLLMTask returns a context whose LLM calls are routed as task, overriding the task of the LLM they are made on
+func WithLLMTask(ctx context.Context, task domain.LLMTask) context.Context {
+	return context.WithValue(ctx, llmTaskKey{}, task)
+}
+
+// LLMTaskFrom returns the task stored in the context, or an empty task
+func LLMTaskFrom(ctx context.Context) domain.LLMTask {
+	task, _ := ctx.Value(llmTaskKey{}).(domain.LLMTask)
+	return task
+}
+
+// Errors returned by LLM ports that queue requests
+var (
+	ErrLLMQueueFull    = errors.New("too many LLM requests are waiting")
+	ErrLLMQueueTimeout = errors.New("LLM request waited too long for its turn")
+)
+
+// Priorities of queued LLM requests, higher ones are served first
+const (
+	LLMPriorityLow    = -10 // Background work such as memory extraction
+	LLMPriorityNormal = 0
+)
+
+// LLMQueueInfo describes who an LLM request is made for, so a queue can order it fairly
+type LLMQueueInfo struct {
+	Group    string             // Requests of one group take turns with the other groups
+	Priority int                // LLMPriorityNormal unless set
+	OnQueued func(position int) // Called when the request has to wait, with its place in line starting at 1
+}
+
+// llmQueueInfoKey is the context key for the queue info of an LLM request
+type llmQueueInfoKey struct{}
+
+// WithLLMQueueInfo returns a context whose LLM calls are queued according to info
+func WithLLMQueueInfo(ctx context.Context, info LLMQueueInfo) context.Context {
+	return context.WithValue(ctx, llmQueueInfoKey{}, info)
+}
+
+// LLMQueueInfoFrom returns the queue info stored in the context, or the zero info
+func LLMQueueInfoFrom(ctx context.Context) LLMQueueInfo {
+	info, _ := ctx.Value(llmQueueInfoKey{}).(LLMQueueInfo)
+	return info
+}
+
+// IsLLMBusy reports whether an LLM call failed because too many requests were queued
+func IsLLMBusy(err error) bool {
+	return errors.Is(err, ErrLLMQueueFull) || errors.Is(err, ErrLLMQueueTimeout)
+}
@@ -0,0 +1,23 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/vibin/chat-bot/internal/core/domain"
+)
+
+// MessageLogPort defines the interface for the passive log of group messages
+type MessageLogPort interface {
+	// Append adds a message to the log
+	Append(ctx context.Context, msg domain.LoggedMessage) error
+	
+	// Since returns up to limit of the newest messages after a time, oldest first
+	Since(ctx context.Context, conversationID string, since time.Time, limit int) ([]domain.LoggedMessage, error)
+	
+	// LastFrom returns the time of the newest message by a sender before a time, or the zero time
+	LastFrom(ctx context.Context, conversationID, senderID string, before time.Time) (time.Time, error)
+	
+	// Prune deletes messages older than maxAge and all but the newest maxPerConversation of each conversation
+	Prune(ctx context.Context, maxAge time.Duration, maxPerConversation int) (int64, error)
+}
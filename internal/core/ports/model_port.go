@@ -0,0 +1,31 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/vibin/chat-bot/internal/core/domain"
+)
+
+// ModelManagerPort is implemented by LLM adapters whose server can list, download and unload models
+type ModelManagerPort interface {
+	// ListModels returns the models installed on the server
+	ListModels(ctx context.Context) ([]domain.ModelDetails, error)
+
+	// ShowModel returns a model with its context length and capabilities
+	ShowModel(ctx context.Context, name string) (domain.ModelDetails, error)
+
+	// PullModel downloads a model and calls onProgress with each status update
+	PullModel(ctx context.Context, name string, onProgress func(status string, completed, total int64)) error
+
+	// UnloadModel frees the memory a loaded model uses on the server
+	UnloadModel(ctx context.Context, name string) error
+}
+
+// ModelSwitcherPort is implemented by LLM adapters whose model can be changed while running
+type ModelSwitcherPort interface {
+	// ModelName returns the model requests are sent to
+	ModelName() string
+
+	// SetModel switches the model for new requests
+	SetModel(name string)
+}
@@ -0,0 +1,39 @@
+package ports
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/vibin/chat-bot/internal/core/domain"
+)
+
+// JobRepositoryPort defines the interface for storing scheduled jobs
+type JobRepositoryPort interface {
+	// CreateJob stores a new job, returning ErrJobExists when its ID is taken
+	CreateJob(ctx context.Context, job *domain.ScheduledJob) error
+
+	// UpdateJob saves the schedule of an existing job
+	UpdateJob(ctx context.Context, job *domain.ScheduledJob) error
+
+	// GetJob returns a job by ID
+	GetJob(ctx context.Context, id string) (*domain.ScheduledJob, error)
+
+	// ListJobs returns the jobs of a chat by next run, or of all chats when chatJID is empty
+	ListJobs(ctx context.Context, chatJID string) ([]*domain.ScheduledJob, error)
+
+	// DueJobs returns the jobs whose next run is at or before now
+	DueJobs(ctx context.Context, now time.Time) ([]*domain.ScheduledJob, error)
+
+	// DeleteJob deletes a job
+	DeleteJob(ctx context.Context, id string) error
+}
+
+// ErrJobExists is returned when a new job's ID is already used by another job
+var ErrJobExists = errors.New("job ID already exists")
+
+// MessageSenderPort defines the interface for posting scheduled messages to a chat
+type MessageSenderPort interface {
+	// SendGroupMessage sends a message to a group on behalf of the bot
+	SendGroupMessage(groupID string, message string) error
+}
@@ -0,0 +1,9 @@
+package ports
+
+import "context"
+
+// SpeechToTextPort defines the interface for transcribing audio
+type SpeechToTextPort interface {
+	// Transcribe returns the text spoken in an audio file
+	Transcribe(ctx context.Context, audio []byte, mimeType string) (string, error)
+}
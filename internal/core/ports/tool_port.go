@@ -0,0 +1,63 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/vibin/chat-bot/internal/core/domain"
+)
+
+// Tool defines a capability the LLM can decide to invoke while answering
+type Tool interface {
+	// Name returns the unique function name the LLM uses to call the tool
+	Name() string
+	
+	// Description tells the LLM what the tool does and when to use it
+	Description() string
+	
+	// Parameters returns the JSON schema of the tool arguments
+	Parameters() map[string]interface{}
+	
+	// Execute runs the tool with the arguments chosen by the LLM
+	Execute(ctx context.Context, arguments map[string]interface{}) (ToolResult, error)
+}
+
+// ToolResult is the output of a tool call
+type ToolResult struct {
+	// Content is the text returned to the LLM
+	Content string
+	
+	// Images are base64 encoded images produced by the tool, delivered to the user but not the LLM
+	Images []string
+}
+
+// ToolCallingLLMPort is implemented by LLM adapters that support native tool calling
+// Wrappers such as queues and routers implement it for any adapter, and report through SupportsTools
+// whether the adapters behind them can call tools
+type ToolCallingLLMPort interface {
+	// GenerateWithTools generates the next assistant message, which either answers
+	// or requests tool calls through its ToolCalls field
+	GenerateWithTools(ctx context.Context, messages []domain.Message, tools []Tool) (domain.Message, error)
+
+	// SupportsTools reports whether GenerateWithTools can call tools rather than only answer in plain text
+	SupportsTools() bool
+}
+
+// ToolScope identifies the conversation and user a tool call is made on behalf of
+type ToolScope struct {
+	ChatID string
+	UserID string
+}
+
+// toolScopeKey is the context key for the ToolScope
+type toolScopeKey struct{}
+
+// WithToolScope returns a context carrying the tool scope
+func WithToolScope(ctx context.Context, scope ToolScope) context.Context {
+	return context.WithValue(ctx, toolScopeKey{}, scope)
+}
+
+// ToolScopeFrom returns the tool scope stored in the context, if any
+func ToolScopeFrom(ctx context.Context) ToolScope {
+	scope, _ := ctx.Value(toolScopeKey{}).(ToolScope)
+	return scope
+}
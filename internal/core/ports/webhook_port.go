@@ -0,0 +1,25 @@
+package ports
+
+import (
+	"context"
+	"errors"
+
+	"github.com/vibin/chat-bot/config"
+)
+
+// WebhookInput is what a webhook service's body template can refer to
+type WebhookInput struct {
+	ChatInput string   // The message without trigger words
+	Sender    string   // Display name of the sender
+	Group     string   // Chat JID
+	Memories  []string // Memories relevant to the message
+}
+
+// WebhookPort defines the interface for calling external chat services
+type WebhookPort interface {
+	// Call sends the input to a service and returns its reply
+	Call(ctx context.Context, service config.WebhookServiceConfig, input WebhookInput) (string, error)
+}
+
+// ErrServiceDown is returned while a webhook service is failing and calls to it are skipped
+var ErrServiceDown = errors.New("service is down")
@@ -1,6 +1,10 @@
 package ports
 
-import "context"
+import (
+	"context"
+
+	"github.com/vibin/chat-bot/config"
+)
 
 // GroupInfo contains information about a WhatsApp group
 type GroupInfo struct {
@@ -104,4 +108,13 @@ type WhatsAppPort interface {
 	
 	// SendGroupMessage sends a message to a WhatsApp group on behalf of the bot
 	SendGroupMessage(groupID string, message string) error
+	
+	// GetPersonas returns copies of the persona profiles, the default persona and the group assignments
+	GetPersonas() (map[string]config.PersonaConfig, string, map[string]string)
+	
+	// UpdatePersonas replaces the persona profiles and their group assignments
+	UpdatePersonas(personas map[string]config.PersonaConfig, defaultPersona string, groupPersonas map[string]string) error
+	
+	// GetCommandNames returns the names of the registered commands
+	GetCommandNames() []string
 }
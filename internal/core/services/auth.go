@@ -0,0 +1,243 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/vibin/chat-bot/config"
+	"github.com/vibin/chat-bot/internal/core/domain"
+	"github.com/vibin/chat-bot/internal/logger"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/time/rate"
+)
+
+const (
+	defaultSessionDuration = 12 * time.Hour
+
+	// loginFailureBurst is how many failed logins an address or username may make in a row
+	loginFailureBurst = 5
+
+	// loginFailureRefill is how often another failed login is allowed once the burst is used up
+	loginFailureRefill = time.Minute
+
+	// maxLoginLimiters is how many addresses and usernames are tracked before recovered ones are dropped
+	maxLoginLimiters = 10000
+)
+
+// ErrInvalidCredentials is returned for an unknown user or a wrong password
+var ErrInvalidCredentials = errors.New("invalid username or password")
+
+// ErrTooManyAttempts is returned while an address or username is locked out after failed logins
+var ErrTooManyAttempts = errors.New("too many failed login attempts")
+
+// dummyHash is compared against for unknown users, so a login takes as long whether or not the user exists
+var dummyHash = []byte("$2a$10$MtWx2xycFAsUMHy5ErlItOfU0IReDRm/9deFkx6yxjs8F695.nY2C")
+
+// AuthService logs admin users in and checks sessions and API tokens
+// Sessions are kept in memory, so a restart logs everyone out
+type AuthService struct {
+	config   *config.AuthConfig
+	logger   logger.Logger
+	mutex    sync.Mutex
+	sessions map[string]*domain.Session
+	limiters map[string]*rate.Limiter // Failed logins by client address and by username
+}
+
+// NewAuthService creates a new auth service after checking the configured users and tokens
+func NewAuthService(config *config.AuthConfig, logger logger.Logger) (*AuthService, error) {
+	for _, user := range config.Users {
+		if user.Username == "" || user.PasswordHash == "" {
+			return nil, fmt.Errorf("admin users need a username and a password hash")
+		}
+		if !domain.Role(user.Role).Valid() {
+			return nil, fmt.Errorf("user %s has unknown role %q", user.Username, user.Role)
+		}
+	}
+	for _, token := range config.APITokens {
+		if len(token.TokenHash) != sha256.Size*2 {
+			return nil, fmt.Errorf("api token %s needs a hex SHA-256 token hash", token.Name)
+		}
+		if !domain.Role(token.Role).Valid() {
+			return nil, fmt.Errorf("api token %s has unknown role %q", token.Name, token.Role)
+		}
+	}
+	if len(config.Users) == 0 && len(config.APITokens) == 0 {
+		logger.Warn("Admin auth is enabled but no users or API tokens are configured")
+	}
+
+	return &AuthService{
+		config:   config,
+		logger:   logger,
+		sessions: make(map[string]*domain.Session),
+		limiters: make(map[string]*rate.Limiter),
+	}, nil
+}
+
+// Login checks a username and password and starts a session
+// Failed attempts are limited per client address and per username
+func (s *AuthService) Login(username, password, clientIP string) (*domain.Session, error) {
+	keys := []string{"ip:" + clientIP, "user:" + username}
+	if !s.loginAllowed(keys) {
+		s.logger.Warn("Login refused after too many failures", "username", username, "client_ip", clientIP)
+		return nil, ErrTooManyAttempts
+	}
+
+	var user *config.AdminUserConfig
+	for i := range s.config.Users {
+		if s.config.Users[i].Username == username {
+			user = &s.config.Users[i]
+			break
+		}
+	}
+
+	if user == nil {
+		bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+		s.logger.Warn("Login failed", "username", username, "client_ip", clientIP)
+		s.loginFailed(keys)
+		return nil, ErrInvalidCredentials
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		s.logger.Warn("Login failed", "username", username, "client_ip", clientIP)
+		s.loginFailed(keys)
+		return nil, ErrInvalidCredentials
+	}
+
+	duration := s.config.SessionHours * time.Hour
+	if duration <= 0 {
+		duration = defaultSessionDuration
+	}
+
+	session := &domain.Session{
+		ID:        randomToken(),
+		Identity:  domain.Identity{Name: user.Username, Role: domain.Role(user.Role)},
+		CSRFToken: randomToken(),
+		ExpiresAt: time.Now().Add(duration),
+	}
+
+	s.mutex.Lock()
+	s.removeExpired(time.Now())
+	s.sessions[session.ID] = session
+	s.mutex.Unlock()
+
+	s.logger.Info("User logged in", "username", user.Username, "role", user.Role)
+	return session, nil
+}
+
+// Session returns the unexpired session with the given ID
+func (s *AuthService) Session(id string) (*domain.Session, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	session, ok := s.sessions[id]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(session.ExpiresAt) {
+		delete(s.sessions, id)
+		return nil, false
+	}
+	return session, true
+}
+
+// Logout ends a session
+func (s *AuthService) Logout(id string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.sessions, id)
+}
+
+// AuthenticateToken returns the identity of an API token
+func (s *AuthService) AuthenticateToken(token string) (domain.Identity, bool) {
+	hash := HashAPIToken(token)
+	for _, configured := range s.config.APITokens {
+		if subtle.ConstantTimeCompare([]byte(hash), []byte(configured.TokenHash)) == 1 {
+			return domain.Identity{Name: configured.Name, Role: domain.Role(configured.Role), IsToken: true}, true
+		}
+	}
+	return domain.Identity{}, false
+}
+
+// loginAllowed reports whether none of the keys has used up its failed logins
+func (s *AuthService) loginAllowed(keys []string) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := time.Now()
+	for _, key := range keys {
+		if limiter, ok := s.limiters[key]; ok && limiter.TokensAt(now) < 1 {
+			return false
+		}
+	}
+	return true
+}
+
+// loginFailed counts a failed login against each key
+func (s *AuthService) loginFailed(keys []string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := time.Now()
+	if len(s.limiters) >= maxLoginLimiters {
+		s.removeRecovered(now)
+	}
+	for _, key := range keys {
+		limiter, ok := s.limiters[key]
+		if !ok {
+			limiter = rate.NewLimiter(rate.Every(loginFailureRefill), loginFailureBurst)
+			s.limiters[key] = limiter
+		}
+		limiter.AllowN(now, 1)
+	}
+}
+
+// removeRecovered drops limiters that have earned back all their attempts, the caller must hold the mutex
+func (s *AuthService) removeRecovered(now time.Time) {
+	for key, limiter := range s.limiters {
+		if limiter.TokensAt(now) >= loginFailureBurst {
+			delete(s.limiters, key)
+		}
+	}
+}
+
+// removeExpired drops expired sessions, the caller must hold the mutex
+func (s *AuthService) removeExpired(now time.Time) {
+	for id, session := range s.sessions {
+		if now.After(session.ExpiresAt) {
+			delete(s.sessions, id)
+		}
+	}
+}
+
+// HashPassword returns the bcrypt hash of a password for the config file
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	return string(hash), err
+}
+
+// NewAPIToken returns a random API token and the hash to put in the config file
+func NewAPIToken() (token, hash string) {
+	token = randomToken()
+	return token, HashAPIToken(token)
+}
+
+// HashAPIToken returns the hex SHA-256 of an API token
+func HashAPIToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// randomToken returns 32 random bytes, hex encoded
+func randomToken() string {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("failed to read random bytes: %v", err))
+	}
+	return hex.EncodeToString(b)
+}
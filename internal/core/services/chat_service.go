@@ -18,17 +18,25 @@ import (
 type ChatService struct {
 	llm        ports.LLMPort
 	imageLLM   ports.LLMPort  // Dedicated LLM for image analysis
+	summaryLLM ports.LLMPort  // Secondary LLM for history summaries
 	repository ports.ChatRepositoryPort
 	webSearch  ports.WebSearchPort
+	tools      []ports.Tool
 	logger     logger.Logger
 	config     *config.Config
 }
 
 // NewChatService creates a new ChatService
-func NewChatService(llm ports.LLMPort, imageLLM ports.LLMPort, repository ports.ChatRepositoryPort, webSearch ports.WebSearchPort, config *config.Config, logger logger.Logger) *ChatService {
+// summaryLLM may be nil, in which case the main LLM writes history summaries
+func NewChatService(llm ports.LLMPort, imageLLM ports.LLMPort, summaryLLM ports.LLMPort, repository ports.ChatRepositoryPort, webSearch ports.WebSearchPort, config *config.Config, logger logger.Logger) *ChatService {
+	if summaryLLM == nil {
+		summaryLLM = llm
+	}
+	
 	return &ChatService{
 		llm:        llm,
 		imageLLM:   imageLLM,
+		summaryLLM: summaryLLM,
 		repository: repository,
 		webSearch:  webSearch,
 		logger:     logger,
@@ -48,8 +56,27 @@ func (s *ChatService) CreateChat(ctx context.Context, title string) (*domain.Cha
 	return chat, nil
 }
 
+// CreateChatWithID creates a new chat with a caller-chosen ID, such as a WhatsApp conversation ID
+func (s *ChatService) CreateChatWithID(ctx context.Context, id, title string) (*domain.Chat, error) {
+	s.logger.Info("Creating new chat", "chat_id", id, "title", title)
+	chat := domain.NewChat(title)
+	chat.ID = id
+	err := s.repository.SaveChat(ctx, chat)
+	if err != nil {
+		s.logger.Error("Failed to save chat", "error", err)
+		return nil, err
+	}
+	return chat, nil
+}
+
 // SendMessage sends a user message to a chat and generates a response
 func (s *ChatService) SendMessage(ctx context.Context, chatID, content string) (*domain.Chat, error) {
+	return s.SendMessageWithPrompt(ctx, chatID, content, "")
+}
+
+// SendMessageWithPrompt stores content as the user's message but sends prompt to the LLM in its place
+// It is for context that should not stay in the history, such as document excerpts. An empty prompt sends content
+func (s *ChatService) SendMessageWithPrompt(ctx context.Context, chatID, content, prompt string) (*domain.Chat, error) {
 	s.logger.Info("Sending message to chat", "chat_id", chatID)
 	
 	// Get the chat
@@ -63,18 +90,27 @@ func (s *ChatService) SendMessage(ctx context.Context, chatID, content string) (
 	userMessage := domain.NewMessage("user", content)
 	chat.AddMessage(userMessage)
 	
+	// Fit the history into the context window, summarizing older turns if needed
+	history := s.buildHistory(ctx, chat)
+	if prompt != "" {
+		history = withLastContent(history, prompt)
+	}
+	
 	// Process the response based on the user's message
 	var response string
 	
-	// Check if web search is enabled and if the message indicates a need for search
-	if s.config.WebSearch.Enabled && s.webSearch != nil && s.webSearch.DetectSearchIntent(content) {
-		// Web search path
+	if toolLLM, ok := s.toolCallingLLM(); ok {
+		// Tool calling path, the LLM decides itself whether to search the web or use other tools
+		s.logger.Info("Generating response with tool calling", "chat_id", chatID)
+		response, err = s.generateWithTools(ctx, toolLLM, chat, history)
+	} else if prompt == "" && s.config.WebSearch.Enabled && s.webSearch != nil && s.webSearch.DetectSearchIntent(content) {
+		// Web search path, skipped with a prompt because the search prompt would replace it
 		s.logger.Info("Using web search pipeline", "chat_id", chatID)
-		response, err = s.processWebSearchRequest(ctx, content, chat.Messages)
+		response, err = s.processWebSearchRequest(ctx, content, history)
 	} else {
 		// Regular LLM path
 		s.logger.Info("Generating standard LLM response", "chat_id", chatID)
-		response, err = s.llm.GenerateResponse(ctx, chat.Messages)
+		response, err = s.llm.GenerateResponse(ctx, history)
 	}
 	
 	if err != nil {
@@ -86,8 +122,63 @@ func (s *ChatService) SendMessage(ctx context.Context, chatID, content string) (
 	assistantMessage := domain.NewMessage("assistant", response)
 	chat.AddMessage(assistantMessage)
 	
-	// Save the updated chat
-	err = s.repository.SaveChat(ctx, chat)
+	// Save the updated chat, tool images are only returned to the caller
+	err = s.repository.SaveChat(ctx, withoutToolImages(chat))
+	if err != nil {
+		s.logger.Error("Failed to save chat", "chat_id", chatID, "error", err)
+		return nil, err
+	}
+	
+	return chat, nil
+}
+
+// SendMessageStream sends a user message to a chat and streams the response tokens to onToken
+// The assistant message is persisted once the stream has completed
+func (s *ChatService) SendMessageStream(ctx context.Context, chatID, content string, onToken func(token string)) (*domain.Chat, error) {
+	s.logger.Info("Streaming message to chat", "chat_id", chatID)
+	
+	// Get the chat
+	chat, err := s.repository.GetChat(ctx, chatID)
+	if err != nil {
+		s.logger.Error("Failed to get chat", "chat_id", chatID, "error", err)
+		return nil, err
+	}
+	
+	// Add user message
+	userMessage := domain.NewMessage("user", content)
+	chat.AddMessage(userMessage)
+	
+	// Fit the history into the context window, summarizing older turns if needed
+	history := s.buildHistory(ctx, chat)
+	
+	var response string
+	if toolLLM, ok := s.toolCallingLLM(); ok {
+		// Tool calls are resolved first, so the final answer is sent as a single chunk
+		s.logger.Info("Generating response with tool calling", "chat_id", chatID)
+		response, err = s.generateWithTools(ctx, toolLLM, chat, history)
+		if err == nil && onToken != nil {
+			onToken(response)
+		}
+	} else {
+		// Enrich the history with search results when the message needs fresh information
+		if s.config.WebSearch.Enabled && s.webSearch != nil && s.webSearch.DetectSearchIntent(content) {
+			s.logger.Info("Using web search pipeline", "chat_id", chatID)
+			history = s.buildWebSearchHistory(ctx, content, history)
+		}
+		
+		response, err = s.llm.GenerateResponseStream(ctx, history, onToken)
+	}
+	if err != nil {
+		s.logger.Error("Failed to stream response", "chat_id", chatID, "error", err)
+		return nil, err
+	}
+	
+	// Add assistant message
+	assistantMessage := domain.NewMessage("assistant", response)
+	chat.AddMessage(assistantMessage)
+	
+	// Save the updated chat, tool images are only returned to the caller
+	err = s.repository.SaveChat(ctx, withoutToolImages(chat))
 	if err != nil {
 		s.logger.Error("Failed to save chat", "chat_id", chatID, "error", err)
 		return nil, err
@@ -96,6 +187,46 @@ func (s *ChatService) SendMessage(ctx context.Context, chatID, content string) (
 	return chat, nil
 }
 
+// GenerateCompletion generates a response for a caller-supplied message history without persisting a chat
+// Web search augmentation is applied when the last user message indicates a need for fresh information
+func (s *ChatService) GenerateCompletion(ctx context.Context, messages []domain.Message) (string, error) {
+	s.logger.Info("Generating completion", "message_count", len(messages))
+	
+	if content, ok := s.searchableContent(messages); ok {
+		s.logger.Info("Using web search pipeline for completion")
+		return s.processWebSearchRequest(ctx, content, messages)
+	}
+	
+	return s.llm.GenerateResponse(ctx, messages)
+}
+
+// GenerateCompletionStream is the streaming variant of GenerateCompletion
+func (s *ChatService) GenerateCompletionStream(ctx context.Context, messages []domain.Message, onToken func(token string)) (string, error) {
+	s.logger.Info("Streaming completion", "message_count", len(messages))
+	
+	history := messages
+	if content, ok := s.searchableContent(messages); ok {
+		s.logger.Info("Using web search pipeline for completion")
+		history = s.buildWebSearchHistory(ctx, content, messages)
+	}
+	
+	return s.llm.GenerateResponseStream(ctx, history, onToken)
+}
+
+// searchableContent returns the last user message if it should be answered with web search
+func (s *ChatService) searchableContent(messages []domain.Message) (string, bool) {
+	if !s.config.WebSearch.Enabled || s.webSearch == nil || len(messages) == 0 {
+		return "", false
+	}
+	
+	last := messages[len(messages)-1]
+	if last.Role != "user" || !s.webSearch.DetectSearchIntent(last.Content) {
+		return "", false
+	}
+	
+	return last.Content, true
+}
+
 // GetChat retrieves a chat by ID
 func (s *ChatService) GetChat(ctx context.Context, id string) (*domain.Chat, error) {
 	s.logger.Info("Getting chat", "chat_id", id)
@@ -122,8 +253,11 @@ func (s *ChatService) GetModelInfo(ctx context.Context) (map[string]interface{},
 
 // GetModelName returns the name of the current LLM model
 func (s *ChatService) GetModelName() string {
-	if s.config.LLM.Provider == "ollama" {
-		return s.config.LLM.Ollama.Model
+	s.config.RLock()
+	defer s.config.RUnlock()
+	switch s.config.LLM.Provider {
+	case "ollama", "openai":
+		return s.config.LLM.ModelName()
 	}
 	// Default fallback
 	return "unknown"
@@ -179,7 +313,9 @@ func (s *ChatService) CompletionWithImageAnalysis(ctx context.Context, message d
 	// Use the model name from config for logging
 	var modelName string
 	if s.config.ImageLLM.Enabled {
-		modelName = s.config.ImageLLM.Ollama.Model
+		s.config.RLock()
+		modelName = s.config.ImageLLM.AsLLMConfig().ModelName()
+		s.config.RUnlock()
 	} else {
 		modelName = s.GetModelName()
 	}
@@ -270,12 +406,18 @@ func removeBase64References(text string) string {
 
 // processWebSearchRequest processes a user request that requires web search
 func (s *ChatService) processWebSearchRequest(ctx context.Context, userContent string, chatHistory []domain.Message) (string, error) {
-	// Step 1: Format the search query using the secondary LLM
-	formattedQuery, err := s.webSearch.FormatSearchQuery(ctx, userContent)
+	return s.llm.GenerateResponse(ctx, s.buildWebSearchHistory(ctx, userContent, chatHistory))
+}
+
+// buildWebSearchHistory replaces the last user message with a prompt that includes web search results
+// The original history is returned if the search cannot be performed
+func (s *ChatService) buildWebSearchHistory(ctx context.Context, userContent string, chatHistory []domain.Message) []domain.Message {
+	// Step 1: Format the search query using the secondary LLM, without the persona of the chat
+	formattedQuery, err := s.webSearch.FormatSearchQuery(ports.WithSystemPrompt(ctx, ""), userContent)
 	if err != nil {
 		s.logger.Error("Failed to format search query", "error", err)
 		// Fall back to direct LLM response if search query formatting fails
-		return s.llm.GenerateResponse(ctx, chatHistory)
+		return chatHistory
 	}
 	
 	// Step 2: Perform the web search with the formatted query
@@ -283,7 +425,7 @@ func (s *ChatService) processWebSearchRequest(ctx context.Context, userContent s
 	if err != nil {
 		s.logger.Error("Web search failed", "error", err)
 		// Fall back to direct LLM response if search fails
-		return s.llm.GenerateResponse(ctx, chatHistory)
+		return chatHistory
 	}
 	
 	// Step 3: Format the search results for the LLM
@@ -297,8 +439,7 @@ func (s *ChatService) processWebSearchRequest(ctx context.Context, userContent s
 	copy(modifiedHistory, chatHistory[:len(chatHistory)-1])
 	modifiedHistory = append(modifiedHistory, promptWithContext)
 	
-	// Step 5: Generate the final response using the main LLM with search context
-	return s.llm.GenerateResponse(ctx, modifiedHistory)
+	return modifiedHistory
 }
 
 // formatSearchResultsForLLM formats search results into a prompt for the LLM
@@ -0,0 +1,84 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/vibin/chat-bot/internal/core/domain"
+)
+
+// defaultDigestTokens is the transcript budget when history budgeting is disabled
+const defaultDigestTokens = 6000
+
+// DigestReader identifies who a digest is written for
+type DigestReader struct {
+	Name  string // Display name, may be empty
+	Phone string // Phone number used in WhatsApp @mentions
+}
+
+// DigestConversation uses the secondary LLM to summarize logged group messages for a reader
+// The newest messages that fit the token budget are used, oldest first
+func (s *ChatService) DigestConversation(ctx context.Context, messages []domain.LoggedMessage, reader DigestReader) (string, error) {
+	if len(messages) == 0 {
+		return "", fmt.Errorf("no messages to summarize")
+	}
+
+	budget := s.config.History.MaxContextTokens
+	if budget <= 0 {
+		budget = defaultDigestTokens
+	}
+
+	// Walk back from the newest message until the budget is used up
+	lines := make([]string, 0, len(messages))
+	used := 0
+	for i := len(messages) - 1; i >= 0; i-- {
+		msg := messages[i]
+		sender := msg.SenderName
+		if sender == "" {
+			sender = msg.SenderID
+		}
+		if msg.FromBot {
+			sender += " (bot)"
+		}
+
+		line := fmt.Sprintf("[%s] %s: %s", msg.Timestamp.Local().Format("Mon 15:04"), sender, msg.Content)
+		tokens := estimateTokens(domain.NewMessage("user", line))
+		if used+tokens > budget && len(lines) > 0 {
+			break
+		}
+		used += tokens
+		lines = append(lines, line)
+	}
+	for i, j := 0, len(lines)-1; i < j; i, j = i+1, j-1 {
+		lines[i], lines[j] = lines[j], lines[i]
+	}
+
+	readerName := reader.Name
+	if readerName == "" {
+		readerName = "the reader"
+	}
+
+	prompt := fmt.Sprintf(`%s missed part of a WhatsApp group conversation. Write them a short digest of the messages below with these sections:
+*Topics* - what was discussed, one bullet per topic
+*Decisions* - anything that was agreed or decided
+*For you* - questions or requests addressed to %s, including @%s mentions
+
+Leave out empty sections. Use WhatsApp formatting, mention who said what where it matters, and reply with the digest only.
+
+Messages:
+%s`, readerName, readerName, reader.Phone, strings.Join(lines, "\n"))
+
+	response, err := s.summaryLLM.GenerateResponse(ctx, []domain.Message{domain.NewMessage("user", prompt)})
+	if err != nil {
+		return "", err
+	}
+
+	digest := strings.TrimSpace(response)
+	if digest == "" {
+		return "", fmt.Errorf("summary LLM returned an empty response")
+	}
+
+	s.logger.Info("Generated conversation digest", "messages", len(messages), "used_messages", len(lines), "tokens", used)
+	return digest, nil
+}
@@ -0,0 +1,379 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/vibin/chat-bot/config"
+	"github.com/vibin/chat-bot/internal/core/domain"
+	"github.com/vibin/chat-bot/internal/core/ports"
+	"github.com/vibin/chat-bot/internal/logger"
+)
+
+const (
+	defaultChunkSize    = 1000
+	defaultChunkOverlap = 150
+	defaultDocumentTopK = 4
+)
+
+// documentStopWords are ignored when matching questions to chunks by keyword
+var documentStopWords = map[string]bool{
+	"the": true, "and": true, "for": true, "are": true, "was": true, "what": true,
+	"which": true, "who": true, "how": true, "does": true, "this": true, "that": true,
+	"with": true, "from": true, "about": true, "document": true, "file": true,
+	"pdf": true, "page": true, "say": true, "says": true, "tell": true,
+}
+
+// DocumentService ingests shared documents and retrieves the passages relevant to a question
+type DocumentService struct {
+	repository ports.DocumentRepositoryPort
+	extractor  ports.DocumentExtractorPort
+	embedder   ports.EmbeddingPort // Optional, keyword matching is used without it
+	config     *config.DocumentsConfig
+	logger     logger.Logger
+}
+
+// NewDocumentService creates a new document service
+func NewDocumentService(repository ports.DocumentRepositoryPort, extractor ports.DocumentExtractorPort, embedder ports.EmbeddingPort, config *config.DocumentsConfig, logger logger.Logger) *DocumentService {
+	return &DocumentService{
+		repository: repository,
+		extractor:  extractor,
+		embedder:   embedder,
+		config:     config,
+		logger:     logger,
+	}
+}
+
+// Supports reports whether a file can be ingested
+func (s *DocumentService) Supports(mimeType, fileName string) bool {
+	return s.extractor.Supports(mimeType, fileName)
+}
+
+// MaxFileSize returns the largest file size in bytes that is ingested
+func (s *DocumentService) MaxFileSize() int64 {
+	if s.config.MaxFileSizeMB <= 0 {
+		return 20 << 20
+	}
+	return int64(s.config.MaxFileSizeMB) << 20
+}
+
+// Ingest extracts, chunks and stores a document for a conversation
+func (s *DocumentService) Ingest(ctx context.Context, conversationID, fileName, mimeType string, data []byte) (*domain.Document, error) {
+	if int64(len(data)) > s.MaxFileSize() {
+		return nil, fmt.Errorf("%s is larger than %d MB", fileName, s.MaxFileSize()>>20)
+	}
+
+	pages, err := s.extractor.Extract(data, mimeType, fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := domain.NewDocument(conversationID, fileName, mimeType)
+	var chunks []domain.DocumentChunk
+	for _, page := range pages {
+		if page.Number > doc.Pages {
+			doc.Pages = page.Number
+		}
+		for _, content := range chunkText(page.Text, s.chunkSize(), s.chunkOverlap()) {
+			chunks = append(chunks, domain.DocumentChunk{
+				DocumentID: doc.ID,
+				FileName:   fileName,
+				Position:   len(chunks),
+				Page:       page.Number,
+				Content:    content,
+			})
+		}
+	}
+	if len(chunks) == 0 {
+		return nil, fmt.Errorf("%s contains no text", fileName)
+	}
+
+	s.embedChunks(ctx, chunks)
+
+	if err := s.repository.SaveDocument(ctx, doc, chunks); err != nil {
+		return nil, fmt.Errorf("failed to save document: %w", err)
+	}
+
+	s.logger.Info("Ingested document",
+		"conversation_id", conversationID,
+		"file_name", fileName,
+		"pages", doc.Pages,
+		"chunks", len(chunks))
+
+	return doc, nil
+}
+
+// embedChunks embeds every chunk if an embedder is configured
+// If any chunk fails the document is stored without embeddings and searched by keyword
+func (s *DocumentService) embedChunks(ctx context.Context, chunks []domain.DocumentChunk) {
+	if s.embedder == nil {
+		return
+	}
+
+	for i := range chunks {
+		vector, err := s.embedder.Embed(ctx, chunks[i].Content)
+		if err != nil {
+			s.logger.Warn("Failed to embed document chunk, falling back to keyword search", "error", err)
+			for j := range chunks {
+				chunks[j].Embedding = nil
+			}
+			return
+		}
+		chunks[i].Embedding = domain.NormalizeVector(vector)
+	}
+}
+
+// ListDocuments returns the documents shared in a conversation
+func (s *DocumentService) ListDocuments(ctx context.Context, conversationID string) ([]*domain.Document, error) {
+	return s.repository.ListDocuments(ctx, conversationID)
+}
+
+// Search returns the chunks of a conversation's documents most relevant to a question, best first
+func (s *DocumentService) Search(ctx context.Context, conversationID, question string) ([]domain.DocumentChunk, error) {
+	chunks, err := s.repository.GetChunks(ctx, conversationID)
+	if err != nil {
+		return nil, err
+	}
+	if len(chunks) == 0 {
+		return nil, nil
+	}
+
+	scores := s.semanticScores(ctx, question, chunks)
+	if scores == nil {
+		scores = keywordScores(question, chunks)
+	}
+
+	order := make([]int, len(chunks))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return scores[order[a]] > scores[order[b]]
+	})
+
+	topK := s.config.TopK
+	if topK <= 0 {
+		topK = defaultDocumentTopK
+	}
+
+	var results []domain.DocumentChunk
+	for _, i := range order {
+		if len(results) == topK || scores[i] <= 0 {
+			break
+		}
+		results = append(results, chunks[i])
+	}
+
+	return results, nil
+}
+
+// semanticScores scores chunks by cosine similarity to the question
+// Returns nil when embeddings are unavailable for the question or any chunk
+func (s *DocumentService) semanticScores(ctx context.Context, question string, chunks []domain.DocumentChunk) []float64 {
+	if s.embedder == nil {
+		return nil
+	}
+
+	vector, err := s.embedder.Embed(ctx, question)
+	if err != nil {
+		s.logger.Warn("Failed to embed question, falling back to keyword search", "error", err)
+		return nil
+	}
+	vector = domain.NormalizeVector(vector)
+
+	scores := make([]float64, len(chunks))
+	for i, chunk := range chunks {
+		if len(chunk.Embedding) != len(vector) {
+			return nil
+		}
+		var dot float64
+		for j, v := range chunk.Embedding {
+			dot += float64(v) * float64(vector[j])
+		}
+		scores[i] = dot
+	}
+
+	return scores
+}
+
+// BuildQuestionPrompt builds a prompt asking the question over the retrieved chunks
+func (s *DocumentService) BuildQuestionPrompt(question string, chunks []domain.DocumentChunk) string {
+	var sb strings.Builder
+	sb.WriteString("Answer the question using only the document excerpts below. ")
+	sb.WriteString("Cite the excerpts you used like (report.pdf, p. 3). ")
+	sb.WriteString("If the excerpts do not contain the answer, say so.\n\n")
+
+	sb.WriteString("[DOCUMENTS]\n")
+	for _, chunk := range chunks {
+		sb.WriteString(fmt.Sprintf("--- %s ---\n%s\n", citation(chunk), chunk.Content))
+	}
+	sb.WriteString("[/DOCUMENTS]\n\n")
+
+	sb.WriteString("Question: " + question)
+	return sb.String()
+}
+
+// FormatSources lists the documents and pages the chunks came from
+func (s *DocumentService) FormatSources(chunks []domain.DocumentChunk) string {
+	var files []string
+	pages := make(map[string][]int)
+	for _, chunk := range chunks {
+		if _, seen := pages[chunk.FileName]; !seen {
+			files = append(files, chunk.FileName)
+			pages[chunk.FileName] = nil
+		}
+		if chunk.Page > 0 && !containsInt(pages[chunk.FileName], chunk.Page) {
+			pages[chunk.FileName] = append(pages[chunk.FileName], chunk.Page)
+		}
+	}
+
+	sources := make([]string, 0, len(files))
+	for _, file := range files {
+		filePages := pages[file]
+		if len(filePages) == 0 {
+			sources = append(sources, file)
+			continue
+		}
+
+		sort.Ints(filePages)
+		numbers := make([]string, len(filePages))
+		for i, page := range filePages {
+			numbers[i] = fmt.Sprint(page)
+		}
+		label := "p."
+		if len(filePages) > 1 {
+			label = "pp."
+		}
+		sources = append(sources, fmt.Sprintf("%s %s %s", file, label, strings.Join(numbers, ", ")))
+	}
+
+	return strings.Join(sources, "; ")
+}
+
+// citation labels a chunk with its file name and page
+func citation(chunk domain.DocumentChunk) string {
+	if chunk.Page > 0 {
+		return fmt.Sprintf("%s, p. %d", chunk.FileName, chunk.Page)
+	}
+	return chunk.FileName
+}
+
+// chunkSize returns the configured chunk size in characters
+func (s *DocumentService) chunkSize() int {
+	if s.config.ChunkSize <= 0 {
+		return defaultChunkSize
+	}
+	return s.config.ChunkSize
+}
+
+// chunkOverlap returns the configured overlap, which must be smaller than half a chunk
+func (s *DocumentService) chunkOverlap() int {
+	overlap := s.config.ChunkOverlap
+	if overlap < 0 {
+		overlap = defaultChunkOverlap
+	}
+	if overlap >= s.chunkSize()/2 {
+		overlap = s.chunkSize() / 4
+	}
+	return overlap
+}
+
+// chunkText splits text into chunks of about size characters on word boundaries,
+// repeating about overlap characters of the previous chunk at the start of the next
+func chunkText(text string, size, overlap int) []string {
+	words := strings.Fields(text)
+	if len(words) == 0 {
+		return nil
+	}
+
+	var chunks []string
+	start := 0
+	for start < len(words) {
+		end := start
+		length := 0
+		for end < len(words) && (length == 0 || length+1+len(words[end]) <= size) {
+			length += len(words[end]) + 1
+			end++
+		}
+		chunks = append(chunks, strings.Join(words[start:end], " "))
+		if end == len(words) {
+			break
+		}
+
+		// Step back over the overlap, always making progress
+		next := end
+		for back := 0; next > start+1 && back+len(words[next-1])+1 <= overlap; next-- {
+			back += len(words[next-1]) + 1
+		}
+		start = next
+	}
+
+	return chunks
+}
+
+// keywordScores scores chunks by the question's keywords, weighting rare words higher
+func keywordScores(question string, chunks []domain.DocumentChunk) []float64 {
+	terms := make(map[string]bool)
+	for _, word := range keywords(question) {
+		terms[word] = true
+	}
+	scores := make([]float64, len(chunks))
+	if len(terms) == 0 {
+		return scores
+	}
+
+	counts := make([]map[string]int, len(chunks))
+	documentFrequency := make(map[string]int)
+	for i, chunk := range chunks {
+		counts[i] = make(map[string]int)
+		for _, word := range keywords(chunk.Content) {
+			counts[i][word]++
+		}
+		for term := range terms {
+			if counts[i][term] > 0 {
+				documentFrequency[term]++
+			}
+		}
+	}
+
+	for i := range chunks {
+		for term := range terms {
+			if count := counts[i][term]; count > 0 {
+				idf := math.Log(float64(len(chunks))/float64(documentFrequency[term])) + 1
+				scores[i] += (1 + math.Log(float64(count))) * idf
+			}
+		}
+	}
+
+	return scores
+}
+
+// keywords returns the lowercase words of a text that are worth matching
+func keywords(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+
+	result := words[:0]
+	for _, word := range words {
+		if len([]rune(word)) < 3 || documentStopWords[word] {
+			continue
+		}
+		result = append(result, word)
+	}
+	return result
+}
+
+// containsInt checks whether a slice contains a value
+func containsInt(values []int, value int) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
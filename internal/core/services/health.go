@@ -0,0 +1,137 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/vibin/chat-bot/config"
+	"github.com/vibin/chat-bot/internal/core/domain"
+	"github.com/vibin/chat-bot/internal/logger"
+)
+
+const defaultProbeTimeout = 3 * time.Second
+
+// HealthProbe checks one dependency and returns an error when it is unavailable
+type HealthProbe func(ctx context.Context) error
+
+// healthComponent is a registered dependency
+type healthComponent struct {
+	name     string
+	critical bool
+	probe    HealthProbe
+}
+
+// HealthService probes the configured dependencies for the health and readiness endpoints
+type HealthService struct {
+	config     *config.HealthConfig
+	logger     logger.Logger
+	mutex      sync.Mutex
+	components []healthComponent
+	last       *domain.HealthReport // Reused for CacheSeconds
+}
+
+// NewHealthService creates a health service without any components
+func NewHealthService(config *config.HealthConfig, logger logger.Logger) *HealthService {
+	return &HealthService{
+		config: config,
+		logger: logger,
+	}
+}
+
+// Register adds a dependency to probe
+// The service is reported as not ready while a critical dependency is down
+func (s *HealthService) Register(name string, critical bool, probe HealthProbe) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.components = append(s.components, healthComponent{name: name, critical: critical, probe: probe})
+	s.last = nil
+}
+
+// Check probes every dependency concurrently, each with its own timeout
+// A recent report is returned without probing again
+func (s *HealthService) Check(ctx context.Context) domain.HealthReport {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.last != nil && time.Since(s.last.CheckedAt) < s.config.CacheSeconds*time.Second {
+		return *s.last
+	}
+
+	// The report is shared, so the caller going away must not fail its probes
+	ctx = context.WithoutCancel(ctx)
+
+	timeout := s.config.TimeoutSeconds * time.Second
+	if timeout <= 0 {
+		timeout = defaultProbeTimeout
+	}
+
+	results := make([]domain.ComponentHealth, len(s.components))
+	var wg sync.WaitGroup
+	for i, component := range s.components {
+		wg.Add(1)
+		go func(i int, component healthComponent) {
+			defer wg.Done()
+			results[i] = s.probe(ctx, component, timeout)
+		}(i, component)
+	}
+	wg.Wait()
+
+	report := domain.HealthReport{
+		Status:     domain.HealthUp,
+		Ready:      true,
+		CheckedAt:  time.Now(),
+		Components: results,
+	}
+	for _, result := range results {
+		if result.Status == domain.HealthUp {
+			continue
+		}
+		if result.Critical {
+			report.Status = domain.HealthDown
+			report.Ready = false
+		} else if report.Status == domain.HealthUp {
+			report.Status = domain.HealthDegraded
+		}
+	}
+
+	s.last = &report
+	return report
+}
+
+// probe runs one component's probe with a timeout
+func (s *HealthService) probe(ctx context.Context, component healthComponent, timeout time.Duration) domain.ComponentHealth {
+	probeCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := component.probe(probeCtx)
+	result := domain.ComponentHealth{
+		Name:      component.name,
+		Status:    domain.HealthUp,
+		Critical:  component.critical,
+		LatencyMS: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		result.Status = domain.HealthDown
+		result.Error = probeError(err)
+		s.logger.Warn("Health probe failed", "component", component.name, "error", err)
+	}
+	return result
+}
+
+// probeError describes a probe failure without the URL, which may carry credentials
+// The reports are served without authentication
+func probeError(err error) string {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		err = urlErr.Err
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return "timed out"
+	}
+	return err.Error()
+}
@@ -0,0 +1,184 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/vibin/chat-bot/internal/core/domain"
+	"github.com/vibin/chat-bot/internal/core/ports"
+)
+
+const (
+	// imageTokenEstimate is the approximate context cost of one attached image
+	imageTokenEstimate = 576
+
+	// messageTokenOverhead accounts for the role markers around each message
+	messageTokenOverhead = 4
+)
+
+// estimateTokens approximates how many tokens a message takes up in the model context
+// English averages about four characters per token, other scripts closer to one per character
+func estimateTokens(msg domain.Message) int {
+	ascii, other := 0, 0
+	for _, r := range msg.Content {
+		if r < utf8.RuneSelf {
+			ascii++
+		} else {
+			other++
+		}
+	}
+
+	return messageTokenOverhead + (ascii+3)/4 + other + len(msg.Images)*imageTokenEstimate
+}
+
+// countTokens returns the estimated token count of a list of messages
+func countTokens(messages []domain.Message) int {
+	total := 0
+	for _, msg := range messages {
+		total += estimateTokens(msg)
+	}
+	return total
+}
+
+// buildHistory returns the messages to send to the LLM for a chat
+// The newest turns are kept verbatim within the configured token budget and older
+// turns are folded into the chat's rolling summary, which the caller persists with the chat
+func (s *ChatService) buildHistory(ctx context.Context, chat *domain.Chat) []domain.Message {
+	budget := s.config.History.MaxContextTokens
+	if budget <= 0 {
+		return chat.Messages
+	}
+
+	// Discard a summary that no longer matches the stored messages
+	if chat.SummarizedCount > len(chat.Messages) {
+		chat.Summary = ""
+		chat.SummarizedCount = 0
+	}
+
+	pending := chat.Messages[chat.SummarizedCount:]
+	if countTokens(pending)+summaryTokens(chat.Summary) <= budget {
+		return withSummary(chat.Summary, pending)
+	}
+
+	// Keep only half the budget verbatim so the summary is not regenerated on every message
+	keep := recentWithinBudget(pending, budget/2, s.config.History.MinRecentMessages)
+	split := len(chat.Messages) - keep
+
+	// The pending messages are all recent enough to keep, so there is nothing new to summarize
+	if split <= chat.SummarizedCount {
+		return withSummary(chat.Summary, pending)
+	}
+
+	s.logger.Info("Summarizing chat history",
+		"chat_id", chat.ID,
+		"summarized_messages", split-chat.SummarizedCount,
+		"kept_messages", keep)
+
+	summary, err := s.summarizeHistory(ctx, chat.Summary, chat.Messages[chat.SummarizedCount:split])
+	if err != nil {
+		// Drop the older turns for this request and try summarizing again next time
+		s.logger.Warn("Failed to summarize chat history, truncating instead", "chat_id", chat.ID, "error", err)
+		return withSummary(chat.Summary, chat.Messages[split:])
+	}
+
+	chat.Summary = summary
+	chat.SummarizedCount = split
+
+	return withSummary(chat.Summary, chat.Messages[split:])
+}
+
+// recentWithinBudget returns how many of the newest messages fit within the token limit
+// At least minRecent messages, and always the latest one, are kept regardless of size
+// Tool results are kept together with the assistant message that called the tools
+func recentWithinBudget(messages []domain.Message, limit, minRecent int) int {
+	if minRecent < 1 {
+		minRecent = 1
+	}
+
+	total, keep := 0, 0
+	for i := len(messages) - 1; i >= 0; i-- {
+		total += estimateTokens(messages[i])
+		if keep >= minRecent && total > limit {
+			break
+		}
+		keep++
+	}
+
+	// A tool result without its tool call is rejected by the LLM, so move the boundary back to the call
+	start := len(messages) - keep
+	for start > 0 && messages[start].Role == "tool" {
+		start--
+	}
+
+	return len(messages) - start
+}
+
+// summaryTokens returns the estimated token cost of including a summary
+func summaryTokens(summary string) int {
+	if summary == "" {
+		return 0
+	}
+	return estimateTokens(summaryMessage(summary))
+}
+
+// summaryMessage wraps a chat summary in a system message
+func summaryMessage(summary string) domain.Message {
+	return domain.NewMessage("system", "Summary of the earlier conversation:\n"+summary)
+}
+
+// withSummary prepends the summary, if any, to the verbatim messages
+func withSummary(summary string, messages []domain.Message) []domain.Message {
+	if summary == "" {
+		return messages
+	}
+
+	history := make([]domain.Message, 0, len(messages)+1)
+	history = append(history, summaryMessage(summary))
+	return append(history, messages...)
+}
+
+// withLastContent returns a copy of the history whose latest message has different content
+func withLastContent(history []domain.Message, content string) []domain.Message {
+	history = append([]domain.Message(nil), history...)
+	if len(history) > 0 {
+		history[len(history)-1].Content = content
+	}
+	return history
+}
+
+// summarizeHistory uses the secondary LLM to fold messages into the previous summary
+func (s *ChatService) summarizeHistory(ctx context.Context, previous string, messages []domain.Message) (string, error) {
+	if previous == "" {
+		previous = "(none)"
+	}
+
+	var transcript strings.Builder
+	for _, msg := range messages {
+		transcript.WriteString(fmt.Sprintf("%s: %s\n", msg.Role, msg.Content))
+	}
+
+	prompt := fmt.Sprintf(`Update the running summary of a conversation with the new messages below.
+Keep names, facts, decisions, preferences and open questions. Drop greetings and small talk.
+Write at most %d words and reply with the updated summary only.
+
+Current summary:
+%s
+
+New messages:
+%s`, s.config.History.SummaryMaxWords, previous, transcript.String())
+
+	// The summary is written without the persona of the chat
+	response, err := s.summaryLLM.GenerateResponse(ports.WithSystemPrompt(ctx, ""), []domain.Message{domain.NewMessage("user", prompt)})
+	if err != nil {
+		return "", err
+	}
+
+	summary := strings.TrimSpace(response)
+	if summary == "" {
+		return "", fmt.Errorf("summary LLM returned an empty response")
+	}
+
+	return summary, nil
+}
@@ -0,0 +1,220 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/vibin/chat-bot/config"
+	"github.com/vibin/chat-bot/internal/core/domain"
+	"github.com/vibin/chat-bot/internal/logger"
+)
+
+// historyMessage returns a message with 36 ASCII characters of content, which estimates at 13 tokens
+func historyMessage(role string, i int) domain.Message {
+	content := fmt.Sprintf("message %02d", i)
+	return domain.NewMessage(role, content+strings.Repeat(".", 36-len(content)))
+}
+
+// contents returns the content of each message
+func contents(messages []domain.Message) []string {
+	result := make([]string, len(messages))
+	for i, msg := range messages {
+		result[i] = msg.Content
+	}
+	return result
+}
+
+// stubSummaryLLM answers summary requests with a fixed summary or error
+type stubSummaryLLM struct {
+	summary string
+	err     error
+	calls   int
+}
+
+func (s *stubSummaryLLM) GenerateResponse(ctx context.Context, messages []domain.Message) (string, error) {
+	s.calls++
+	return s.summary, s.err
+}
+
+func (s *stubSummaryLLM) GenerateResponseStream(ctx context.Context, messages []domain.Message, onToken func(token string)) (string, error) {
+	return s.GenerateResponse(ctx, messages)
+}
+
+func (s *stubSummaryLLM) GetModelInfo(ctx context.Context) (map[string]interface{}, error) {
+	return nil, nil
+}
+
+func TestEstimateTokens(t *testing.T) {
+	withImage := domain.NewMessage("user", "")
+	withImage.Images = []string{"aW1hZ2U="}
+
+	tests := []struct {
+		name string
+		msg  domain.Message
+		want int
+	}{
+		{name: "empty message", msg: domain.NewMessage("user", ""), want: 4},
+		{name: "four ASCII characters per token", msg: domain.NewMessage("user", "abcd"), want: 5},
+		{name: "partial tokens round up", msg: domain.NewMessage("user", "abcde"), want: 6},
+		{name: "one token per non-ASCII character", msg: domain.NewMessage("user", "日本語"), want: 7},
+		{name: "mixed scripts", msg: domain.NewMessage("user", "héllo"), want: 6},
+		{name: "images", msg: withImage, want: 4 + imageTokenEstimate},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := estimateTokens(tt.msg); got != tt.want {
+				t.Errorf("expected %d tokens, got %d", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestRecentWithinBudget(t *testing.T) {
+	toolCall := historyMessage("assistant", 1)
+	toolCall.ToolCalls = []domain.ToolCall{{Name: "web_search"}, {Name: "calculator"}}
+	withTools := []domain.Message{
+		historyMessage("user", 0),
+		toolCall,
+		historyMessage("tool", 2),
+		historyMessage("tool", 3),
+		historyMessage("assistant", 4),
+	}
+
+	plain := []domain.Message{
+		historyMessage("user", 0),
+		historyMessage("assistant", 1),
+		historyMessage("user", 2),
+		historyMessage("assistant", 3),
+	}
+
+	tests := []struct {
+		name      string
+		messages  []domain.Message
+		limit     int
+		minRecent int
+		want      int
+	}{
+		{name: "everything fits", messages: plain, limit: 100, minRecent: 1, want: 4},
+		{name: "stops at the limit", messages: plain, limit: 26, minRecent: 1, want: 2},
+		{name: "keeps the minimum over the limit", messages: plain, limit: 0, minRecent: 3, want: 3},
+		{name: "always keeps the latest message", messages: plain, limit: 0, minRecent: 0, want: 1},
+		{name: "keeps tool results with their call", messages: withTools, limit: 26, minRecent: 1, want: 4},
+		{name: "boundary already at the call", messages: withTools, limit: 52, minRecent: 1, want: 4},
+		{name: "no messages", messages: nil, limit: 100, minRecent: 1, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := recentWithinBudget(tt.messages, tt.limit, tt.minRecent); got != tt.want {
+				t.Errorf("expected %d messages, got %d", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestBuildHistory(t *testing.T) {
+	messages := make([]domain.Message, 6)
+	for i := range messages {
+		role := "user"
+		if i%2 == 1 {
+			role = "assistant"
+		}
+		messages[i] = historyMessage(role, i)
+	}
+	summaryOf := func(summary string) string {
+		return summaryMessage(summary).Content
+	}
+
+	tests := []struct {
+		name            string
+		budget          int
+		summary         string
+		summarizedCount int
+		llm             *stubSummaryLLM
+		want            []string
+		wantSummary     string
+		wantSummarized  int
+		wantCalls       int
+	}{
+		{
+			name:   "budgeting disabled",
+			budget: 0,
+			llm:    &stubSummaryLLM{summary: "unused"},
+			want:   contents(messages),
+		},
+		{
+			name:   "everything fits",
+			budget: 1000,
+			llm:    &stubSummaryLLM{summary: "unused"},
+			want:   contents(messages),
+		},
+		{
+			name:            "existing summary is prepended",
+			budget:          1000,
+			summary:         "earlier",
+			summarizedCount: 2,
+			llm:             &stubSummaryLLM{summary: "unused"},
+			want:            append([]string{summaryOf("earlier")}, contents(messages[2:])...),
+			wantSummary:     "earlier",
+			wantSummarized:  2,
+		},
+		{
+			name:           "older messages are summarized",
+			budget:         40,
+			llm:            &stubSummaryLLM{summary: "the gist"},
+			want:           append([]string{summaryOf("the gist")}, contents(messages[4:])...),
+			wantSummary:    "the gist",
+			wantSummarized: 4,
+			wantCalls:      1,
+		},
+		{
+			name:      "failed summary truncates",
+			budget:    40,
+			llm:       &stubSummaryLLM{err: errors.New("down")},
+			want:      contents(messages[4:]),
+			wantCalls: 1,
+		},
+		{
+			name:            "stale summary is discarded",
+			budget:          1000,
+			summary:         "from a longer chat",
+			summarizedCount: 10,
+			llm:             &stubSummaryLLM{summary: "unused"},
+			want:            contents(messages),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{History: config.HistoryConfig{
+				MaxContextTokens:  tt.budget,
+				MinRecentMessages: 2,
+				SummaryMaxWords:   50,
+			}}
+			service := NewChatService(nil, nil, tt.llm, nil, nil, cfg, logger.New(slog.LevelError, io.Discard))
+
+			chat := domain.NewChat("test")
+			chat.Messages = messages
+			chat.Summary = tt.summary
+			chat.SummarizedCount = tt.summarizedCount
+
+			got := contents(service.buildHistory(context.Background(), chat))
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("expected history %q, got %q", tt.want, got)
+			}
+			if chat.Summary != tt.wantSummary || chat.SummarizedCount != tt.wantSummarized {
+				t.Errorf("expected summary %q of %d messages, got %q of %d",
+					tt.wantSummary, tt.wantSummarized, chat.Summary, chat.SummarizedCount)
+			}
+			if tt.llm.calls != tt.wantCalls {
+				t.Errorf("expected %d summary calls, got %d", tt.wantCalls, tt.llm.calls)
+			}
+		})
+	}
+}
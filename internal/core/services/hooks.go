@@ -0,0 +1,170 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/vibin/chat-bot/config"
+	"github.com/vibin/chat-bot/internal/core/domain"
+	"github.com/vibin/chat-bot/internal/core/ports"
+	"github.com/vibin/chat-bot/internal/logger"
+	"golang.org/x/time/rate"
+)
+
+const (
+	defaultHookRateLimit = 10
+
+	// defaultRewritePrompt is used when a hook asks for a rewrite without its own instructions
+	defaultRewritePrompt = "Rewrite this notification as a short, friendly WhatsApp message for a family group. Keep every fact, time and number. Reply with the message only."
+)
+
+// Errors returned by HookService.Deliver
+var (
+	ErrHookNotFound     = errors.New("hook not found")
+	ErrHookUnauthorized = errors.New("invalid hook token")
+	ErrHookRateLimited  = errors.New("hook rate limit exceeded")
+	ErrHookBadPayload   = errors.New("invalid hook payload")
+)
+
+// HookService posts messages sent to inbound webhooks into their WhatsApp groups
+type HookService struct {
+	config   *config.HooksConfig
+	llm      ports.LLMPort // Rewrites messages for hooks that ask for it, nil posts them as they are
+	sender   ports.MessageSenderPort
+	logger   logger.Logger
+	mutex    sync.Mutex
+	limiters map[string]*rate.Limiter // By hook ID
+}
+
+// NewHookService creates a new hook service
+func NewHookService(config *config.HooksConfig, llm ports.LLMPort, sender ports.MessageSenderPort, logger logger.Logger) *HookService {
+	return &HookService{
+		config:   config,
+		llm:      llm,
+		sender:   sender,
+		logger:   logger,
+		limiters: make(map[string]*rate.Limiter),
+	}
+}
+
+// Deliver checks the token and rate limit of a hook, builds its message from the payload and posts it
+// It returns the message that was posted
+func (s *HookService) Deliver(ctx context.Context, hookID, token string, payload map[string]interface{}) (string, error) {
+	hook := s.hook(hookID)
+	if hook == nil {
+		return "", ErrHookNotFound
+	}
+
+	// Hooks without a token can never be called
+	if hook.Token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(hook.Token)) != 1 {
+		return "", ErrHookUnauthorized
+	}
+
+	if !s.limiter(hook).Allow() {
+		return "", ErrHookRateLimited
+	}
+
+	message, err := renderHookMessage(hook, payload)
+	if err != nil {
+		return "", err
+	}
+
+	if hook.Rewrite && s.llm != nil {
+		rewritten, err := s.rewrite(ctx, hook, message)
+		if err != nil {
+			// The alert matters more than its wording
+			s.logger.Warn("Failed to rewrite hook message, posting it as is", "hook_id", hook.ID, "error", err)
+		} else {
+			message = rewritten
+		}
+	}
+
+	if err := s.sender.SendGroupMessage(hook.GroupID, message); err != nil {
+		return "", fmt.Errorf("failed to send message: %w", err)
+	}
+
+	s.logger.Info("Delivered hook message", "hook_id", hook.ID, "group_id", hook.GroupID)
+	return message, nil
+}
+
+// hook returns the hook with the given ID, or nil
+func (s *HookService) hook(id string) *config.InboundHookConfig {
+	for i := range s.config.Hooks {
+		if s.config.Hooks[i].ID == id {
+			return &s.config.Hooks[i]
+		}
+	}
+	return nil
+}
+
+// limiter returns the rate limiter of a hook
+func (s *HookService) limiter(hook *config.InboundHookConfig) *rate.Limiter {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	limiter, ok := s.limiters[hook.ID]
+	if !ok {
+		perMinute := hook.RateLimitPerMinute
+		if perMinute <= 0 {
+			perMinute = defaultHookRateLimit
+		}
+		limiter = rate.NewLimiter(rate.Every(time.Minute/time.Duration(perMinute)), perMinute)
+		s.limiters[hook.ID] = limiter
+	}
+	return limiter
+}
+
+// rewrite asks the LLM to reword a hook message
+func (s *HookService) rewrite(ctx context.Context, hook *config.InboundHookConfig, message string) (string, error) {
+	instructions := hook.RewritePrompt
+	if instructions == "" {
+		instructions = defaultRewritePrompt
+	}
+
+	prompt := instructions + "\n\nNotification:\n" + message
+	response, err := s.llm.GenerateResponse(ctx, []domain.Message{domain.NewMessage("user", prompt)})
+	if err != nil {
+		return "", err
+	}
+
+	response = strings.TrimSpace(response)
+	if response == "" {
+		return "", fmt.Errorf("empty rewrite")
+	}
+	return response, nil
+}
+
+// renderHookMessage builds the message of a hook from its template, or the payload's "message" field
+func renderHookMessage(hook *config.InboundHookConfig, payload map[string]interface{}) (string, error) {
+	if hook.Template == "" {
+		message, _ := payload["message"].(string)
+		message = strings.TrimSpace(message)
+		if message == "" {
+			return "", fmt.Errorf("%w: message is required", ErrHookBadPayload)
+		}
+		return message, nil
+	}
+
+	tmpl, err := template.New(hook.ID).Option("missingkey=error").Parse(hook.Template)
+	if err != nil {
+		return "", fmt.Errorf("invalid template for hook %s: %w", hook.ID, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, payload); err != nil {
+		return "", fmt.Errorf("%w: %v", ErrHookBadPayload, err)
+	}
+
+	message := strings.TrimSpace(buf.String())
+	if message == "" {
+		return "", fmt.Errorf("%w: the template produced an empty message", ErrHookBadPayload)
+	}
+	return message, nil
+}
@@ -0,0 +1,223 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/vibin/chat-bot/internal/core/domain"
+	"github.com/vibin/chat-bot/internal/core/ports"
+	"github.com/vibin/chat-bot/internal/logger"
+)
+
+const (
+	// maxExtractedMemories limits how many memories are stored from a single exchange
+	maxExtractedMemories = 5
+
+	// maxMemoryLength drops extracted memories that are too long to be a single fact
+	maxMemoryLength = 200
+
+	// extractionTimeout bounds a single background extraction
+	extractionTimeout = 60 * time.Second
+
+	// extractionQueueSize is how many exchanges may wait for extraction before new ones are dropped
+	extractionQueueSize = 32
+)
+
+// MemoryStore keeps the memories found by a MemoryExtractor
+// MemoryService stores them in the memory database
+type MemoryStore interface {
+	GetMemoriesAsStrings(userID, conversationID string) ([]string, error)
+	AddMemory(userID, conversationID, content string) error
+}
+
+// MemoryExtractor uses an LLM to find durable facts about a user in a chat exchange
+// and stores the new ones in a MemoryStore
+type MemoryExtractor struct {
+	llm    ports.LLMPort
+	store  MemoryStore
+	logger logger.Logger
+	jobs   chan extractionJob // Exchanges waiting for the worker started by Start
+}
+
+// extractionJob is an exchange waiting for extraction
+type extractionJob struct {
+	userID         string
+	conversationID string
+	userMessage    string
+	botResponse    string
+}
+
+// extractedMemories is the JSON structure the LLM is asked to reply with
+type extractedMemories struct {
+	Memories []string `json:"memories"`
+}
+
+// NewMemoryExtractor creates a new MemoryExtractor
+func NewMemoryExtractor(llm ports.LLMPort, store MemoryStore, logger logger.Logger) *MemoryExtractor {
+	return &MemoryExtractor{
+		llm:    llm,
+		store:  store,
+		logger: logger,
+		jobs:   make(chan extractionJob, extractionQueueSize),
+	}
+}
+
+// Start extracts the queued exchanges one at a time until ctx is done, so the LLM is not flooded
+func (e *MemoryExtractor) Start(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case job := <-e.jobs:
+			e.run(ctx, job)
+		}
+	}
+}
+
+// ExtractAsync queues an exchange for extraction in the background
+// The exchange is dropped when the queue is full
+func (e *MemoryExtractor) ExtractAsync(userID, conversationID, userMessage, botResponse string) {
+	job := extractionJob{userID: userID, conversationID: conversationID, userMessage: userMessage, botResponse: botResponse}
+	select {
+	case e.jobs <- job:
+	default:
+		e.logger.Warn("Memory extraction queue is full, skipping exchange", "user_id", userID, "conversation_id", conversationID)
+	}
+}
+
+// run extracts the memories of one queued exchange
+func (e *MemoryExtractor) run(ctx context.Context, job extractionJob) {
+	ctx, cancel := context.WithTimeout(ctx, extractionTimeout)
+	defer cancel()
+	// Replies waiting for the LLM go first
+	ctx = ports.WithLLMQueueInfo(ctx, ports.LLMQueueInfo{Group: job.conversationID, Priority: ports.LLMPriorityLow})
+
+	if _, err := e.Extract(ctx, job.userID, job.conversationID, job.userMessage, job.botResponse); err != nil {
+		e.logger.Warn("Memory extraction failed", "user_id", job.userID, "conversation_id", job.conversationID, "error", err)
+	}
+}
+
+// Extract asks the LLM for durable facts about the user and stores those not already known
+// It returns the newly stored memories
+func (e *MemoryExtractor) Extract(ctx context.Context, userID, conversationID, userMessage, botResponse string) ([]string, error) {
+	if strings.TrimSpace(userMessage) == "" {
+		return nil, nil
+	}
+
+	existing, err := e.store.GetMemoriesAsStrings(userID, conversationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load existing memories: %w", err)
+	}
+
+	prompt := buildExtractionPrompt(existing, userMessage, botResponse)
+	response, err := e.llm.GenerateResponse(ctx, []domain.Message{domain.NewMessage("user", prompt)})
+	if err != nil {
+		return nil, err
+	}
+
+	candidates, err := parseExtractedMemories(response)
+	if err != nil {
+		return nil, err
+	}
+
+	known := make([]string, 0, len(existing)+len(candidates))
+	for _, memory := range existing {
+		known = append(known, normalizeMemory(memory))
+	}
+
+	var stored []string
+	for _, candidate := range candidates {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "" || len(candidate) > maxMemoryLength {
+			continue
+		}
+
+		normalized := normalizeMemory(candidate)
+		if normalized == "" || isKnownMemory(known, normalized) {
+			continue
+		}
+
+		if err := e.store.AddMemory(userID, conversationID, candidate); err != nil {
+			return stored, fmt.Errorf("failed to store memory: %w", err)
+		}
+
+		known = append(known, normalized)
+		stored = append(stored, candidate)
+		if len(stored) == maxExtractedMemories {
+			break
+		}
+	}
+
+	if len(stored) > 0 {
+		e.logger.Info("Stored extracted memories",
+			"user_id", userID,
+			"conversation_id", conversationID,
+			"count", len(stored))
+	}
+
+	return stored, nil
+}
+
+// buildExtractionPrompt builds the prompt asking for new facts as JSON
+func buildExtractionPrompt(existing []string, userMessage, botResponse string) string {
+	var sb strings.Builder
+
+	sb.WriteString("You extract long-term memories about a user from a chat exchange.\n")
+	sb.WriteString("Only include durable facts about the user, such as their name, family members, preferences, dislikes, important dates, job, location and plans.\n")
+	sb.WriteString("Ignore questions, small talk, general knowledge and anything the assistant said that the user did not confirm.\n")
+	sb.WriteString("Write each fact as a short sentence in the third person, for example \"Is vegetarian\" or \"Daughter's birthday is on 4 March\".\n\n")
+
+	sb.WriteString("Facts already known:\n")
+	if len(existing) == 0 {
+		sb.WriteString("(none)\n")
+	}
+	for _, memory := range existing {
+		sb.WriteString("- " + memory + "\n")
+	}
+
+	sb.WriteString("\nUser: " + userMessage + "\n")
+	sb.WriteString("Assistant: " + botResponse + "\n\n")
+
+	sb.WriteString(`Reply with JSON only, in the form {"memories": ["fact", ...]}. Do not repeat known facts. Use an empty list if there is nothing new.`)
+
+	return sb.String()
+}
+
+// parseExtractedMemories parses the JSON object in the LLM response
+// Models often wrap JSON in code fences or prose, so only the outermost object is read
+func parseExtractedMemories(response string) ([]string, error) {
+	start := strings.Index(response, "{")
+	end := strings.LastIndex(response, "}")
+	if start == -1 || end < start {
+		return nil, fmt.Errorf("no JSON object in extraction response")
+	}
+
+	var result extractedMemories
+	if err := json.Unmarshal([]byte(response[start:end+1]), &result); err != nil {
+		return nil, fmt.Errorf("failed to parse extraction response: %w", err)
+	}
+
+	return result.Memories, nil
+}
+
+// normalizeMemory lowercases a memory and reduces it to words for comparison
+func normalizeMemory(memory string) string {
+	words := strings.FieldsFunc(strings.ToLower(memory), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	return strings.Join(words, " ")
+}
+
+// isKnownMemory reports whether a normalized memory is already covered by a known one
+func isKnownMemory(known []string, normalized string) bool {
+	for _, memory := range known {
+		if memory == normalized || strings.Contains(memory, normalized) {
+			return true
+		}
+	}
+	return false
+}
@@ -1,24 +1,72 @@
 package services
 
 import (
+	"context"
 	"log"
+	"sync"
 	"time"
 
 	"github.com/vibin/chat-bot/internal/adapters/secondary/database"
+	"github.com/vibin/chat-bot/internal/core/ports"
+)
+
+const (
+	// embeddingTimeout bounds embedding a single memory
+	embeddingTimeout = 30 * time.Second
+
+	// embeddingBackfillBatch is how many unembedded memories are loaded at a time by the backfill worker
+	embeddingBackfillBatch = 50
+
+	// embeddingBackfillQueueSize is how many users may wait for a backfill before new requests are dropped
+	embeddingBackfillQueueSize = 64
 )
 
 // MemoryService provides an interface between the memory database and the application
 type MemoryService struct {
-	memoryDB *database.MemoryDatabase
+	memoryDB      *database.MemoryDatabase
+	embedder      ports.EmbeddingPort // Optional, enables semantic retrieval
+	topK          int
+	minSimilarity float64
+	backfills     chan backfillJob // Users whose memories wait for the worker started by Start
+	pendingMutex  sync.Mutex
+	pending       map[backfillJob]bool // Queued backfills, so a user is only queued once
+}
+
+// backfillJob is a user whose memories lack embeddings
+type backfillJob struct {
+	userID         string
+	conversationID string
 }
 
 // NewMemoryService creates a new memory service
 func NewMemoryService(memoryDB *database.MemoryDatabase) *MemoryService {
 	return &MemoryService{
-		memoryDB: memoryDB,
+		memoryDB:  memoryDB,
+		backfills: make(chan backfillJob, embeddingBackfillQueueSize),
+		pending:   make(map[backfillJob]bool),
+	}
+}
+
+// Start embeds the memories of queued users one user at a time until ctx is done
+func (s *MemoryService) Start(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case job := <-s.backfills:
+			s.backfill(ctx, job)
+			s.pendingMutex.Lock()
+			delete(s.pending, job)
+			s.pendingMutex.Unlock()
+		}
 	}
 }
 
+// Ping checks that the memory database can be reached
+func (s *MemoryService) Ping(ctx context.Context) error {
+	return s.memoryDB.Ping(ctx)
+}
+
 // AddMemory adds a memory to the database with retry mechanism
 func (s *MemoryService) AddMemory(userID, conversationID, content string) error {
 	memory := &database.Memory{
@@ -33,8 +81,13 @@ func (s *MemoryService) AddMemory(userID, conversationID, content string) error
 	// Implement retry logic for database operations
 	var err error
 	for retries := 0; retries < 3; retries++ {
-		err = s.memoryDB.AddMemory(memory)
+		var inserted bool
+		inserted, err = s.memoryDB.AddMemory(memory)
 		if err == nil {
+			// Existing memories keep their embedding, so syncing the cache doesn't embed them again
+			if inserted {
+				s.embedMemory(memory)
+			}
 			return nil // Success
 		}
 		
@@ -72,7 +125,12 @@ func (s *MemoryService) UpdateMemory(id int64, content string) error {
 	
 	memory.Content = content
 	memory.LastUsed = time.Now()
-	return s.memoryDB.UpdateMemory(memory)
+	if err := s.memoryDB.UpdateMemory(memory); err != nil {
+		return err
+	}
+	
+	s.embedMemory(memory)
+	return nil
 }
 
 // DeleteMemory deletes a memory by ID
@@ -116,3 +174,117 @@ func (s *MemoryService) GetMemoriesAsStrings(userID, conversationID string) ([]s
 	
 	return memories, nil
 }
+
+// SetEmbedder enables semantic retrieval, returning the topK memories with at least minSimilarity
+func (s *MemoryService) SetEmbedder(embedder ports.EmbeddingPort, topK int, minSimilarity float64) {
+	s.embedder = embedder
+	s.topK = topK
+	s.minSimilarity = minSimilarity
+}
+
+// embedMemory computes and stores the embedding of a memory if an embedder is configured
+// Failures are only logged, missing embeddings are backfilled after the next retrieval
+func (s *MemoryService) embedMemory(memory *database.Memory) {
+	if s.embedder == nil || memory.ID == 0 {
+		return
+	}
+	
+	ctx, cancel := context.WithTimeout(context.Background(), embeddingTimeout)
+	defer cancel()
+	
+	if err := s.storeEmbedding(ctx, memory); err != nil {
+		log.Printf("Warning: Failed to embed memory %d: %v", memory.ID, err)
+	}
+}
+
+// storeEmbedding embeds the memory content and saves the vector
+func (s *MemoryService) storeEmbedding(ctx context.Context, memory *database.Memory) error {
+	vector, err := s.embedder.Embed(ctx, memory.Content)
+	if err != nil {
+		return err
+	}
+	return s.memoryDB.SetMemoryEmbedding(memory.ID, s.embedder.ModelName(), vector)
+}
+
+// RelevantMemories returns the memories of a user most relevant to a message
+// Without an embedder all of the user's memories are returned
+func (s *MemoryService) RelevantMemories(ctx context.Context, userID, conversationID, message string) ([]*database.Memory, error) {
+	if s.embedder == nil || s.topK <= 0 {
+		return s.GetUserMemories(userID, conversationID)
+	}
+	
+	model := s.embedder.ModelName()
+	
+	// Memories stored before embeddings were enabled or under another model are embedded in the background
+	// and only found by later retrievals
+	s.queueBackfill(userID, conversationID)
+	
+	vector, err := s.embedder.Embed(ctx, message)
+	if err != nil {
+		return nil, err
+	}
+	
+	scored, err := s.memoryDB.SearchMemories(userID, conversationID, model, vector, s.topK)
+	if err != nil {
+		return nil, err
+	}
+	
+	memories := make([]*database.Memory, 0, len(scored))
+	for _, result := range scored {
+		if result.Score < s.minSimilarity {
+			continue
+		}
+		if err := s.memoryDB.IncrementUseCount(result.ID); err != nil {
+			log.Printf("Warning: Failed to update memory use count: %v", err)
+		}
+		memories = append(memories, result.Memory)
+	}
+	
+	return memories, nil
+}
+
+// queueBackfill asks the worker to embed a user's memories that lack an embedding
+// The request is dropped when the user is already queued or the queue is full
+func (s *MemoryService) queueBackfill(userID, conversationID string) {
+	job := backfillJob{userID: userID, conversationID: conversationID}
+	
+	s.pendingMutex.Lock()
+	defer s.pendingMutex.Unlock()
+	if s.pending[job] {
+		return
+	}
+	
+	select {
+	case s.backfills <- job:
+		s.pending[job] = true
+	default:
+		log.Printf("Warning: Memory embedding backfill queue is full, skipping user %s", userID)
+	}
+}
+
+// backfill embeds the memories of a user that have no embedding from the current model
+// It stops at the first failure, the rest are retried when the user is queued again
+func (s *MemoryService) backfill(ctx context.Context, job backfillJob) {
+	model := s.embedder.ModelName()
+	for {
+		missing, err := s.memoryDB.GetMemoriesWithoutEmbedding(job.userID, job.conversationID, model, embeddingBackfillBatch)
+		if err != nil {
+			log.Printf("Warning: Failed to load memories to embed: %v", err)
+			return
+		}
+		
+		for _, memory := range missing {
+			embedCtx, cancel := context.WithTimeout(ctx, embeddingTimeout)
+			err := s.storeEmbedding(embedCtx, memory)
+			cancel()
+			if err != nil {
+				log.Printf("Warning: Failed to backfill memory embedding %d: %v", memory.ID, err)
+				return
+			}
+		}
+		
+		if len(missing) < embeddingBackfillBatch {
+			return
+		}
+	}
+}
@@ -0,0 +1,237 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vibin/chat-bot/config"
+	"github.com/vibin/chat-bot/internal/core/domain"
+	"github.com/vibin/chat-bot/internal/core/ports"
+	"github.com/vibin/chat-bot/internal/logger"
+)
+
+// Errors returned by ModelService
+var (
+	ErrModelManagementUnsupported = errors.New("the LLM server does not support model management")
+	ErrModelSwitchUnsupported     = errors.New("the LLM adapter can't switch models")
+	ErrUnknownModelRole           = errors.New("unknown model role")
+	ErrModelNotFound              = errors.New("model is not installed")
+	ErrPullInProgress             = errors.New("model is already being pulled")
+)
+
+// ModelService lists, pulls and unloads models on the LLM server and switches the models the bot uses
+type ModelService struct {
+	config  *config.Config
+	manager ports.ModelManagerPort // The main LLM's server, nil when it can't manage models
+	logger  logger.Logger
+	mutex   sync.Mutex
+	roles   map[domain.ModelRole]ports.LLMPort
+	pulls   map[string]*domain.ModelPull // By model name, finished pulls are kept until the model is pulled again
+}
+
+// NewModelService creates a model service for the main LLM's server without any roles
+func NewModelService(config *config.Config, manager ports.ModelManagerPort, logger logger.Logger) *ModelService {
+	return &ModelService{
+		config:  config,
+		manager: manager,
+		logger:  logger,
+		roles:   make(map[domain.ModelRole]ports.LLMPort),
+		pulls:   make(map[string]*domain.ModelPull),
+	}
+}
+
+// SetRole registers the adapter serving a role, so its model can be reported and switched
+func (s *ModelService) SetRole(role domain.ModelRole, adapter ports.LLMPort) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.roles[role] = adapter
+}
+
+// ActiveModels returns the model each role uses
+func (s *ModelService) ActiveModels() map[domain.ModelRole]string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	active := make(map[domain.ModelRole]string, len(s.roles))
+	for role := range s.roles {
+		active[role] = s.configuredModel(role)
+	}
+	return active
+}
+
+// ListModels returns the models installed on the server and what the bot uses each for
+func (s *ModelService) ListModels(ctx context.Context) ([]domain.ModelDetails, error) {
+	if s.manager == nil {
+		return nil, ErrModelManagementUnsupported
+	}
+
+	models, err := s.manager.ListModels(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	active := s.ActiveModels()
+	for i := range models {
+		for _, role := range []domain.ModelRole{domain.ModelRoleMain, domain.ModelRoleSecondary, domain.ModelRoleImage} {
+			if name, ok := active[role]; ok && sameModelName(models[i].Name, name) {
+				models[i].Roles = append(models[i].Roles, role)
+			}
+		}
+	}
+	return models, nil
+}
+
+// ShowModel returns a model's context length and capabilities
+func (s *ModelService) ShowModel(ctx context.Context, name string) (domain.ModelDetails, error) {
+	if s.manager == nil {
+		return domain.ModelDetails{}, ErrModelManagementUnsupported
+	}
+	return s.manager.ShowModel(ctx, name)
+}
+
+// Pull starts downloading a model in the background
+// Its progress is reported by Pulls
+func (s *ModelService) Pull(name string) (domain.ModelPull, error) {
+	if s.manager == nil {
+		return domain.ModelPull{}, ErrModelManagementUnsupported
+	}
+
+	s.mutex.Lock()
+	if existing, ok := s.pulls[name]; ok && !existing.Done {
+		s.mutex.Unlock()
+		return domain.ModelPull{}, ErrPullInProgress
+	}
+	pull := &domain.ModelPull{Name: name, Status: "starting", StartedAt: time.Now()}
+	s.pulls[name] = pull
+	snapshot := *pull
+	s.mutex.Unlock()
+
+	go func() {
+		err := s.manager.PullModel(context.Background(), name, func(status string, completed, total int64) {
+			s.mutex.Lock()
+			defer s.mutex.Unlock()
+			pull.Status = status
+			// Status lines between layer downloads carry no byte counts
+			if total > 0 {
+				pull.Completed = completed
+				pull.Total = total
+			}
+		})
+
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		pull.Done = true
+		if err != nil {
+			pull.Error = err.Error()
+			s.logger.Error("Failed to pull model", "model", name, "error", err)
+		}
+	}()
+
+	return snapshot, nil
+}
+
+// Pulls returns the progress of the current and finished pulls
+func (s *ModelService) Pulls() []domain.ModelPull {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	pulls := make([]domain.ModelPull, 0, len(s.pulls))
+	for _, pull := range s.pulls {
+		pulls = append(pulls, *pull)
+	}
+	return pulls
+}
+
+// Unload frees the memory a model uses on the server
+func (s *ModelService) Unload(ctx context.Context, name string) error {
+	if s.manager == nil {
+		return ErrModelManagementUnsupported
+	}
+	return s.manager.UnloadModel(ctx, name)
+}
+
+// Switch changes the model a role uses for new requests and records it in the configuration
+// The model must be installed when the role's server can list its models
+func (s *ModelService) Switch(ctx context.Context, role domain.ModelRole, name string) error {
+	s.mutex.Lock()
+	adapter, ok := s.roles[role]
+	s.mutex.Unlock()
+	if !ok {
+		return ErrUnknownModelRole
+	}
+
+	switcher, ok := adapter.(ports.ModelSwitcherPort)
+	if !ok {
+		return ErrModelSwitchUnsupported
+	}
+
+	// The role may use a different server than the main LLM, so check with its own adapter
+	if manager, ok := adapter.(ports.ModelManagerPort); ok {
+		models, err := manager.ListModels(ctx)
+		if err != nil {
+			return err
+		}
+		installed := false
+		for _, model := range models {
+			if sameModelName(model.Name, name) {
+				installed = true
+				break
+			}
+		}
+		if !installed {
+			return ErrModelNotFound
+		}
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	previous := s.configuredModel(role)
+	switcher.SetModel(name)
+	s.config.Lock()
+	switch role {
+	case domain.ModelRoleMain:
+		s.config.LLM.SetModelName(name)
+	case domain.ModelRoleSecondary:
+		s.config.SecondaryLLM.SetModelName(name)
+	case domain.ModelRoleImage:
+		s.config.ImageLLM.SetModelName(name)
+	}
+	s.config.Unlock()
+
+	s.logger.Info("Switched model", "role", role, "from", previous, "to", name)
+	return nil
+}
+
+// configuredModel returns the model a role uses
+// The mutex must be held
+func (s *ModelService) configuredModel(role domain.ModelRole) string {
+	if switcher, ok := s.roles[role].(ports.ModelSwitcherPort); ok {
+		return switcher.ModelName()
+	}
+
+	s.config.RLock()
+	defer s.config.RUnlock()
+	switch role {
+	case domain.ModelRoleSecondary:
+		return s.config.SecondaryLLM.AsLLMConfig().ModelName()
+	case domain.ModelRoleImage:
+		return s.config.ImageLLM.AsLLMConfig().ModelName()
+	}
+	return s.config.LLM.ModelName()
+}
+
+// sameModelName reports whether two model names refer to the same model
+// Ollama treats a name without a tag as the "latest" tag
+func sameModelName(a, b string) bool {
+	if !strings.Contains(a, ":") {
+		a += ":latest"
+	}
+	if !strings.Contains(b, ":") {
+		b += ":latest"
+	}
+	return a == b
+}
@@ -0,0 +1,461 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/vibin/chat-bot/config"
+	"github.com/vibin/chat-bot/internal/core/domain"
+	"github.com/vibin/chat-bot/internal/core/ports"
+	"github.com/vibin/chat-bot/internal/logger"
+)
+
+// noIntent is the reply the LLM gives when a message matches none of the intents
+const noIntent = "none"
+
+// ruleFile is the layout of the rules file
+type ruleFile struct {
+	Rules []domain.ResponseRule `json:"rules"`
+}
+
+// compiledRule is a rule with its matcher and template prepared
+type compiledRule struct {
+	rule     domain.ResponseRule
+	pattern  *regexp.Regexp // Set for keyword and regex rules
+	template *template.Template
+}
+
+// RuleInput is a message checked against the rules
+type RuleInput struct {
+	Message    string
+	ChatJID    string
+	Sender     string
+	PersonaKey string
+	Persona    config.PersonaConfig
+}
+
+// RuleMatch is the rule that answered a message and its rendered response
+type RuleMatch struct {
+	RuleID   string `json:"rule_id"`
+	Response string `json:"response"`
+}
+
+// ruleData is what response templates can refer to
+type ruleData struct {
+	Persona config.PersonaConfig
+	Sender  string
+	Group   string
+	Message string
+}
+
+// ResponseRuleService answers messages with canned responses from a rules file
+// Rules are checked in priority order, and the file is reloaded when it changes
+type ResponseRuleService struct {
+	path    string
+	llm     ports.LLMPort // Classifies intent rules, nil skips them
+	logger  logger.Logger
+	mutex   sync.RWMutex
+	rules   []compiledRule
+	modTime time.Time
+	writes  sync.Mutex // Held while the rules file is read or changed, so concurrent edits and reloads don't lose updates
+}
+
+// NewResponseRuleService loads the rules file, creating it with the default rules if it does not exist
+func NewResponseRuleService(path string, llm ports.LLMPort, logger logger.Logger) (*ResponseRuleService, error) {
+	s := &ResponseRuleService{
+		path:   path,
+		llm:    llm,
+		logger: logger,
+	}
+
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		if err := s.writeRules(defaultResponseRules()); err != nil {
+			return nil, fmt.Errorf("failed to write default response rules: %w", err)
+		}
+		logger.Info("Created default response rules", "path", path)
+	}
+
+	if err := s.Load(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Load reads and compiles the rules file
+// The current rules are kept when the file is invalid
+func (s *ResponseRuleService) Load() error {
+	s.writes.Lock()
+	defer s.writes.Unlock()
+	return s.load()
+}
+
+// load reads and compiles the rules file
+// The writes mutex must be held
+func (s *ResponseRuleService) load() error {
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return err
+	}
+
+	var file ruleFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("failed to parse response rules: %w", err)
+	}
+
+	compiled, err := compileRules(file.Rules)
+	if err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	s.rules = compiled
+	s.modTime = info.ModTime()
+	s.mutex.Unlock()
+
+	s.logger.Info("Loaded response rules", "path", s.path, "rules", len(compiled))
+	return nil
+}
+
+// Watch reloads the rules file whenever it changes, until the context is cancelled
+func (s *ResponseRuleService) Watch(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.reloadIfChanged()
+		}
+	}
+}
+
+// reloadIfChanged loads the rules file when it was changed outside the service
+func (s *ResponseRuleService) reloadIfChanged() {
+	s.writes.Lock()
+	defer s.writes.Unlock()
+
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return
+	}
+
+	s.mutex.RLock()
+	changed := !info.ModTime().Equal(s.modTime)
+	s.mutex.RUnlock()
+	if !changed {
+		return
+	}
+
+	if err := s.load(); err != nil {
+		s.logger.Error("Failed to reload response rules, keeping the current rules", "error", err)
+		// Don't retry the same broken file on every tick
+		s.mutex.Lock()
+		s.modTime = info.ModTime()
+		s.mutex.Unlock()
+	}
+}
+
+// Rules returns the rules in the order they are checked
+func (s *ResponseRuleService) Rules() []domain.ResponseRule {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	rules := make([]domain.ResponseRule, 0, len(s.rules))
+	for _, compiled := range s.rules {
+		rules = append(rules, compiled.rule)
+	}
+	return rules
+}
+
+// SaveRule adds a rule, or replaces the rule with the same ID, and writes the file
+func (s *ResponseRuleService) SaveRule(rule domain.ResponseRule) error {
+	if err := rule.Validate(); err != nil {
+		return err
+	}
+
+	s.writes.Lock()
+	defer s.writes.Unlock()
+
+	rules := s.Rules()
+	replaced := false
+	for i := range rules {
+		if rules[i].ID == rule.ID {
+			rules[i] = rule
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		rules = append(rules, rule)
+	}
+
+	return s.replaceRules(rules)
+}
+
+// DeleteRule removes a rule and writes the file
+func (s *ResponseRuleService) DeleteRule(id string) error {
+	s.writes.Lock()
+	defer s.writes.Unlock()
+
+	rules := s.Rules()
+	for i := range rules {
+		if rules[i].ID == id {
+			return s.replaceRules(append(rules[:i], rules[i+1:]...))
+		}
+	}
+	return fmt.Errorf("rule %s not found", id)
+}
+
+// Match returns the response of the first rule matching a message, or nil when none match
+func (s *ResponseRuleService) Match(ctx context.Context, input RuleInput) (*RuleMatch, error) {
+	s.mutex.RLock()
+	rules := s.rules
+	s.mutex.RUnlock()
+
+	// Intent rules share one LLM call, made when the first one is reached
+	intent := ""
+	classified := false
+
+	for _, compiled := range rules {
+		rule := compiled.rule
+		if rule.Disabled || !rule.AppliesTo(input.ChatJID, input.PersonaKey) {
+			continue
+		}
+
+		matched := false
+		switch rule.Match {
+		case domain.RuleMatchKeyword, domain.RuleMatchRegex:
+			matched = compiled.pattern.MatchString(input.Message)
+		case domain.RuleMatchIntent:
+			if s.llm == nil {
+				continue
+			}
+			if !classified {
+				classified = true
+				var err error
+				intent, err = s.classifyIntent(ctx, input, rules)
+				if err != nil {
+					s.logger.Warn("Failed to classify message intent", "error", err)
+				}
+			}
+			matched = intent == rule.ID
+		}
+		if !matched {
+			continue
+		}
+
+		var buf bytes.Buffer
+		data := ruleData{
+			Persona: input.Persona,
+			Sender:  input.Sender,
+			Group:   input.ChatJID,
+			Message: input.Message,
+		}
+		if err := compiled.template.Execute(&buf, data); err != nil {
+			return nil, fmt.Errorf("rule %s: failed to render response: %w", rule.ID, err)
+		}
+
+		return &RuleMatch{RuleID: rule.ID, Response: strings.TrimSpace(buf.String())}, nil
+	}
+
+	return nil, nil
+}
+
+// classifyIntent asks the LLM which of the applicable intent rules a message matches
+// It returns the rule ID, or an empty string when none match
+func (s *ResponseRuleService) classifyIntent(ctx context.Context, input RuleInput, rules []compiledRule) (string, error) {
+	var sb strings.Builder
+	sb.WriteString("Classify the intent of a chat message. The possible intents are:\n")
+
+	count := 0
+	for _, compiled := range rules {
+		rule := compiled.rule
+		if rule.Disabled || rule.Match != domain.RuleMatchIntent || !rule.AppliesTo(input.ChatJID, input.PersonaKey) {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("- %s: %s\n", rule.ID, rule.Intent))
+		count++
+	}
+	if count == 0 {
+		return "", nil
+	}
+
+	sb.WriteString(fmt.Sprintf("- %s: the message has none of these intents\n\n", noIntent))
+	sb.WriteString("Message: " + input.Message + "\n\n")
+	sb.WriteString("Reply with the intent name only.")
+
+	// Classification uses the adapter's default prompt rather than the persona's
+	ctx = ports.WithSystemPrompt(ctx, "")
+	response, err := s.llm.GenerateResponse(ctx, []domain.Message{domain.NewMessage("user", sb.String())})
+	if err != nil {
+		return "", err
+	}
+
+	answer := strings.Trim(strings.TrimSpace(response), "`\"'.")
+	if strings.EqualFold(answer, noIntent) {
+		return "", nil
+	}
+	return answer, nil
+}
+
+// replaceRules compiles and writes a new set of rules and makes them current
+// The writes mutex must be held
+func (s *ResponseRuleService) replaceRules(rules []domain.ResponseRule) error {
+	compiled, err := compileRules(rules)
+	if err != nil {
+		return err
+	}
+
+	if err := s.writeRules(rules); err != nil {
+		return err
+	}
+
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	s.rules = compiled
+	s.modTime = info.ModTime()
+	s.mutex.Unlock()
+
+	return nil
+}
+
+// writeRules writes the rules file atomically
+func (s *ResponseRuleService) writeRules(rules []domain.ResponseRule) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(ruleFile{Rules: rules}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmpPath := s.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, s.path)
+}
+
+// compileRules validates and compiles rules, sorted by priority with ties kept in file order
+func compileRules(rules []domain.ResponseRule) ([]compiledRule, error) {
+	compiled := make([]compiledRule, 0, len(rules))
+	seen := make(map[string]bool)
+
+	for _, rule := range rules {
+		if err := rule.Validate(); err != nil {
+			return nil, err
+		}
+		if seen[rule.ID] {
+			return nil, fmt.Errorf("duplicate rule id %s", rule.ID)
+		}
+		seen[rule.ID] = true
+
+		c := compiledRule{
+			rule:     rule,
+			template: template.Must(template.New(rule.ID).Parse(rule.Response)),
+		}
+
+		switch rule.Match {
+		case domain.RuleMatchKeyword:
+			c.pattern = keywordPattern(rule.Keywords)
+		case domain.RuleMatchRegex:
+			c.pattern = regexp.MustCompile(rule.Pattern)
+		}
+
+		compiled = append(compiled, c)
+	}
+
+	sort.SliceStable(compiled, func(i, j int) bool {
+		return compiled[i].rule.Priority > compiled[j].rule.Priority
+	})
+
+	return compiled, nil
+}
+
+// keywordPattern matches any of the keywords as whole words, ignoring case
+func keywordPattern(keywords []string) *regexp.Regexp {
+	quoted := make([]string, 0, len(keywords))
+	for _, keyword := range keywords {
+		words := strings.Fields(keyword)
+		for i, word := range words {
+			words[i] = regexp.QuoteMeta(word)
+		}
+		if len(words) > 0 {
+			quoted = append(quoted, strings.Join(words, `\s+`))
+		}
+	}
+	return regexp.MustCompile(`(?i)\b(?:` + strings.Join(quoted, "|") + `)\b`)
+}
+
+// defaultResponseRules returns the rules written when no rules file exists
+// They only catch direct questions about the bot, so ordinary questions about AI still reach the model
+func defaultResponseRules() []domain.ResponseRule {
+	return []domain.ResponseRule{
+		{
+			ID:          "guard-secrets",
+			Description: "Refuse to reveal the system prompt or credentials",
+			Priority:    100,
+			Match:       domain.RuleMatchRegex,
+			Pattern:     `(?i)\b(your|the)\s+(system\s+prompt|initial\s+instructions|api\s+keys?|secret\s+keys?|access\s+tokens?|credentials|password)\b|\bignore\s+(all\s+)?(your\s+|the\s+)?previous\s+instructions\b|\bjailbreak\b`,
+			Response:    "I can't share that. I'm here to be helpful, accurate, and safe. 🛡️",
+		},
+		{
+			ID:          "identity",
+			Description: "Introduce the persona",
+			Priority:    50,
+			Match:       domain.RuleMatchKeyword,
+			Keywords:    []string{"your name", "who are you"},
+			Response:    "👋 I am {{.Persona.Name}}, a helpful chat assistant{{if .Persona.Creator}} created by {{.Persona.Creator}}{{end}}.",
+		},
+		{
+			ID:          "creator",
+			Description: "Say who created the bot",
+			Priority:    40,
+			Match:       domain.RuleMatchKeyword,
+			Keywords:    []string{"who made you", "who created you", "who built you", "your creator"},
+			Response:    "{{if .Persona.Creator}}{{.Persona.Creator}} is my creator. 🧠{{else}}I was built by the people who run this chat. 🧠{{end}}",
+		},
+		{
+			ID:          "home",
+			Description: "Say where the bot is running",
+			Priority:    40,
+			Match:       domain.RuleMatchRegex,
+			Pattern:     `(?i)\bwhere\s+(are\s+)?you\s+(running|hosted)\b`,
+			Response:    "{{if .Persona.Home}}I am running at {{.Persona.Home}}.{{else}}I am running on a home server.{{end}} 🏠",
+		},
+		{
+			ID:          "model",
+			Description: "Say what powers the bot without naming the real model",
+			Priority:    40,
+			Match:       domain.RuleMatchRegex,
+			Pattern:     `(?i)\b(which|what)\s+(ai\s+|language\s+)?(model|llm)\s+(are\s+you|do\s+you\s+use|powers\s+you|is\s+this)\b|\bwhat\s+powers\s+you\b`,
+			Response:    "I am powered by {{if .Persona.ModelAlias}}{{.Persona.ModelAlias}}, {{end}}an advanced AI system. 🤖",
+		},
+	}
+}
@@ -0,0 +1,341 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"github.com/vibin/chat-bot/config"
+	"github.com/vibin/chat-bot/internal/core/domain"
+	"github.com/vibin/chat-bot/internal/core/ports"
+	"github.com/vibin/chat-bot/internal/logger"
+)
+
+const (
+	defaultPollInterval   = 30 * time.Second
+	defaultMaxJobsPerChat = 25
+	defaultMinInterval    = time.Hour
+
+	// createJobAttempts is how many IDs are tried before creating a job fails
+	createJobAttempts = 3
+
+	// sendRetryWindow is how long a failed run is retried before it is given up
+	sendRetryWindow = time.Hour
+
+	// scheduleLayout is the local time format the LLM is asked to use for one-shot jobs
+	scheduleLayout = "2006-01-02T15:04"
+)
+
+// cronParser accepts standard five-field cron expressions only, so a job can run at most once a minute
+var cronParser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow)
+
+// parsedSchedule is the JSON structure the LLM is asked to reply with
+type parsedSchedule struct {
+	Type    string `json:"type"`   // "once" or "recurring"
+	RunAt   string `json:"run_at"` // Local time for one-shot jobs
+	Cron    string `json:"cron"`   // Cron expression for recurring jobs
+	Message string `json:"message"`
+	Error   string `json:"error"` // Set when the request has no usable time
+}
+
+// Scheduler stores scheduled jobs and posts their messages when they are due
+type Scheduler struct {
+	repository ports.JobRepositoryPort
+	llm        ports.LLMPort // Parses reminders written in natural language
+	sender     ports.MessageSenderPort
+	config     *config.SchedulerConfig
+	location   *time.Location
+	logger     logger.Logger
+}
+
+// NewScheduler creates a new scheduler
+// Times are interpreted in the configured timezone, or the server's local time when it is empty
+func NewScheduler(repository ports.JobRepositoryPort, llm ports.LLMPort, config *config.SchedulerConfig, logger logger.Logger) (*Scheduler, error) {
+	location := time.Local
+	if config.Timezone != "" {
+		loc, err := time.LoadLocation(config.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid scheduler timezone %q: %w", config.Timezone, err)
+		}
+		location = loc
+	}
+
+	return &Scheduler{
+		repository: repository,
+		llm:        llm,
+		config:     config,
+		location:   location,
+		logger:     logger,
+	}, nil
+}
+
+// SetSender sets where due messages are posted
+func (s *Scheduler) SetSender(sender ports.MessageSenderPort) {
+	s.sender = sender
+}
+
+// Location returns the timezone jobs are scheduled in
+func (s *Scheduler) Location() *time.Location {
+	return s.location
+}
+
+// Start runs due jobs until the context is cancelled
+// The first check happens after one poll interval so the sender has time to connect
+func (s *Scheduler) Start(ctx context.Context) {
+	interval := s.config.PollIntervalSeconds * time.Second
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.runDue(ctx, now)
+		}
+	}
+}
+
+// runDue posts the messages of all due jobs and reschedules or removes them
+func (s *Scheduler) runDue(ctx context.Context, now time.Time) {
+	if s.sender == nil {
+		return
+	}
+
+	jobs, err := s.repository.DueJobs(ctx, now)
+	if err != nil {
+		s.logger.Error("Failed to read due jobs", "error", err)
+		return
+	}
+
+	for _, job := range jobs {
+		if err := s.sender.SendGroupMessage(job.ChatJID, formatJobMessage(job)); err != nil {
+			// Keep retrying for a while, the sender may be reconnecting
+			if now.Sub(job.NextRun) < sendRetryWindow {
+				s.logger.Warn("Failed to send scheduled message, will retry", "job_id", job.ID, "error", err)
+				continue
+			}
+			s.logger.Error("Giving up on scheduled message", "job_id", job.ID, "scheduled_for", job.NextRun, "error", err)
+		} else {
+			s.logger.Info("Sent scheduled message", "job_id", job.ID, "chat_jid", job.ChatJID)
+			job.LastRun = now
+		}
+
+		if !job.Recurring() {
+			if err := s.repository.DeleteJob(ctx, job.ID); err != nil {
+				s.logger.Error("Failed to delete finished job", "job_id", job.ID, "error", err)
+			}
+			continue
+		}
+
+		// Runs missed while the bot was down are skipped rather than sent in a burst
+		schedule, err := cronParser.Parse(job.Cron)
+		if err != nil {
+			s.logger.Error("Deleting job with invalid cron expression", "job_id", job.ID, "cron", job.Cron, "error", err)
+			s.repository.DeleteJob(ctx, job.ID)
+			continue
+		}
+		job.NextRun = schedule.Next(now.In(s.location))
+		if err := s.repository.UpdateJob(ctx, job); err != nil {
+			s.logger.Error("Failed to reschedule job", "job_id", job.ID, "error", err)
+		}
+	}
+}
+
+// CreateJob validates and stores a job
+// A job runs once at runAt when cronExpr is empty, and on the cron schedule otherwise
+func (s *Scheduler) CreateJob(ctx context.Context, chatJID, message, cronExpr string, runAt time.Time, createdBy string) (*domain.ScheduledJob, error) {
+	message = strings.TrimSpace(message)
+	if chatJID == "" {
+		return nil, fmt.Errorf("a chat is required")
+	}
+	if message == "" {
+		return nil, fmt.Errorf("a message is required")
+	}
+
+	now := time.Now().In(s.location)
+	nextRun := runAt
+	if cronExpr != "" {
+		schedule, err := cronParser.Parse(cronExpr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron expression %q: %w", cronExpr, err)
+		}
+		nextRun = schedule.Next(now)
+		if nextRun.IsZero() {
+			return nil, fmt.Errorf("cron expression %q never runs", cronExpr)
+		}
+	} else if !runAt.After(now) {
+		return nil, fmt.Errorf("%s is in the past", runAt.In(s.location).Format("Mon 2 Jan 15:04"))
+	}
+
+	maxJobs := s.config.MaxJobsPerChat
+	if maxJobs <= 0 {
+		maxJobs = defaultMaxJobsPerChat
+	}
+	existing, err := s.repository.ListJobs(ctx, chatJID)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= maxJobs {
+		return nil, fmt.Errorf("this chat already has %d scheduled messages", len(existing))
+	}
+
+	// IDs are short, so a clash with an existing job gets a new ID
+	var job *domain.ScheduledJob
+	for attempt := 1; ; attempt++ {
+		job = domain.NewScheduledJob(chatJID, message, cronExpr, nextRun, createdBy)
+		err = s.repository.CreateJob(ctx, job)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, ports.ErrJobExists) || attempt == createJobAttempts {
+			return nil, err
+		}
+	}
+
+	s.logger.Info("Scheduled job", "job_id", job.ID, "chat_jid", chatJID, "cron", cronExpr, "next_run", nextRun)
+	return job, nil
+}
+
+// ScheduleFromText asks the LLM to turn a request such as "remind us Friday 6pm to pay rent" into a job
+func (s *Scheduler) ScheduleFromText(ctx context.Context, chatJID, request, createdBy string) (*domain.ScheduledJob, error) {
+	if s.llm == nil {
+		return nil, fmt.Errorf("no LLM is configured to read reminders")
+	}
+
+	now := time.Now().In(s.location)
+	response, err := s.llm.GenerateResponse(ctx, []domain.Message{domain.NewMessage("user", buildSchedulePrompt(request, now))})
+	if err != nil {
+		return nil, err
+	}
+
+	parsed, err := parseScheduleResponse(response)
+	if err != nil {
+		return nil, err
+	}
+	if parsed.Error != "" {
+		return nil, fmt.Errorf("%s", parsed.Error)
+	}
+
+	switch parsed.Type {
+	case "recurring":
+		cronExpr := strings.TrimSpace(parsed.Cron)
+		if err := s.checkInterval(cronExpr, now); err != nil {
+			return nil, err
+		}
+		return s.CreateJob(ctx, chatJID, parsed.Message, cronExpr, time.Time{}, createdBy)
+	case "once":
+		runAt, err := time.ParseInLocation(scheduleLayout, strings.TrimSpace(parsed.RunAt), s.location)
+		if err != nil {
+			return nil, fmt.Errorf("could not read the time %q", parsed.RunAt)
+		}
+		return s.CreateJob(ctx, chatJID, parsed.Message, "", runAt, createdBy)
+	default:
+		return nil, fmt.Errorf("could not tell when to send the reminder")
+	}
+}
+
+// checkInterval rejects cron expressions from reminder requests that run more often than the minimum interval
+// so a misread request can't flood a chat. Runs over the next year are checked, as gaps vary by day and month
+func (s *Scheduler) checkInterval(cronExpr string, now time.Time) error {
+	schedule, err := cronParser.Parse(cronExpr)
+	if err != nil {
+		return fmt.Errorf("invalid cron expression %q: %w", cronExpr, err)
+	}
+
+	minInterval := s.config.MinIntervalMinutes * time.Minute
+	if minInterval <= 0 {
+		minInterval = defaultMinInterval
+	}
+
+	end := now.AddDate(1, 0, 0)
+	previous := schedule.Next(now)
+	for !previous.IsZero() && previous.Before(end) {
+		next := schedule.Next(previous)
+		if !next.IsZero() && next.Sub(previous) < minInterval {
+			return fmt.Errorf("reminders can repeat at most every %d minutes", int(minInterval/time.Minute))
+		}
+		previous = next
+	}
+	return nil
+}
+
+// ListJobs returns the jobs of a chat, or of all chats when chatJID is empty
+func (s *Scheduler) ListJobs(ctx context.Context, chatJID string) ([]*domain.ScheduledJob, error) {
+	return s.repository.ListJobs(ctx, chatJID)
+}
+
+// CancelJob deletes a job, only matching jobs of chatJID unless it is empty
+func (s *Scheduler) CancelJob(ctx context.Context, chatJID, id string) error {
+	job, err := s.repository.GetJob(ctx, strings.ToLower(strings.TrimSpace(id)))
+	if err != nil {
+		return err
+	}
+	if chatJID != "" && job.ChatJID != chatJID {
+		return fmt.Errorf("job not found")
+	}
+
+	if err := s.repository.DeleteJob(ctx, job.ID); err != nil {
+		return err
+	}
+
+	s.logger.Info("Cancelled job", "job_id", job.ID, "chat_jid", job.ChatJID)
+	return nil
+}
+
+// DescribeJob returns a short human readable description of when a job runs
+func (s *Scheduler) DescribeJob(job *domain.ScheduledJob) string {
+	next := job.NextRun.In(s.location).Format("Mon 2 Jan 15:04")
+	if job.Recurring() {
+		return fmt.Sprintf("repeats (%s), next on %s", job.Cron, next)
+	}
+	return fmt.Sprintf("on %s", next)
+}
+
+// formatJobMessage returns the text posted when a job runs
+func formatJobMessage(job *domain.ScheduledJob) string {
+	return "⏰ " + job.Message
+}
+
+// buildSchedulePrompt builds the prompt asking for a schedule as JSON
+func buildSchedulePrompt(request string, now time.Time) string {
+	var sb strings.Builder
+
+	sb.WriteString("You turn reminder requests from a WhatsApp group into a schedule.\n")
+	sb.WriteString(fmt.Sprintf("The current local time is %s (%s).\n\n", now.Format("Monday 2 January 2006 15:04"), now.Location()))
+
+	sb.WriteString("For a reminder that happens once, reply with {\"type\": \"once\", \"run_at\": \"YYYY-MM-DDTHH:MM\", \"message\": \"...\"} using local time.\n")
+	sb.WriteString("For a reminder that repeats, reply with {\"type\": \"recurring\", \"cron\": \"minute hour day-of-month month day-of-week\", \"message\": \"...\"} using a standard five-field cron expression in local time.\n")
+	sb.WriteString("If no time of day is given, use 09:00. A weekday without a date means the next one after now.\n")
+	sb.WriteString("The message is what the bot will post to the group when the time comes, written as a short friendly reminder, for example \"Time to pay the rent!\".\n")
+	sb.WriteString("If the request does not say when, reply with {\"error\": \"a short explanation\"}.\n\n")
+
+	sb.WriteString("Request: " + request + "\n\n")
+	sb.WriteString("Reply with JSON only.")
+
+	return sb.String()
+}
+
+// parseScheduleResponse parses the JSON object in the LLM response
+func parseScheduleResponse(response string) (*parsedSchedule, error) {
+	start := strings.Index(response, "{")
+	end := strings.LastIndex(response, "}")
+	if start == -1 || end < start {
+		return nil, fmt.Errorf("no JSON object in schedule response")
+	}
+
+	var result parsedSchedule
+	if err := json.Unmarshal([]byte(response[start:end+1]), &result); err != nil {
+		return nil, fmt.Errorf("failed to parse schedule response: %w", err)
+	}
+
+	return &result, nil
+}
@@ -0,0 +1,123 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/vibin/chat-bot/internal/core/domain"
+	"github.com/vibin/chat-bot/internal/core/ports"
+)
+
+// defaultMaxToolIterations bounds the tool calling loop when no limit is configured
+const defaultMaxToolIterations = 5
+
+// RegisterTool makes a tool available to the LLM
+// Tools must be registered during startup, before messages are processed
+func (s *ChatService) RegisterTool(tool ports.Tool) {
+	s.logger.Info("Registering tool", "tool", tool.Name())
+	s.tools = append(s.tools, tool)
+}
+
+// toolCallingLLM returns the main LLM if tool calling is enabled and supported
+// Queued and routed LLMs always offer GenerateWithTools, so the adapters behind them are asked
+func (s *ChatService) toolCallingLLM() (ports.ToolCallingLLMPort, bool) {
+	if !s.config.Tools.Enabled || len(s.tools) == 0 {
+		return nil, false
+	}
+
+	toolLLM, ok := s.llm.(ports.ToolCallingLLMPort)
+	if !ok || !toolLLM.SupportsTools() {
+		return nil, false
+	}
+	return toolLLM, true
+}
+
+// generateWithTools lets the LLM call tools until it produces an answer
+// Tool calls and their results are recorded in the chat as messages
+func (s *ChatService) generateWithTools(ctx context.Context, toolLLM ports.ToolCallingLLMPort, chat *domain.Chat, history []domain.Message) (string, error) {
+	maxIterations := s.config.Tools.MaxIterations
+	if maxIterations <= 0 {
+		maxIterations = defaultMaxToolIterations
+	}
+
+	// Tools act on behalf of this chat and, if known, the user sending the message
+	scope := ports.ToolScopeFrom(ctx)
+	scope.ChatID = chat.ID
+	ctx = ports.WithToolScope(ctx, scope)
+
+	// Copy the history so appending never writes into chat.Messages
+	history = append([]domain.Message(nil), history...)
+
+	for i := 0; i < maxIterations; i++ {
+		reply, err := toolLLM.GenerateWithTools(ctx, history, s.tools)
+		if err != nil {
+			return "", err
+		}
+
+		if len(reply.ToolCalls) == 0 {
+			return reply.Content, nil
+		}
+
+		reply.Type = domain.MessageTypeToolCall
+		chat.AddMessage(reply)
+		history = append(history, reply)
+
+		for _, call := range reply.ToolCalls {
+			result := s.executeTool(ctx, call)
+			chat.AddMessage(result)
+			history = append(history, result)
+		}
+	}
+
+	// Ask for a final answer without offering tools again
+	s.logger.Warn("Tool calling iteration limit reached", "chat_id", chat.ID, "max_iterations", maxIterations)
+	return s.llm.GenerateResponse(ctx, history)
+}
+
+// withoutToolImages returns a copy of a chat without the images produced by tools
+// They are delivered with the reply, keeping them would bloat the stored history
+func withoutToolImages(chat *domain.Chat) *domain.Chat {
+	stored := *chat
+	stored.Messages = make([]domain.Message, len(chat.Messages))
+	for i, msg := range chat.Messages {
+		if msg.Type == domain.MessageTypeToolResult {
+			msg.Images = nil
+		}
+		stored.Messages[i] = msg
+	}
+	return &stored
+}
+
+// executeTool runs a single tool call and returns its result as a tool message
+// Failures are reported back to the LLM so it can recover or explain
+func (s *ChatService) executeTool(ctx context.Context, call domain.ToolCall) domain.Message {
+	s.logger.Info("Executing tool", "tool", call.Name, "arguments", call.Arguments)
+
+	result := domain.NewMessage("tool", "")
+	result.Type = domain.MessageTypeToolResult
+	result.ToolName = call.Name
+
+	var tool ports.Tool
+	for _, t := range s.tools {
+		if t.Name() == call.Name {
+			tool = t
+			break
+		}
+	}
+	if tool == nil {
+		s.logger.Warn("LLM called unknown tool", "tool", call.Name)
+		result.Content = fmt.Sprintf("Error: there is no tool named %q", call.Name)
+		return result
+	}
+
+	output, err := tool.Execute(ctx, call.Arguments)
+	if err != nil {
+		s.logger.Error("Tool execution failed", "tool", call.Name, "error", err)
+		result.Content = fmt.Sprintf("Error: %v", err)
+		return result
+	}
+
+	result.Content = output.Content
+	result.Images = output.Images
+	return result
+}
@@ -0,0 +1,223 @@
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "chatbot"
+
+// Outcome labels
+const (
+	StatusOK    = "ok"
+	StatusError = "error"
+)
+
+// DirectChat is the group label of direct messages, so contacts don't each get their own series
+const DirectChat = "direct"
+
+// OtherChat is the group label of groups the bot is not allowed in, so strangers adding it to groups can't add series
+const OtherChat = "other"
+
+// llmBuckets cover quick classifications up to long generations on a busy GPU
+var llmBuckets = []float64{0.25, 0.5, 1, 2.5, 5, 10, 20, 30, 60, 120}
+
+// LLM calls
+var (
+	LLMRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "llm",
+		Name:      "requests_total",
+		Help:      "LLM calls by model, operation and status",
+	}, []string{"model", "operation", "status"})
+
+	LLMRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "llm",
+		Name:      "request_duration_seconds",
+		Help:      "Duration of LLM calls by model and operation",
+		Buckets:   llmBuckets,
+	}, []string{"model", "operation"})
+
+	LLMTokens = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "llm",
+		Name:      "tokens_total",
+		Help:      "Tokens processed by model, kind is prompt or completion",
+	}, []string{"model", "kind"})
+
+	LLMRoutedRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "llm",
+		Name:      "routed_requests_total",
+		Help:      "Attempts of routed LLM requests by backend, task and status",
+	}, []string{"backend", "task", "status"})
+
+	LLMBackendUp = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "llm",
+		Name:      "backend_up",
+		Help:      "Whether a routed LLM backend is taking requests, 0 while it cools down after a failure",
+	}, []string{"backend"})
+
+	LLMQueueWaiting = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "llm",
+		Name:      "queue_waiting",
+		Help:      "LLM requests waiting for their turn by LLM server",
+	}, []string{"endpoint"})
+
+	LLMQueueWait = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "llm",
+		Name:      "queue_wait_seconds",
+		Help:      "Time LLM requests waited for their turn by LLM server",
+		Buckets:   []float64{0.1, 0.5, 1, 2.5, 5, 10, 20, 30, 60},
+	}, []string{"endpoint"})
+
+	LLMQueueRejected = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "llm",
+		Name:      "queue_rejected_total",
+		Help:      "LLM requests that were not run by LLM server and reason, full or timeout",
+	}, []string{"endpoint", "reason"})
+)
+
+// WhatsApp traffic
+var (
+	WhatsAppMessagesReceived = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "whatsapp",
+		Name:      "messages_received_total",
+		Help:      "WhatsApp messages received by group",
+	}, []string{"group"})
+
+	WhatsAppMessagesHandled = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "whatsapp",
+		Name:      "messages_handled_total",
+		Help:      "WhatsApp messages the bot acted on by group and command",
+	}, []string{"group", "command"})
+
+	WhatsAppMessagesIgnored = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "whatsapp",
+		Name:      "messages_ignored_total",
+		Help:      "WhatsApp messages the bot did not act on by group and reason",
+	}, []string{"group", "reason"})
+
+	WhatsAppSendFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "whatsapp",
+		Name:      "send_failures_total",
+		Help:      "WhatsApp replies that could not be sent by reason",
+	}, []string{"reason"})
+
+	WhatsAppRateLimitWait = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "whatsapp",
+		Name:      "rate_limit_wait_seconds",
+		Help:      "Time replies waited for the send rate limiter",
+		Buckets:   []float64{0.01, 0.05, 0.1, 0.5, 1, 2, 5, 10, 30},
+	})
+)
+
+// Web search, webhook services and the memory database
+var (
+	WebSearchRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "websearch",
+		Name:      "requests_total",
+		Help:      "Web search calls by provider and status",
+	}, []string{"provider", "status"})
+
+	WebSearchDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "websearch",
+		Name:      "request_duration_seconds",
+		Help:      "Duration of web search calls by provider",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"provider"})
+
+	WebhookRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "webhook",
+		Name:      "requests_total",
+		Help:      "Webhook service calls by service and status, circuit_open calls were not made",
+	}, []string{"service", "status"})
+
+	WebhookDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "webhook",
+		Name:      "request_duration_seconds",
+		Help:      "Duration of webhook service calls by service, including retries",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"service"})
+
+	MemoryOperations = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "memory_db",
+		Name:      "operations_total",
+		Help:      "Memory database operations by operation and status",
+	}, []string{"operation", "status"})
+
+	MemoryOperationDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "memory_db",
+		Name:      "operation_duration_seconds",
+		Help:      "Duration of memory database operations",
+		Buckets:   []float64{0.0005, 0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1},
+	}, []string{"operation"})
+)
+
+// Handler serves the metrics in the Prometheus text format
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// Status returns the status label for an error
+func Status(err error) string {
+	if err != nil {
+		return StatusError
+	}
+	return StatusOK
+}
+
+// ObserveLLMRequest records an LLM call that started at start
+func ObserveLLMRequest(model, operation string, start time.Time, err error) {
+	LLMRequests.WithLabelValues(model, operation, Status(err)).Inc()
+	LLMRequestDuration.WithLabelValues(model, operation).Observe(time.Since(start).Seconds())
+}
+
+// AddLLMTokens records the prompt and completion tokens reported for an LLM call
+func AddLLMTokens(model string, prompt, completion int) {
+	if prompt > 0 {
+		LLMTokens.WithLabelValues(model, "prompt").Add(float64(prompt))
+	}
+	if completion > 0 {
+		LLMTokens.WithLabelValues(model, "completion").Add(float64(completion))
+	}
+}
+
+// ObserveWebSearch records a web search call that started at start
+func ObserveWebSearch(provider, status string, start time.Time) {
+	WebSearchRequests.WithLabelValues(provider, status).Inc()
+	WebSearchDuration.WithLabelValues(provider).Observe(time.Since(start).Seconds())
+}
+
+// ObserveWebhook records a webhook service call that started at start
+func ObserveWebhook(service, status string, start time.Time) {
+	WebhookRequests.WithLabelValues(service, status).Inc()
+	WebhookDuration.WithLabelValues(service).Observe(time.Since(start).Seconds())
+}
+
+// ObserveMemoryOperation records a memory database operation that started at start
+// It takes a pointer to the operation's error so it can be deferred
+func ObserveMemoryOperation(operation string, start time.Time, err *error) {
+	MemoryOperations.WithLabelValues(operation, Status(*err)).Inc()
+	MemoryOperationDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+}
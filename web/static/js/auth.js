@@ -0,0 +1,35 @@
+// Adds the CSRF token to the admin pages' API calls and sends the user to the login page when their session ends
+(function() {
+    const originalFetch = window.fetch;
+
+    function csrfToken() {
+        const match = document.cookie.match(/(?:^|;\s*)chatbot_csrf=([^;]+)/);
+        return match ? decodeURIComponent(match[1]) : '';
+    }
+
+    window.fetch = function(input, init) {
+        init = init || {};
+        const method = (init.method || 'GET').toUpperCase();
+        if (!['GET', 'HEAD', 'OPTIONS'].includes(method)) {
+            const headers = new Headers(init.headers || {});
+            const token = csrfToken();
+            if (token) {
+                headers.set('X-CSRF-Token', token);
+            }
+            init.headers = headers;
+        }
+
+        return originalFetch(input, init).then(function(response) {
+            if (response.status === 401) {
+                window.location.href = '/login?next=' + encodeURIComponent(window.location.pathname);
+            }
+            return response;
+        });
+    };
+
+    window.logout = function() {
+        originalFetch('/api/auth/logout', { method: 'POST' }).then(function() {
+            window.location.href = '/login';
+        });
+    };
+})();
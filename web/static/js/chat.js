@@ -61,6 +61,11 @@ document.addEventListener('DOMContentLoaded', () => {
         
         // Add each message to the container
         messages.forEach(message => {
+            // Tool calls and tool results are internal steps of an answer
+            if (message.role === 'tool' || (message.tool_calls && message.tool_calls.length > 0)) {
+                return;
+            }
+            
             const messageElement = document.createElement('div');
             messageElement.className = `message ${message.role}-message`;
             
@@ -100,8 +105,8 @@ document.addEventListener('DOMContentLoaded', () => {
             messagesContainer.appendChild(loadingElement);
             scrollToBottom();
             
-            // Send message to API
-            const response = await fetch(`/api/chats/${chatId}/messages`, {
+            // Send message to the streaming API
+            const response = await fetch(`/api/chats/${chatId}/messages/stream`, {
                 method: 'POST',
                 headers: {
                     'Content-Type': 'application/json',
@@ -113,13 +118,29 @@ document.addEventListener('DOMContentLoaded', () => {
                 throw new Error('Failed to send message');
             }
             
-            const chat = await response.json();
-            
-            // Remove loading indicator
-            messagesContainer.removeChild(loadingElement);
+            // Element that receives tokens as they arrive
+            let assistantElement = null;
+            let assistantText = '';
             
-            // Display all messages to ensure we have the latest state
-            displayMessages(chat.messages);
+            await readEventStream(response, (event, data) => {
+                if (event === 'token') {
+                    if (!assistantElement) {
+                        // Replace the loading indicator with the streamed message
+                        messagesContainer.removeChild(loadingElement);
+                        assistantElement = document.createElement('div');
+                        assistantElement.className = 'message assistant-message';
+                        messagesContainer.appendChild(assistantElement);
+                    }
+                    assistantText += data.content;
+                    assistantElement.textContent = assistantText;
+                    scrollToBottom();
+                } else if (event === 'done') {
+                    // Display all messages to ensure we have the latest state
+                    displayMessages(data.messages);
+                } else if (event === 'error') {
+                    throw new Error(data.error);
+                }
+            });
         } catch (error) {
             console.error('Error sending message:', error);
             
@@ -134,6 +155,41 @@ document.addEventListener('DOMContentLoaded', () => {
         }
     }
     
+    /**
+     * Reads a Server-Sent Events response and calls onEvent for each event
+     */
+    async function readEventStream(response, onEvent) {
+        const reader = response.body.getReader();
+        const decoder = new TextDecoder();
+        let buffer = '';
+        
+        while (true) {
+            const { value, done } = await reader.read();
+            if (done) break;
+            
+            buffer += decoder.decode(value, { stream: true });
+            
+            // Events are separated by a blank line
+            let separator;
+            while ((separator = buffer.indexOf('\n\n')) !== -1) {
+                const rawEvent = buffer.slice(0, separator);
+                buffer = buffer.slice(separator + 2);
+                
+                let event = 'message';
+                let data = '';
+                rawEvent.split('\n').forEach(line => {
+                    if (line.startsWith('event: ')) {
+                        event = line.slice(7);
+                    } else if (line.startsWith('data: ')) {
+                        data += line.slice(6);
+                    }
+                });
+                
+                onEvent(event, data ? JSON.parse(data) : null);
+            }
+        }
+    }
+    
     /**
      * Adds a message to the UI
      */
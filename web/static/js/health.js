@@ -0,0 +1,48 @@
+// Shows the health of the bot's dependencies on the admin pages, from the same report as /readyz
+(function() {
+    const REFRESH_MS = 30000;
+
+    function badgeClass(component) {
+        if (component.status === 'up') {
+            return 'bg-success';
+        }
+        return component.critical ? 'bg-danger' : 'bg-warning text-dark';
+    }
+
+    function render(container, report) {
+        container.innerHTML = '';
+
+        const summary = document.createElement('strong');
+        summary.className = 'me-2';
+        summary.textContent = 'Health: ' + report.status;
+        container.appendChild(summary);
+
+        report.components.forEach(function(component) {
+            const badge = document.createElement('span');
+            badge.className = 'badge me-1 ' + badgeClass(component);
+            badge.textContent = component.name;
+            badge.title = component.status === 'up'
+                ? component.latency_ms + ' ms'
+                : component.status + (component.error ? ': ' + component.error : '');
+            container.appendChild(badge);
+        });
+    }
+
+    function refresh() {
+        const container = document.getElementById('health-status');
+        if (!container) {
+            return;
+        }
+
+        // /readyz answers 503 with the same report when a critical dependency is down
+        fetch('/readyz')
+            .then(function(response) { return response.json(); })
+            .then(function(report) { render(container, report); })
+            .catch(function() { container.textContent = 'Health: unknown'; });
+    }
+
+    document.addEventListener('DOMContentLoaded', function() {
+        refresh();
+        setInterval(refresh, REFRESH_MS);
+    });
+})();